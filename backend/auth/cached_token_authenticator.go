@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"backend.cs3219.comp.nus.edu.sg/model"
+	"backend.cs3219.comp.nus.edu.sg/util"
+)
+
+// cachedTokenAuthenticator keeps the most recently used authentication results in an LRU cache.
+// Unknown tokens are cached as well, with their own TTL, so repeated bad tokens do not reach the database.
+type cachedTokenAuthenticator struct {
+	authenticator TokenAuthenticator
+	ttl           time.Duration
+	negativeTtl   time.Duration
+	capacity      int
+	now           func() time.Time
+
+	mutex   sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+}
+
+type tokenCacheEntry struct {
+	key       string
+	principal *model.Principal
+	expiresAt time.Time
+}
+
+func NewCachedTokenAuthenticator(
+	authenticator TokenAuthenticator,
+	ttl time.Duration,
+	negativeTtl time.Duration,
+	capacity int,
+) TokenAuthenticator {
+	return &cachedTokenAuthenticator{
+		authenticator: authenticator,
+		ttl:           ttl,
+		negativeTtl:   negativeTtl,
+		capacity:      capacity,
+		now:           time.Now,
+		entries:       make(map[string]*list.Element),
+		lru:           list.New(),
+	}
+}
+
+func (cache *cachedTokenAuthenticator) Authenticate(token string) *model.Principal {
+	// Secrets are never kept in memory as-is
+	key := util.HashToken("", token)
+	if principal, isCached := cache.lookup(key); isCached {
+		return principal
+	}
+
+	principal := cache.authenticator.Authenticate(token)
+	cache.store(key, principal)
+	return principal
+}
+
+func (cache *cachedTokenAuthenticator) InvalidateToken(tokenId int) {
+	cache.mutex.Lock()
+	for key, element := range cache.entries {
+		entry := element.Value.(*tokenCacheEntry)
+		// Negative entries carry no token ID, so any of them could belong to a re-enabled token
+		if entry.principal == nil || entry.principal.TokenId == tokenId {
+			cache.lru.Remove(element)
+			delete(cache.entries, key)
+		}
+	}
+	cache.mutex.Unlock()
+
+	cache.authenticator.InvalidateToken(tokenId)
+}
+
+func (cache *cachedTokenAuthenticator) lookup(key string) (*model.Principal, bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	element, ok := cache.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := element.Value.(*tokenCacheEntry)
+	if !cache.now().Before(entry.expiresAt) {
+		cache.lru.Remove(element)
+		delete(cache.entries, key)
+		return nil, false
+	}
+
+	cache.lru.MoveToFront(element)
+	return entry.principal, true
+}
+
+func (cache *cachedTokenAuthenticator) store(key string, principal *model.Principal) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	now := cache.now()
+	expiresAt := now.Add(cache.negativeTtl)
+	if principal != nil {
+		expiresAt = now.Add(cache.ttl)
+		if principal.ExpiresAt != nil && principal.ExpiresAt.Before(expiresAt) {
+			expiresAt = *principal.ExpiresAt
+		}
+	}
+
+	entry := &tokenCacheEntry{
+		key:       key,
+		principal: principal,
+		expiresAt: expiresAt,
+	}
+	if element, ok := cache.entries[key]; ok {
+		element.Value = entry
+		cache.lru.MoveToFront(element)
+		return
+	}
+
+	cache.entries[key] = cache.lru.PushFront(entry)
+	for cache.lru.Len() > cache.capacity {
+		oldest := cache.lru.Back()
+		cache.lru.Remove(oldest)
+		delete(cache.entries, oldest.Value.(*tokenCacheEntry).key)
+	}
+}
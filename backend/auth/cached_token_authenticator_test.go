@@ -0,0 +1,128 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"backend.cs3219.comp.nus.edu.sg/mocks"
+	"backend.cs3219.comp.nus.edu.sg/model"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestTokenCache(authenticator TokenAuthenticator, capacity int, now *time.Time) *cachedTokenAuthenticator {
+	cache := NewCachedTokenAuthenticator(authenticator, time.Minute, 10*time.Second, capacity).(*cachedTokenAuthenticator)
+	cache.now = func() time.Time {
+		return *now
+	}
+	return cache
+}
+
+func TestCachedTokenAuthenticatorTtl(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	now := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	principal := &model.Principal{TokenId: 1}
+	authenticator := mocks.NewMockTokenAuthenticator(mockCtrl)
+	gomock.InOrder(
+		authenticator.EXPECT().Authenticate("AAA").Return(principal),
+		authenticator.EXPECT().Authenticate("AAA").Return(principal),
+	)
+	gomock.InOrder(
+		authenticator.EXPECT().Authenticate("BBB").Return(nil),
+		authenticator.EXPECT().Authenticate("BBB").Return(nil),
+	)
+	cache := newTestTokenCache(authenticator, 10, &now)
+
+	// Case: Repeated lookups are served from the cache
+	assert.Equal(t, principal, cache.Authenticate("AAA"))
+	assert.Equal(t, principal, cache.Authenticate("AAA"))
+	assert.Nil(t, cache.Authenticate("BBB"))
+	assert.Nil(t, cache.Authenticate("BBB"))
+
+	// Case: Negative entries expire sooner than positive entries
+	now = now.Add(30 * time.Second)
+	assert.Equal(t, principal, cache.Authenticate("AAA"))
+	assert.Nil(t, cache.Authenticate("BBB"))
+
+	// Case: Positive entries expire after the TTL
+	now = now.Add(time.Minute)
+	assert.Equal(t, principal, cache.Authenticate("AAA"))
+}
+
+func TestCachedTokenAuthenticatorTokenExpiry(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	now := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	tokenExpiresAt := now.Add(5 * time.Second)
+	principal := &model.Principal{TokenId: 1, ExpiresAt: &tokenExpiresAt}
+	authenticator := mocks.NewMockTokenAuthenticator(mockCtrl)
+	gomock.InOrder(
+		authenticator.EXPECT().Authenticate("AAA").Return(principal),
+		authenticator.EXPECT().Authenticate("AAA").Return(nil),
+	)
+	cache := newTestTokenCache(authenticator, 10, &now)
+
+	assert.Equal(t, principal, cache.Authenticate("AAA"))
+	assert.Equal(t, principal, cache.Authenticate("AAA"))
+
+	// Case: Entry never outlives the token
+	now = tokenExpiresAt
+	assert.Nil(t, cache.Authenticate("AAA"))
+}
+
+func TestCachedTokenAuthenticatorEviction(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	now := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	authenticator := mocks.NewMockTokenAuthenticator(mockCtrl)
+	authenticator.EXPECT().Authenticate("AAA").Return(&model.Principal{TokenId: 1}).Times(1)
+	authenticator.EXPECT().Authenticate("BBB").Return(&model.Principal{TokenId: 2}).Times(2)
+	authenticator.EXPECT().Authenticate("CCC").Return(&model.Principal{TokenId: 3}).Times(1)
+	cache := newTestTokenCache(authenticator, 2, &now)
+
+	cache.Authenticate("AAA")
+	cache.Authenticate("BBB")
+	// AAA becomes the most recently used entry, so BBB is evicted for CCC
+	cache.Authenticate("AAA")
+	cache.Authenticate("CCC")
+	cache.Authenticate("AAA")
+	cache.Authenticate("BBB")
+	assert.Equal(t, 2, cache.lru.Len())
+	assert.Equal(t, 2, len(cache.entries))
+}
+
+func TestCachedTokenAuthenticatorInvalidate(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	now := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	authenticator := mocks.NewMockTokenAuthenticator(mockCtrl)
+	gomock.InOrder(
+		authenticator.EXPECT().Authenticate("AAA").Return(&model.Principal{TokenId: 1}),
+		authenticator.EXPECT().Authenticate("AAA").Return(nil),
+	)
+	authenticator.EXPECT().Authenticate("BBB").Return(&model.Principal{TokenId: 2}).Times(1)
+	gomock.InOrder(
+		authenticator.EXPECT().Authenticate("CCC").Return(nil),
+		authenticator.EXPECT().Authenticate("CCC").Return(&model.Principal{TokenId: 3}),
+	)
+	authenticator.EXPECT().InvalidateToken(1).Times(1)
+	cache := newTestTokenCache(authenticator, 10, &now)
+
+	assert.NotNil(t, cache.Authenticate("AAA"))
+	assert.NotNil(t, cache.Authenticate("BBB"))
+	assert.Nil(t, cache.Authenticate("CCC"))
+
+	cache.InvalidateToken(1)
+
+	// Case: Invalidated token is looked up again
+	assert.Nil(t, cache.Authenticate("AAA"))
+	// Case: Unrelated tokens stay cached
+	assert.NotNil(t, cache.Authenticate("BBB"))
+	// Case: Negative entries are dropped, since a re-enabled token may be among them
+	assert.NotNil(t, cache.Authenticate("CCC"))
+}
@@ -0,0 +1,21 @@
+package auth
+
+import (
+	"context"
+
+	"backend.cs3219.comp.nus.edu.sg/model"
+)
+
+type principalContextKey struct{}
+
+func WithPrincipal(ctx context.Context, principal *model.Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+func PrincipalFromContext(ctx context.Context) *model.Principal {
+	principal, ok := ctx.Value(principalContextKey{}).(*model.Principal)
+	if !ok {
+		return nil
+	}
+	return principal
+}
@@ -0,0 +1,18 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"backend.cs3219.comp.nus.edu.sg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrincipalContext(t *testing.T) {
+	ctx := context.Background()
+	assert.Nil(t, PrincipalFromContext(ctx))
+
+	principal := &model.Principal{TokenId: 3}
+	ctx = WithPrincipal(ctx, principal)
+	assert.Equal(t, principal, PrincipalFromContext(ctx))
+}
@@ -0,0 +1,13 @@
+package auth
+
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+	ScopeAdmin = "admin"
+)
+
+var DefaultScopes = []string{ScopeRead, ScopeWrite}
+
+func IsValidScope(scope string) bool {
+	return scope == ScopeRead || scope == ScopeWrite || scope == ScopeAdmin
+}
@@ -1,14 +1,18 @@
 package auth
 
 import (
+	"crypto/subtle"
 	"log"
 
 	"backend.cs3219.comp.nus.edu.sg/database"
+	"backend.cs3219.comp.nus.edu.sg/model"
+	"backend.cs3219.comp.nus.edu.sg/util"
 )
 
 //go:generate mockgen -destination=../mocks/mock_token_authenticator.go -build_flags=-mod=mod -package=mocks backend.cs3219.comp.nus.edu.sg/auth TokenAuthenticator
 type TokenAuthenticator interface {
-	IsValidToken(token string) bool
+	Authenticate(token string) *model.Principal
+	InvalidateToken(tokenId int)
 }
 
 type tokenAuthenticator struct {
@@ -21,11 +25,32 @@ func NewTokenAuthenticator(db *database.DatabaseConnection) TokenAuthenticator {
 	}
 }
 
-func (authenticator *tokenAuthenticator) IsValidToken(token string) bool {
-	isValid, err := authenticator.tokenAdapter.IsValidToken(token)
+func (authenticator *tokenAuthenticator) Authenticate(token string) *model.Principal {
+	candidates, err := authenticator.tokenAdapter.GetEnabledApiTokensByPrefix(util.GetTokenPrefix(token))
 	if err != nil {
 		log.Println(err)
-		return false
+		return nil
 	}
-	return isValid
+
+	var matchedToken *model.ApiToken
+	for _, candidate := range candidates {
+		tokenHash := util.HashToken(candidate.Salt, token)
+		if subtle.ConstantTimeCompare([]byte(tokenHash), []byte(candidate.Hash)) == 1 {
+			matchedToken = candidate
+		}
+	}
+	if matchedToken == nil {
+		return nil
+	}
+
+	return &model.Principal{
+		TokenId:   matchedToken.Id,
+		Scopes:    matchedToken.Scopes,
+		CreatedAt: matchedToken.CreatedAt,
+		ExpiresAt: matchedToken.ExpiresAt,
+	}
+}
+
+func (authenticator *tokenAuthenticator) InvalidateToken(tokenId int) {
+	// Every call reads from the database, so there is nothing to invalidate
 }
@@ -3,13 +3,15 @@ package auth
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"backend.cs3219.comp.nus.edu.sg/mocks"
+	"backend.cs3219.comp.nus.edu.sg/model"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 )
 
-func TestIsValidToken(t *testing.T) {
+func TestAuthenticate(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
@@ -18,13 +20,35 @@ func TestIsValidToken(t *testing.T) {
 		tokenAdapter: adapter,
 	}
 
+	createdAt := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	storedToken, err := NewHashedApiToken("cs3219tokena", []string{ScopeRead})
+	assert.Nil(t, err)
+	storedToken.Id = 5
+	storedToken.CreatedAt = createdAt
+	otherToken, err := NewHashedApiToken("cs3219tokenb", DefaultScopes)
+	assert.Nil(t, err)
+	otherToken.Id = 6
+
 	gomock.InOrder(
-		adapter.EXPECT().IsValidToken("AAA").Return(false, nil),
-		adapter.EXPECT().IsValidToken("AAA").Return(true, nil),
-		adapter.EXPECT().IsValidToken("AAA").Return(true, errors.New("test error")),
+		adapter.EXPECT().GetEnabledApiTokensByPrefix("cs3219to").Return(nil, nil),
+		adapter.EXPECT().GetEnabledApiTokensByPrefix("cs3219to").Return([]*model.ApiToken{otherToken, storedToken}, nil),
+		adapter.EXPECT().GetEnabledApiTokensByPrefix("cs3219to").Return([]*model.ApiToken{otherToken}, nil),
+		adapter.EXPECT().GetEnabledApiTokensByPrefix("cs3219to").Return(nil, errors.New("test error")),
 	)
 
-	assert.False(t, authenticator.IsValidToken("AAA"))
-	assert.True(t, authenticator.IsValidToken("AAA"))
-	assert.False(t, authenticator.IsValidToken("AAA"))
+	// Case: No token with the prefix
+	assert.Nil(t, authenticator.Authenticate("cs3219tokena"))
+
+	// Case: Matching hash among the candidates
+	assert.Equal(t, &model.Principal{
+		TokenId:   5,
+		Scopes:    []string{ScopeRead},
+		CreatedAt: createdAt,
+	}, authenticator.Authenticate("cs3219tokena"))
+
+	// Case: Same prefix, different secret
+	assert.Nil(t, authenticator.Authenticate("cs3219tokena"))
+
+	// Case: Database error
+	assert.Nil(t, authenticator.Authenticate("cs3219tokena"))
 }
@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"backend.cs3219.comp.nus.edu.sg/model"
+	"backend.cs3219.comp.nus.edu.sg/util"
+)
+
+const tokenByteLength = 32
+
+func GenerateToken() (string, error) {
+	tokenBytes := make([]byte, tokenByteLength)
+	_, err := rand.Read(tokenBytes)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(tokenBytes), nil
+}
+
+// NewHashedApiToken builds the stored form of a token secret. The secret itself is not retained.
+func NewHashedApiToken(secret string, scopes []string) (*model.ApiToken, error) {
+	salt, err := util.NewTokenSalt()
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ApiToken{
+		Prefix:    util.GetTokenPrefix(secret),
+		Salt:      salt,
+		Hash:      util.HashToken(salt, secret),
+		Scopes:    scopes,
+		IsEnabled: true,
+	}, nil
+}
@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"testing"
+
+	"backend.cs3219.comp.nus.edu.sg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateToken(t *testing.T) {
+	tokenA, err := GenerateToken()
+	assert.Nil(t, err)
+	assert.Equal(t, tokenByteLength*2, len(tokenA))
+
+	tokenB, err := GenerateToken()
+	assert.Nil(t, err)
+	assert.NotEqual(t, tokenA, tokenB)
+}
+
+func TestNewHashedApiToken(t *testing.T) {
+	token, err := NewHashedApiToken("cs3219tokena", DefaultScopes)
+	assert.Nil(t, err)
+	assert.Equal(t, "cs3219to", token.Prefix)
+	assert.NotContains(t, token.Hash, "cs3219tokena")
+	assert.Equal(t, util.HashToken(token.Salt, "cs3219tokena"), token.Hash)
+	assert.Equal(t, DefaultScopes, token.Scopes)
+	assert.True(t, token.IsEnabled)
+
+	otherToken, err := NewHashedApiToken("cs3219tokena", DefaultScopes)
+	assert.Nil(t, err)
+	assert.NotEqual(t, token.Salt, otherToken.Salt)
+	assert.NotEqual(t, token.Hash, otherToken.Hash)
+}
@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"backend.cs3219.comp.nus.edu.sg/database"
+	"backend.cs3219.comp.nus.edu.sg/model"
+)
+
+// usageTrackingAuthenticator records successful authentications in memory and
+// periodically writes them out as a single batched update.
+type usageTrackingAuthenticator struct {
+	authenticator TokenAuthenticator
+	tokenAdapter  database.DatabaseApiTokenAdapter
+
+	mutex   sync.Mutex
+	pending map[int]time.Time
+}
+
+func NewUsageTrackingAuthenticator(
+	authenticator TokenAuthenticator,
+	db *database.DatabaseConnection,
+	flushInterval time.Duration,
+) TokenAuthenticator {
+	tracker := newUsageTrackingAuthenticator(authenticator, database.NewDatabaseApiTokenAdapter(db))
+	go tracker.flushPeriodically(flushInterval)
+	return tracker
+}
+
+func newUsageTrackingAuthenticator(
+	authenticator TokenAuthenticator,
+	tokenAdapter database.DatabaseApiTokenAdapter,
+) *usageTrackingAuthenticator {
+	return &usageTrackingAuthenticator{
+		authenticator: authenticator,
+		tokenAdapter:  tokenAdapter,
+		pending:       make(map[int]time.Time),
+	}
+}
+
+func (tracker *usageTrackingAuthenticator) Authenticate(token string) *model.Principal {
+	principal := tracker.authenticator.Authenticate(token)
+	if principal != nil {
+		tracker.recordUsage(principal.TokenId, time.Now())
+	}
+	return principal
+}
+
+func (tracker *usageTrackingAuthenticator) InvalidateToken(tokenId int) {
+	tracker.authenticator.InvalidateToken(tokenId)
+}
+
+func (tracker *usageTrackingAuthenticator) recordUsage(tokenId int, usedAt time.Time) {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	if lastUsedAt, ok := tracker.pending[tokenId]; !ok || usedAt.After(lastUsedAt) {
+		tracker.pending[tokenId] = usedAt
+	}
+}
+
+func (tracker *usageTrackingAuthenticator) flushPeriodically(flushInterval time.Duration) {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		tracker.flush()
+	}
+}
+
+func (tracker *usageTrackingAuthenticator) flush() {
+	tracker.mutex.Lock()
+	batch := tracker.pending
+	tracker.pending = make(map[int]time.Time)
+	tracker.mutex.Unlock()
+
+	if len(batch) == 0 {
+		return
+	}
+
+	err := tracker.tokenAdapter.UpdateApiTokensLastUsed(batch)
+	if err != nil {
+		log.Println(err)
+		// Requeue the batch so that the usage is written on the next flush
+		for tokenId, usedAt := range batch {
+			tracker.recordUsage(tokenId, usedAt)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"backend.cs3219.comp.nus.edu.sg/mocks"
+	"backend.cs3219.comp.nus.edu.sg/model"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsageTrackingAuthenticator(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	principal := &model.Principal{TokenId: 5}
+	authenticator := mocks.NewMockTokenAuthenticator(mockCtrl)
+	authenticator.EXPECT().Authenticate("AAA").Return(principal).AnyTimes()
+	authenticator.EXPECT().Authenticate("BBB").Return(nil).AnyTimes()
+
+	var flushedBatch map[int]time.Time
+	adapter := mocks.NewMockDatabaseApiTokenAdapter(mockCtrl)
+	gomock.InOrder(
+		adapter.EXPECT().UpdateApiTokensLastUsed(gomock.Any()).Return(errors.New("test error")),
+		adapter.EXPECT().UpdateApiTokensLastUsed(gomock.Any()).DoAndReturn(func(batch map[int]time.Time) error {
+			flushedBatch = batch
+			return nil
+		}),
+	)
+	tracker := newUsageTrackingAuthenticator(authenticator, adapter)
+
+	// Case: Nothing to flush
+	tracker.flush()
+
+	// Case: Failed authentication is not recorded
+	assert.Nil(t, tracker.Authenticate("BBB"))
+	tracker.flush()
+
+	// Case: Repeated use is batched into a single entry
+	before := time.Now()
+	assert.Equal(t, principal, tracker.Authenticate("AAA"))
+	assert.Equal(t, principal, tracker.Authenticate("AAA"))
+	after := time.Now()
+
+	// Case: Failed flush is retried on the next flush
+	tracker.flush()
+	tracker.flush()
+	assert.Equal(t, 1, len(flushedBatch))
+	assert.False(t, flushedBatch[5].Before(before))
+	assert.False(t, flushedBatch[5].After(after))
+
+	// Case: Batch is cleared after a successful flush
+	tracker.flush()
+}
@@ -0,0 +1,10 @@
+package catalogue
+
+import "backend.cs3219.comp.nus.edu.sg/model"
+
+//go:generate mockgen -destination=../mocks/mock_catalogue_provider.go -build_flags=-mod=mod -package=mocks backend.cs3219.comp.nus.edu.sg/catalogue CatalogueProvider
+type CatalogueProvider interface {
+	// GetCard returns the catalogue entry of the card with the given unique ID,
+	// or nil if the catalogue has no such card
+	GetCard(uniqueId string) (*model.CatalogueCard, error)
+}
@@ -0,0 +1,43 @@
+package catalogue
+
+import (
+	"sync"
+
+	"backend.cs3219.comp.nus.edu.sg/model"
+)
+
+// FakeCatalogueProvider serves the cards set on it instead of asking the real
+// catalogue, for tests and local development. Cards not set are unknown.
+type FakeCatalogueProvider struct {
+	mutex sync.RWMutex
+	cards map[string]*model.CatalogueCard
+	err   error
+}
+
+func NewFakeCatalogueProvider() *FakeCatalogueProvider {
+	return &FakeCatalogueProvider{
+		cards: make(map[string]*model.CatalogueCard),
+	}
+}
+
+func (provider *FakeCatalogueProvider) SetCard(card *model.CatalogueCard) {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+	provider.cards[card.UniqueId] = card
+}
+
+// SetError makes every lookup fail with err until it is set back to nil
+func (provider *FakeCatalogueProvider) SetError(err error) {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+	provider.err = err
+}
+
+func (provider *FakeCatalogueProvider) GetCard(uniqueId string) (*model.CatalogueCard, error) {
+	provider.mutex.RLock()
+	defer provider.mutex.RUnlock()
+	if provider.err != nil {
+		return nil, provider.err
+	}
+	return provider.cards[uniqueId], nil
+}
@@ -0,0 +1,43 @@
+package catalogue
+
+import (
+	"fmt"
+
+	"backend.cs3219.comp.nus.edu.sg/model"
+)
+
+// sampleCards are the cards of the sample development data in database/seed.sql
+var sampleCards = []struct {
+	number   string
+	name     string
+	rarity   string
+	cardType string
+}{
+	{"1", "Venusaur-EX", "Rare Holo EX", "Grass"},
+	{"2", "Mega Venusaur-EX", "Rare Holo EX", "Grass"},
+	{"3", "Weedle", "Common", "Grass"},
+	{"15", "Scatterbug", "Common", "Grass"},
+	{"16", "Spewpa", "Uncommon", "Grass"},
+	{"17", "Vivillion", "Rare", "Grass"},
+	{"18", "Skiddo", "Common", "Grass"},
+	{"19", "Gogoat", "Uncommon", "Grass"},
+	{"20", "Slugma", "Common", "Fire"},
+}
+
+// NewSampleCatalogueProvider is a fake catalogue of the sample development
+// cards, so cards can be created and synced locally without pokemontcg.io
+func NewSampleCatalogueProvider() *FakeCatalogueProvider {
+	provider := NewFakeCatalogueProvider()
+	for _, card := range sampleCards {
+		provider.SetCard(&model.CatalogueCard{
+			UniqueId: "xy1-" + card.number,
+			Name:     card.name,
+			ImageUrl: fmt.Sprintf("https://images.pokemontcg.io/xy1/%s_hires.png", card.number),
+			SetName:  "XY",
+			Rarity:   card.rarity,
+			Types:    []string{card.cardType},
+			Number:   card.number,
+		})
+	}
+	return provider
+}
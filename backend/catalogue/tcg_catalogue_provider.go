@@ -0,0 +1,103 @@
+package catalogue
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"backend.cs3219.comp.nus.edu.sg/model"
+)
+
+const (
+	tcgApiUrl     = "https://api.pokemontcg.io/v2"
+	tcgApiTimeout = 5 * time.Second
+)
+
+type tcgApiResponse struct {
+	Data struct {
+		Id     string   `json:"id"`
+		Name   string   `json:"name"`
+		Number string   `json:"number"`
+		Rarity string   `json:"rarity"`
+		Types  []string `json:"types"`
+		Set    struct {
+			Name string `json:"name"`
+		} `json:"set"`
+		Images struct {
+			Small string `json:"small"`
+			Large string `json:"large"`
+		} `json:"images"`
+	} `json:"data"`
+}
+
+// tcgCatalogueProvider reads card details from the pokemontcg.io catalogue
+type tcgCatalogueProvider struct {
+	baseUrl string
+	apiKey  string
+	client  *http.Client
+}
+
+// NewTcgCatalogueProvider creates a provider for pokemontcg.io. The API key is
+// optional, but requests without one are rate limited more heavily.
+func NewTcgCatalogueProvider(apiKey string) CatalogueProvider {
+	return newTcgCatalogueProvider(tcgApiUrl, apiKey)
+}
+
+func newTcgCatalogueProvider(baseUrl string, apiKey string) *tcgCatalogueProvider {
+	return &tcgCatalogueProvider{
+		baseUrl: baseUrl,
+		apiKey:  apiKey,
+		client: &http.Client{
+			Timeout: tcgApiTimeout,
+		},
+	}
+}
+
+func (provider *tcgCatalogueProvider) GetCard(uniqueId string) (*model.CatalogueCard, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/cards/%s", provider.baseUrl, url.PathEscape(uniqueId)), nil)
+	if err != nil {
+		return nil, err
+	}
+	if provider.apiKey != "" {
+		req.Header.Set("X-Api-Key", provider.apiKey)
+	}
+
+	resp, err := provider.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("pokemontcg.io responded with status %d", resp.StatusCode)
+	}
+
+	var response tcgApiResponse
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	if err != nil {
+		return nil, fmt.Errorf("unreadable pokemontcg.io response: %w", err)
+	}
+	if response.Data.Id == "" {
+		return nil, nil
+	}
+
+	// The large image is preferred, as it is what the wishlist displays
+	imageUrl := response.Data.Images.Large
+	if imageUrl == "" {
+		imageUrl = response.Data.Images.Small
+	}
+	return &model.CatalogueCard{
+		UniqueId: response.Data.Id,
+		Name:     response.Data.Name,
+		ImageUrl: imageUrl,
+		SetName:  response.Data.Set.Name,
+		Rarity:   response.Data.Rarity,
+		Types:    response.Data.Types,
+		Number:   response.Data.Number,
+	}, nil
+}
@@ -0,0 +1,71 @@
+package catalogue
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"backend.cs3219.comp.nus.edu.sg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTcgCatalogueProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != "KEY" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/cards/xy1-1":
+			w.Write([]byte(`{"data": {"id": "xy1-1", "name": "Venusaur-EX", "number": "1", "rarity": "Rare Holo EX", "types": ["Grass"],
+				"set": {"id": "xy1", "name": "XY"}, "images": {"small": "https://images.pokemontcg.io/xy1/1.png", "large": "https://images.pokemontcg.io/xy1/1_hires.png"}}}`))
+		case "/cards/basep-1":
+			w.Write([]byte(`{"data": {"id": "basep-1", "name": "Pikachu", "number": "1", "images": {"small": "https://images.pokemontcg.io/basep/1.png"}}}`))
+		case "/cards/broken":
+			w.Write([]byte("<html></html>"))
+		case "/cards/limited":
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	provider := newTcgCatalogueProvider(server.URL, "KEY")
+
+	// Case: Card with every detail
+	card, err := provider.GetCard("xy1-1")
+	assert.Nil(t, err)
+	assert.Equal(t, &model.CatalogueCard{
+		UniqueId: "xy1-1",
+		Name:     "Venusaur-EX",
+		ImageUrl: "https://images.pokemontcg.io/xy1/1_hires.png",
+		SetName:  "XY",
+		Rarity:   "Rare Holo EX",
+		Types:    []string{"Grass"},
+		Number:   "1",
+	}, card)
+
+	// Case: Card with a small image only and no rarity or types
+	card, err = provider.GetCard("basep-1")
+	assert.Nil(t, err)
+	assert.Equal(t, "https://images.pokemontcg.io/basep/1.png", card.ImageUrl)
+	assert.Equal(t, "", card.Rarity)
+	assert.Nil(t, card.Types)
+
+	// Case: Card not in the catalogue
+	card, err = provider.GetCard("xy1-999")
+	assert.Nil(t, err)
+	assert.Nil(t, card)
+
+	// Case: Upstream failures
+	for _, uniqueId := range []string{"broken", "limited"} {
+		_, err = provider.GetCard(uniqueId)
+		assert.NotNil(t, err, uniqueId)
+	}
+
+	// Case: Rejected API key
+	_, err = newTcgCatalogueProvider(server.URL, "WRONG").GetCard("xy1-1")
+	assert.ErrorContains(t, err, "403")
+}
@@ -1,13 +1,21 @@
 package controller
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io/ioutil"
+	"log"
 	"net/http"
 	"strconv"
 	"strings"
 
 	"backend.cs3219.comp.nus.edu.sg/auth"
+	"backend.cs3219.comp.nus.edu.sg/database"
+	"backend.cs3219.comp.nus.edu.sg/model"
+	"backend.cs3219.comp.nus.edu.sg/server"
 	"github.com/julienschmidt/httprouter"
 )
 
@@ -37,6 +45,21 @@ func (controller *baseController) readJson(req *http.Request, container interfac
 	return json.Unmarshal(rawData, container)
 }
 
+func (controller *baseController) readOptionalJson(req *http.Request, container interface{}) error {
+	if req.Body == nil {
+		return nil
+	}
+	rawData, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+	if len(rawData) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(rawData, container)
+}
+
 func (controller *baseController) writeJson(resp http.ResponseWriter, data interface{}) error {
 	jsonData, err := json.Marshal(data)
 	if err != nil {
@@ -46,6 +69,41 @@ func (controller *baseController) writeJson(resp http.ResponseWriter, data inter
 	return controller.writeJsonType(resp, 200, jsonData)
 }
 
+// writeJsonWithETag writes the response along with its ETag, or a bare 304 if
+// the request's If-None-Match already names it. An empty etag is derived from
+// the response body.
+func (controller *baseController) writeJsonWithETag(
+	resp http.ResponseWriter,
+	req *http.Request,
+	data interface{},
+	etag string,
+) error {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	if etag == "" {
+		digest := sha256.Sum256(jsonData)
+		etag = fmt.Sprintf(`"%s"`, hex.EncodeToString(digest[:16]))
+	}
+	resp.Header().Set("ETag", etag)
+
+	ifNoneMatch := req.Header.Get("If-None-Match")
+	if ifNoneMatch != "" && matchesETag(ifNoneMatch, etag, true) {
+		resp.WriteHeader(304)
+		return nil
+	}
+	return controller.writeJsonType(resp, 200, jsonData)
+}
+
+// checkIfMatch reports whether the request may modify a resource with the given
+// ETag, which is always the case when it sends no If-Match header
+func (controller *baseController) checkIfMatch(req *http.Request, etag string) bool {
+	ifMatch := req.Header.Get("If-Match")
+	return ifMatch == "" || matchesETag(ifMatch, etag, false)
+}
+
 func (controller *baseController) writeNotFound(resp http.ResponseWriter) {
 	controller.writeJsonType(resp, 404, []byte("{}"))
 }
@@ -54,6 +112,25 @@ func (controller *baseController) writeInternalError(resp http.ResponseWriter) {
 	controller.writeJsonType(resp, 500, []byte("{}"))
 }
 
+// writeDatabaseError writes the status matching a failed database call: 409 for
+// conflicting writes, 400 for rejected data, 503 for transient failures and 500
+// for anything else
+func (controller *baseController) writeDatabaseError(resp http.ResponseWriter, err error) {
+	var uniqueKeyErr *database.UniqueKeyError
+	switch {
+	case errors.As(err, &uniqueKeyErr):
+		controller.writeError(resp, 409, "The request conflicts with an existing record")
+	case database.IsConstraintError(err):
+		controller.writeError(resp, 400, "The request was rejected by a database constraint")
+	case database.IsUnavailableError(err):
+		resp.Header().Set("Retry-After", "1")
+		controller.writeError(resp, 503, "The database is temporarily unavailable")
+	default:
+		log.Println(err)
+		controller.writeInternalError(resp)
+	}
+}
+
 func (controller *baseController) writeBadRequest(resp http.ResponseWriter) {
 	controller.writeJsonType(resp, 400, []byte("{}"))
 }
@@ -79,24 +156,66 @@ func (controller *baseController) writeJsonType(resp http.ResponseWriter, code i
 	return err
 }
 
-func (controller *baseController) authenticateRequest(resp http.ResponseWriter, req *http.Request) bool {
-	if !controller.isBearerValid(req) {
-		resp.WriteHeader(401)
-		resp.Write([]byte("Unauthorized"))
-		return false
+func (controller *baseController) authenticateRequest(requiredScope string, handler server.HTTPHandler) server.HTTPHandler {
+	return func(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
+		principal := controller.resolveBearerPrincipal(req)
+		if principal == nil {
+			resp.WriteHeader(401)
+			resp.Write([]byte("Unauthorized"))
+			return
+		}
+		if !principal.HasScope(requiredScope) {
+			controller.writeError(resp, 403, fmt.Sprintf("This token is missing the '%s' scope", requiredScope))
+			return
+		}
+		handler(resp, req.WithContext(auth.WithPrincipal(req.Context(), principal)), params)
+	}
+}
+
+func (controller *baseController) getPrincipal(req *http.Request) *model.Principal {
+	return auth.PrincipalFromContext(req.Context())
+}
+
+func (controller *baseController) resolveBearerPrincipal(req *http.Request) *model.Principal {
+	bearerToken := controller.readBearerToken(req)
+	if bearerToken == "" {
+		return nil
 	}
-	return true
+	return controller.authenticator.Authenticate(bearerToken)
 }
 
-func (controller *baseController) isBearerValid(req *http.Request) bool {
+func (controller *baseController) readBearerToken(req *http.Request) string {
 	authHeader := req.Header.Get("authorization")
 	if authHeader == "" {
-		return false
+		return ""
 	}
 	if !strings.HasPrefix(authHeader, "Bearer ") {
-		return false
+		return ""
+	}
+
+	return strings.Split(authHeader, " ")[1]
+}
+
+// matchesETag checks an If-Match or If-None-Match header value against an ETag.
+// Weak comparison, used for If-None-Match, ignores the W/ prefix on either side.
+func matchesETag(header string, etag string, weak bool) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	if weak {
+		etag = strings.TrimPrefix(etag, "W/")
 	}
 
-	bearerToken := strings.Split(authHeader, " ")[1]
-	return controller.authenticator.IsValidToken(bearerToken)
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if weak {
+			candidate = strings.TrimPrefix(candidate, "W/")
+		} else if strings.HasPrefix(candidate, "W/") {
+			continue
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
 }
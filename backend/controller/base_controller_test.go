@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"backend.cs3219.comp.nus.edu.sg/auth"
+	"backend.cs3219.comp.nus.edu.sg/database"
+	"backend.cs3219.comp.nus.edu.sg/mocks"
+	"backend.cs3219.comp.nus.edu.sg/model"
+	"github.com/golang/mock/gomock"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthenticateRequest(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	principal := &model.Principal{
+		TokenId: OWNER_ID,
+		Scopes:  []string{auth.ScopeRead},
+	}
+	authenticator := mocks.NewMockTokenAuthenticator(mockCtrl)
+	gomock.InOrder(
+		authenticator.EXPECT().Authenticate(UNAUTH_TOKEN).Return(nil),
+		authenticator.EXPECT().Authenticate(AUTH_TOKEN).Return(principal).Times(2),
+	)
+	controller := &baseController{
+		authenticator: authenticator,
+	}
+
+	var handledPrincipal *model.Principal
+	handlerCalls := 0
+	handler := controller.authenticateRequest(auth.ScopeRead, func(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
+		handlerCalls++
+		handledPrincipal = controller.getPrincipal(req)
+	})
+
+	// Case: No Authorization header
+	responseStub := newResponseWriter()
+	handler(responseStub, buildHTTPRequest(map[string][]string{}, nil), EMPTY_PARAMS)
+	assert.Equal(t, 401, responseStub.status)
+
+	// Case: Not a bearer token
+	responseStub = newResponseWriter()
+	handler(responseStub, buildHTTPRequest(map[string][]string{
+		"Authorization": {"Basic " + AUTH_TOKEN},
+	}, nil), EMPTY_PARAMS)
+	assert.Equal(t, 401, responseStub.status)
+
+	// Case: Unknown token
+	responseStub = newResponseWriter()
+	handler(responseStub, buildHTTPRequest(map[string][]string{
+		"Authorization": {"Bearer " + UNAUTH_TOKEN},
+	}, nil), EMPTY_PARAMS)
+	assert.Equal(t, 401, responseStub.status)
+	assert.Equal(t, 0, handlerCalls)
+
+	// Case: Valid token, principal is attached to the request context
+	responseStub = newResponseWriter()
+	handler(responseStub, buildHTTPRequest(map[string][]string{
+		"Authorization": {"Bearer " + AUTH_TOKEN},
+	}, nil), EMPTY_PARAMS)
+	assert.Equal(t, 1, handlerCalls)
+	assert.Equal(t, principal, handledPrincipal)
+
+	// Case: Valid token without the required scope
+	writeHandler := controller.authenticateRequest(auth.ScopeWrite, func(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
+		handlerCalls++
+	})
+	responseStub = newResponseWriter()
+	writeHandler(responseStub, buildHTTPRequest(map[string][]string{
+		"Authorization": {"Bearer " + AUTH_TOKEN},
+	}, nil), EMPTY_PARAMS)
+	assert.Equal(t, 403, responseStub.status)
+	assert.Equal(t, 1, handlerCalls)
+}
+
+func TestMatchesETag(t *testing.T) {
+	assert.True(t, matchesETag(`"1-2"`, `"1-2"`, false))
+	assert.True(t, matchesETag(`"1-1", "1-2"`, `"1-2"`, false))
+	assert.True(t, matchesETag("*", `"1-2"`, false))
+	assert.False(t, matchesETag(`"1-1"`, `"1-2"`, false))
+
+	// Weak validators only count for If-None-Match
+	assert.False(t, matchesETag(`W/"1-2"`, `"1-2"`, false))
+	assert.True(t, matchesETag(`W/"1-2"`, `"1-2"`, true))
+}
+
+func TestWriteJsonWithETag(t *testing.T) {
+	controller := &baseController{}
+	request := buildHTTPRequest(map[string][]string{}, nil)
+
+	// Case: ETag derived from the body when none is given
+	responseStub := newResponseWriter()
+	assert.Nil(t, controller.writeJsonWithETag(responseStub, request, []int{1, 2}, ""))
+	assert.Equal(t, 200, responseStub.status)
+	etag := http.Header(responseStub.headers).Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	// Case: Same body, matching If-None-Match
+	request.Header.Set("If-None-Match", etag)
+	responseStub = newResponseWriter()
+	assert.Nil(t, controller.writeJsonWithETag(responseStub, request, []int{1, 2}, ""))
+	assert.Equal(t, 304, responseStub.status)
+	assert.Empty(t, responseStub.body)
+
+	// Case: Body changed
+	responseStub = newResponseWriter()
+	assert.Nil(t, controller.writeJsonWithETag(responseStub, request, []int{1, 2, 3}, ""))
+	assert.Equal(t, 200, responseStub.status)
+}
+
+func TestWriteDatabaseError(t *testing.T) {
+	controller := &baseController{}
+	cases := []struct {
+		err    error
+		status int
+	}{
+		{&database.UniqueKeyError{}, 409},
+		{&database.ForeignKeyError{}, 400},
+		{&database.NotNullError{}, 400},
+		{&database.CheckError{}, 400},
+		{&database.SerializationError{}, 503},
+		{&database.ConnectionError{Err: io.EOF}, 503},
+		{errors.New("Test Error"), 500},
+	}
+	for _, item := range cases {
+		responseStub := newResponseWriter()
+		controller.writeDatabaseError(responseStub, item.err)
+		assert.Equal(t, item.status, responseStub.status, item.err.Error())
+	}
+}
@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"backend.cs3219.comp.nus.edu.sg/database"
+	"backend.cs3219.comp.nus.edu.sg/model"
+	"github.com/julienschmidt/httprouter"
+)
+
+var (
+	errCatalogueUnavailable = &cardResponseError{status: 502, message: "The card catalogue could not be reached"}
+	errCardNotInCatalogue   = &cardResponseError{status: 400, message: "The card was not found in the catalogue, so pokemon and imageUrl are required"}
+)
+
+// enrichCard fills in the card's details from the catalogue, keeping any name
+// or image given for it. A failed lookup is only reported if the card cannot be
+// created without it.
+func (controller *cardController) enrichCard(card *model.Card) error {
+	if controller.catalogue == nil || card.UniqueId == "" {
+		return nil
+	}
+
+	detailsGiven := card.Pokemon != "" && card.ImageUrl != ""
+	entry, err := controller.catalogue.GetCard(card.UniqueId)
+	if err != nil {
+		log.Printf("Failed to look up %s in the catalogue: %v", card.UniqueId, err)
+		if !detailsGiven {
+			return errCatalogueUnavailable
+		}
+		return nil
+	}
+	if entry == nil {
+		if !detailsGiven {
+			return errCardNotInCatalogue
+		}
+		return nil
+	}
+
+	card.ApplyCatalogue(entry, false, time.Now().UTC())
+	return nil
+}
+
+// syncCard replaces the card's name, image and other catalogue details with
+// those currently in the catalogue
+func (controller *cardController) syncCard(
+	resp http.ResponseWriter,
+	req *http.Request,
+	params httprouter.Params,
+) {
+	ownerId := controller.getPrincipal(req).TokenId
+
+	cardIdParam := controller.readIntParam("cardId", params)
+	if cardIdParam == nil {
+		controller.writeBadRequest(resp)
+		return
+	}
+	if controller.catalogue == nil {
+		controller.writeError(resp, 503, "Card catalogue lookups are not configured")
+		return
+	}
+
+	targetCard, err := controller.db.GetCard(ownerId, *cardIdParam)
+	if err != nil {
+		controller.writeDatabaseError(resp, err)
+		return
+	}
+	if targetCard == nil {
+		controller.writeNotFound(resp)
+		return
+	}
+	if !controller.checkIfMatch(req, cardETag(targetCard)) {
+		controller.writeCardError(resp, &database.VersionConflictError{})
+		return
+	}
+
+	entry, err := controller.catalogue.GetCard(targetCard.UniqueId)
+	if err != nil {
+		log.Printf("Failed to look up %s in the catalogue: %v", targetCard.UniqueId, err)
+		controller.writeCardError(resp, errCatalogueUnavailable)
+		return
+	}
+	if entry == nil {
+		controller.writeError(resp, 404, "The card was not found in the catalogue")
+		return
+	}
+
+	// The lookup happens outside a transaction, so the write is always made
+	// conditional on the card being unchanged since it was read
+	cardData := *targetCard
+	cardData.ApplyCatalogue(entry, true, time.Now().UTC())
+	err = validateCardFields(&cardData)
+	if err == nil {
+		err = controller.db.EditCard(ownerId, &cardData, model.CardCatalogueFields...)
+	}
+	if err != nil {
+		controller.writeCardError(resp, err)
+		return
+	}
+
+	resp.Header().Set("ETag", cardETag(&cardData))
+	err = controller.writeJson(resp, &cardData)
+	if err != nil {
+		log.Println("Failed to write response for syncCard")
+	}
+}
+
+// cardFieldsWithCatalogueDetails adds the catalogue details to the fields an
+// edit writes, so that clearing them is saved too
+func cardFieldsWithCatalogueDetails(fields []string) []string {
+	withDetails := make([]string, 0, len(fields)+len(model.CardCatalogueDetailFields))
+	withDetails = append(withDetails, fields...)
+	return append(withDetails, model.CardCatalogueDetailFields...)
+}
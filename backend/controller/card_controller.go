@@ -1,13 +1,23 @@
 package controller
 
 import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
 	"log"
+	"mime"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 
 	"backend.cs3219.comp.nus.edu.sg/auth"
+	"backend.cs3219.comp.nus.edu.sg/catalogue"
 	"backend.cs3219.comp.nus.edu.sg/database"
 	"backend.cs3219.comp.nus.edu.sg/model"
+	"backend.cs3219.comp.nus.edu.sg/pricing"
 	"backend.cs3219.comp.nus.edu.sg/server"
 	"github.com/julienschmidt/httprouter"
 )
@@ -18,12 +28,25 @@ type CardController interface {
 
 type cardController struct {
 	baseController
-	db database.DatabaseCardAdapter
+	db      database.DatabaseCardAdapter
+	history database.DatabasePriceHistoryAdapter
+	// prices is nil when no price provider is configured
+	prices pricing.PriceProvider
+	// catalogue is nil when cards are not enriched from a catalogue
+	catalogue catalogue.CatalogueProvider
 }
 
-func NewCardController(db *database.DatabaseConnection, authenticator auth.TokenAuthenticator) CardController {
+func NewCardController(
+	db *database.DatabaseConnection,
+	authenticator auth.TokenAuthenticator,
+	priceProvider pricing.PriceProvider,
+	catalogueProvider catalogue.CatalogueProvider,
+) CardController {
 	return &cardController{
-		db: database.NewDatabaseCardAdapter(db),
+		db:        database.NewDatabaseCardAdapter(db),
+		history:   database.NewDatabasePriceHistoryAdapter(db),
+		prices:    priceProvider,
+		catalogue: catalogueProvider,
 		baseController: baseController{
 			authenticator: authenticator,
 		},
@@ -31,11 +54,16 @@ func NewCardController(db *database.DatabaseConnection, authenticator auth.Token
 }
 
 func (controller *cardController) Attach(server server.HTTPServer) {
-	server.Get("/api/card", controller.getAllCards)
-	server.Post("/api/card", controller.createCard)
-	server.Get("/api/card/:cardId", controller.getCard)
-	server.Put("/api/card/:cardId", controller.editCard)
-	server.Delete("/api/card/:cardId", controller.deleteCard)
+	server.Get("/api/card", controller.authenticateRequest(auth.ScopeRead, controller.getAllCards))
+	server.Post("/api/card", controller.authenticateRequest(auth.ScopeWrite, controller.createCard))
+	server.Post("/api/card/:cardId", controller.routeCardAction())
+	server.Get("/api/card/:cardId", controller.routeCardLookup())
+	server.Put("/api/card/:cardId", controller.authenticateRequest(auth.ScopeWrite, controller.editCard))
+	server.Patch("/api/card/:cardId", controller.authenticateRequest(auth.ScopeWrite, controller.patchCard))
+	server.Delete("/api/card/:cardId", controller.authenticateRequest(auth.ScopeWrite, controller.deleteCard))
+	server.Get("/api/card/:cardId/price", controller.authenticateRequest(auth.ScopeRead, controller.getCardPrice))
+	server.Get("/api/card/:cardId/prices", controller.authenticateRequest(auth.ScopeRead, controller.getCardPriceHistory))
+	server.Post("/api/card/:cardId/sync", controller.authenticateRequest(auth.ScopeWrite, controller.syncCard))
 }
 
 func (controller *cardController) getAllCards(
@@ -43,31 +71,170 @@ func (controller *cardController) getAllCards(
 	req *http.Request,
 	params httprouter.Params,
 ) {
-	if !controller.authenticateRequest(resp, req) {
+	ownerId := controller.getPrincipal(req).TokenId
+
+	query, err := controller.readCardQuery(req)
+	if err != nil {
+		controller.writeError(resp, 400, err.Error())
 		return
 	}
 
-	cards, err := controller.db.GetAllCards()
+	page, err := controller.db.ListCards(ownerId, query)
 	if err != nil {
-		controller.writeInternalError(resp)
+		controller.writeDatabaseError(resp, err)
 		return
 	}
 
-	err = controller.writeJson(resp, cards)
+	response := cardListResponse{
+		Items: page.Items,
+		Total: page.Total,
+	}
+	if response.Items == nil {
+		response.Items = make([]*model.Card, 0)
+	}
+	if page.HasMore && len(page.Items) > 0 {
+		nextCursor := encodeCardCursor(query, page.Items[len(page.Items)-1])
+		response.NextCursor = &nextCursor
+	}
+
+	err = controller.writeJsonWithETag(resp, req, &response, "")
 	if err != nil {
 		log.Println("Failed to write response for getAllCards")
 	}
 }
 
-func (controller *cardController) getCard(
+// routeCardLookup serves fixed sub-routes such as /api/card/search, which
+// httprouter cannot register next to the /api/card/:cardId wildcard
+func (controller *cardController) routeCardLookup() server.HTTPHandler {
+	namedRoutes := map[string]server.HTTPHandler{
+		"search": controller.authenticateRequest(auth.ScopeRead, controller.searchCards),
+		"export": controller.authenticateRequest(auth.ScopeRead, controller.exportCards),
+	}
+	getCard := controller.authenticateRequest(auth.ScopeRead, controller.getCard)
+
+	return func(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
+		if handler, ok := namedRoutes[params.ByName("cardId")]; ok {
+			handler(resp, req, params)
+			return
+		}
+		getCard(resp, req, params)
+	}
+}
+
+// routeCardAction serves fixed POST sub-routes such as /api/card/import, which
+// httprouter cannot register next to the /api/card/:cardId/sync wildcard
+func (controller *cardController) routeCardAction() server.HTTPHandler {
+	namedRoutes := map[string]server.HTTPHandler{
+		"import": controller.authenticateRequest(auth.ScopeWrite, controller.importCards),
+	}
+
+	return func(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
+		if handler, ok := namedRoutes[params.ByName("cardId")]; ok {
+			handler(resp, req, params)
+			return
+		}
+		// Answer as httprouter would for a method the card route lacks
+		resp.Header().Set("Allow", "DELETE, GET, OPTIONS, PATCH, PUT")
+		controller.writeError(resp, 405, "Method Not Allowed")
+	}
+}
+
+func (controller *cardController) searchCards(
+	resp http.ResponseWriter,
+	req *http.Request,
+	params httprouter.Params,
+) {
+	ownerId := controller.getPrincipal(req).TokenId
+
+	text, limit, err := controller.readSearchQuery(req)
+	if err != nil {
+		controller.writeError(resp, 400, err.Error())
+		return
+	}
+
+	results, err := controller.db.SearchCards(ownerId, text, limit)
+	if err != nil {
+		controller.writeDatabaseError(resp, err)
+		return
+	}
+
+	var response struct {
+		Items []*model.CardSearchResult `json:"items"`
+	}
+	response.Items = results
+	if response.Items == nil {
+		response.Items = make([]*model.CardSearchResult, 0)
+	}
+	err = controller.writeJson(resp, &response)
+	if err != nil {
+		log.Println("Failed to write response for searchCards")
+	}
+}
+
+func (controller *cardController) exportCards(
 	resp http.ResponseWriter,
 	req *http.Request,
 	params httprouter.Params,
 ) {
-	if !controller.authenticateRequest(resp, req) {
+	ownerId := controller.getPrincipal(req).TokenId
+
+	formatName := req.URL.Query().Get("format")
+	if formatName == "" {
+		formatName = "csv"
+	}
+	format, ok := cardExportFormats[formatName]
+	if !ok {
+		controller.writeError(resp, 400, fmt.Sprintf("Unknown export format '%s'", formatName))
+		return
+	}
+
+	// The cards are counted and streamed from one snapshot, so the totals
+	// always match the rows written
+	statusSent := false
+	err := controller.db.RunInSnapshot(func(db database.DatabaseCardAdapter) error {
+		totals, err := db.CountCards(ownerId)
+		if err != nil {
+			return err
+		}
+
+		resp.Header().Set("Content-Type", format.contentType)
+		resp.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="wishlist.%s"`, format.extension))
+		resp.Header().Set("X-Total-Count", strconv.Itoa(totals.Cards))
+		resp.WriteHeader(200)
+		statusSent = true
+
+		bufferedResp := bufio.NewWriter(resp)
+		exporter, err := format.newWriter(bufferedResp, totals)
+		if err == nil {
+			err = db.StreamCards(ownerId, exporter.WriteCard)
+		}
+		if err == nil {
+			err = exporter.Close()
+		}
+		if err == nil {
+			err = bufferedResp.Flush()
+		}
+		return err
+	})
+	if err != nil && !statusSent {
+		controller.writeDatabaseError(resp, err)
 		return
 	}
 
+	// The status is already sent once rows start streaming, so failures past
+	// that point can only cut the download short
+	if err != nil {
+		log.Println("Failed to write response for exportCards:", err)
+	}
+}
+
+func (controller *cardController) getCard(
+	resp http.ResponseWriter,
+	req *http.Request,
+	params httprouter.Params,
+) {
+	ownerId := controller.getPrincipal(req).TokenId
+
 	cardIdParam := controller.readIntParam("cardId", params)
 	if cardIdParam == nil {
 		controller.writeBadRequest(resp)
@@ -75,9 +242,9 @@ func (controller *cardController) getCard(
 	}
 	cardId := *cardIdParam
 
-	card, err := controller.db.GetCard(cardId)
+	card, err := controller.db.GetCard(ownerId, cardId)
 	if err != nil {
-		controller.writeInternalError(resp)
+		controller.writeDatabaseError(resp, err)
 		return
 	}
 
@@ -86,7 +253,7 @@ func (controller *cardController) getCard(
 		return
 	}
 
-	err = controller.writeJson(resp, card)
+	err = controller.writeJsonWithETag(resp, req, card, cardETag(card))
 	if err != nil {
 		log.Println("Failed to write response for getCard")
 	}
@@ -97,9 +264,7 @@ func (controller *cardController) createCard(
 	req *http.Request,
 	params httprouter.Params,
 ) {
-	if !controller.authenticateRequest(resp, req) {
-		return
-	}
+	ownerId := controller.getPrincipal(req).TokenId
 
 	var cardData model.Card
 	err := controller.readJson(req, &cardData)
@@ -108,36 +273,114 @@ func (controller *cardController) createCard(
 		return
 	}
 
-	if cardData.ImageUrl == "" || cardData.Pokemon == "" || cardData.UniqueId == "" {
-		controller.writeBadRequest(resp)
+	// Catalogue details only come from the catalogue, never from the client
+	cardData.ClearCatalogueDetails()
+	cardData.ApplyDefaults()
+	err = controller.enrichCard(&cardData)
+	if err != nil {
+		controller.writeCardError(resp, err)
 		return
 	}
-
-	_, err = url.ParseRequestURI(cardData.ImageUrl)
+	err = validateCardFields(&cardData)
 	if err != nil {
-		controller.writeError(resp, 400, "The URL provided is invalid")
+		controller.writeCardError(resp, err)
 		return
 	}
 
-	existingCard, err := controller.db.GetCardByUniqueId(cardData.UniqueId)
+	// A duplicate unique ID is left to the unique constraint, which catches
+	// concurrent creates that a check made beforehand would miss
+	card, err := controller.db.CreateCard(ownerId, &cardData)
 	if err != nil {
-		controller.writeInternalError(resp)
+		controller.writeCardError(resp, err)
 		return
 	}
-	if existingCard != nil {
-		controller.writeError(resp, 403, "A card with the same unique ID already exists")
-		return
+
+	resp.Header().Set("ETag", cardETag(card))
+	err = controller.writeJson(resp, card)
+	if err != nil {
+		log.Println("Failed to write response for createCard")
 	}
+}
 
-	card, err := controller.db.CreateCard(&cardData)
+func (controller *cardController) importCards(
+	resp http.ResponseWriter,
+	req *http.Request,
+	params httprouter.Params,
+) {
+	ownerId := controller.getPrincipal(req).TokenId
+
+	readImport := readJsonImport
+	contentType := req.Header.Get("Content-Type")
+	if contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		switch {
+		case err != nil:
+			controller.writeBadRequest(resp)
+			return
+		case mediaType == csvContentType:
+			readImport = readCsvImport
+		case mediaType != "application/json":
+			controller.writeError(resp, 415, fmt.Sprintf("Unsupported import format '%s'", mediaType))
+			return
+		}
+	}
+
+	if req.Body == nil {
+		controller.writeBadRequest(resp)
+		return
+	}
+	rows, err := readImport(http.MaxBytesReader(resp, req.Body, maxImportBytes))
 	if err != nil {
-		controller.writeInternalError(resp)
+		controller.writeError(resp, 400, err.Error())
+		return
+	}
+	if len(rows) == 0 || len(rows) > maxImportRows {
+		controller.writeError(resp, 400, fmt.Sprintf("An import must contain between 1 and %d cards", maxImportRows))
 		return
 	}
 
-	err = controller.writeJson(resp, card)
+	// Each row is inserted on its own, so valid rows are kept even if others fail
+	response := cardImportResponse{
+		Results: make([]*cardImportResult, len(rows)),
+	}
+	for i, row := range rows {
+		result := &cardImportResult{
+			Row: i + 1,
+		}
+		response.Results[i] = result
+
+		err = row.err
+		if err == nil {
+			row.card.ClearCatalogueDetails()
+			row.card.ApplyDefaults()
+			err = validateCardFields(row.card)
+		}
+		if err != nil {
+			result.Status = importStatusInvalid
+			result.Error = err.Error()
+			response.Failed++
+			continue
+		}
+
+		card, err := controller.db.CreateCardIfAbsent(ownerId, row.card)
+		if err != nil {
+			result.Status = importStatusError
+			result.Error = "The card could not be saved"
+			response.Failed++
+		} else if card == nil {
+			result.Status = importStatusConflict
+			result.Error = "A card with the same unique ID already exists"
+			response.Failed++
+		} else {
+			result.Status = importStatusCreated
+			result.Card = card
+			response.Created++
+		}
+	}
+
+	err = controller.writeJson(resp, &response)
 	if err != nil {
-		log.Println("Failed to write response for createCard")
+		log.Println("Failed to write response for importCards")
 	}
 }
 
@@ -146,9 +389,7 @@ func (controller *cardController) editCard(
 	req *http.Request,
 	params httprouter.Params,
 ) {
-	if !controller.authenticateRequest(resp, req) {
-		return
-	}
+	ownerId := controller.getPrincipal(req).TokenId
 
 	cardIdParam := controller.readIntParam("cardId", params)
 	if cardIdParam == nil {
@@ -157,14 +398,19 @@ func (controller *cardController) editCard(
 	}
 	cardId := *cardIdParam
 
-	var cardData model.Card
-	err := controller.readJson(req, &cardData)
+	if req.Body == nil {
+		controller.writeBadRequest(resp)
+		return
+	}
+	cardJson, err := ioutil.ReadAll(req.Body)
 	if err != nil {
 		controller.writeBadRequest(resp)
 		return
 	}
 
-	if cardData.ImageUrl == "" || cardData.Pokemon == "" || cardData.UniqueId == "" {
+	var cardData model.Card
+	err = json.Unmarshal(cardJson, &cardData)
+	if err != nil {
 		controller.writeBadRequest(resp)
 		return
 	}
@@ -174,41 +420,139 @@ func (controller *cardController) editCard(
 		return
 	}
 
-	_, err = url.ParseRequestURI(cardData.ImageUrl)
+	// Fields left out keep their stored values, which replace these defaults
+	omittedFields := omittedCardFields(cardJson)
+	cardData.ApplyDefaults()
+	err = validateCardFields(&cardData)
 	if err != nil {
-		controller.writeError(resp, 400, "The URL provided is invalid")
+		controller.writeCardError(resp, err)
 		return
 	}
 
-	existingCard, err := controller.db.GetCardByUniqueId(cardData.UniqueId)
+	// Taking another card's unique ID fails on the unique constraint
+	err = controller.db.RunInTx(func(db database.DatabaseCardAdapter) error {
+		targetCard, err := db.GetCard(ownerId, cardId)
+		if err != nil {
+			return err
+		}
+		if targetCard == nil {
+			return errCardNotFound
+		}
+		if !controller.checkIfMatch(req, cardETag(targetCard)) {
+			return &database.VersionConflictError{}
+		}
+
+		copyCardFields(&cardData, targetCard, omittedFields)
+
+		// Catalogue details are read only, so the stored ones are kept unless
+		// they were for the card's old unique ID, until it is synced again
+		cardData.Version = controller.expectedCardVersion(req, targetCard)
+		if cardData.UniqueId == targetCard.UniqueId {
+			cardData.CopyCatalogueDetails(targetCard)
+			return db.EditCard(ownerId, &cardData)
+		}
+		cardData.ClearCatalogueDetails()
+		return db.EditCard(ownerId, &cardData, cardFieldsWithCatalogueDetails(model.CardEditableFields)...)
+	})
 	if err != nil {
-		controller.writeInternalError(resp)
+		controller.writeCardError(resp, err)
 		return
 	}
-	if existingCard != nil && existingCard.Id != cardId {
-		controller.writeError(resp, 403, "A card with the same unique ID already exists")
+
+	resp.Header().Set("ETag", cardETag(&cardData))
+	err = controller.writeJson(resp, &cardData)
+	if err != nil {
+		log.Println("Failed to write response for editCard")
+	}
+}
+
+func (controller *cardController) patchCard(
+	resp http.ResponseWriter,
+	req *http.Request,
+	params httprouter.Params,
+) {
+	ownerId := controller.getPrincipal(req).TokenId
+
+	cardIdParam := controller.readIntParam("cardId", params)
+	if cardIdParam == nil {
+		controller.writeBadRequest(resp)
 		return
 	}
+	cardId := *cardIdParam
 
-	targetCard, err := controller.db.GetCard(cardId)
-	if err != nil {
-		controller.writeInternalError(resp)
+	applyPatch := applyMergePatch
+	contentType := req.Header.Get("Content-Type")
+	if contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		switch {
+		case err != nil:
+			controller.writeBadRequest(resp)
+			return
+		case mediaType == jsonPatchContentType:
+			applyPatch = applyJsonPatch
+		case mediaType != mergePatchContentType && mediaType != "application/json":
+			controller.writeError(resp, 415, fmt.Sprintf("Unsupported patch format '%s'", mediaType))
+			return
+		}
+	}
+
+	if req.Body == nil {
+		controller.writeBadRequest(resp)
 		return
 	}
-	if targetCard == nil {
-		controller.writeNotFound(resp)
+	patchData, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		controller.writeBadRequest(resp)
 		return
 	}
 
-	err = controller.db.EditCard(&cardData)
+	var cardData model.Card
+	err = controller.db.RunInTx(func(db database.DatabaseCardAdapter) error {
+		targetCard, err := db.GetCard(ownerId, cardId)
+		if err != nil {
+			return err
+		}
+		if targetCard == nil {
+			return errCardNotFound
+		}
+		if !controller.checkIfMatch(req, cardETag(targetCard)) {
+			return &database.VersionConflictError{}
+		}
+
+		cardData = *targetCard
+		err = applyPatch(&cardData, patchData)
+		if errors.Is(err, errCardPatchTestFailed) {
+			return err
+		} else if err != nil {
+			return &cardResponseError{status: 400, message: err.Error()}
+		}
+
+		err = validateCardFields(&cardData)
+		if err != nil {
+			return err
+		}
+
+		// Only the columns the patch actually changed are written
+		changedFields := changedCardFields(targetCard, &cardData)
+		if len(changedFields) == 0 {
+			return nil
+		}
+		if cardData.UniqueId != targetCard.UniqueId {
+			cardData.ClearCatalogueDetails()
+			changedFields = cardFieldsWithCatalogueDetails(changedFields)
+		}
+		cardData.Version = controller.expectedCardVersion(req, targetCard)
+		return db.EditCard(ownerId, &cardData, changedFields...)
+	})
 	if err != nil {
-		controller.writeInternalError(resp)
+		controller.writeCardError(resp, err)
 		return
 	}
 
+	resp.Header().Set("ETag", cardETag(&cardData))
 	err = controller.writeJson(resp, &cardData)
 	if err != nil {
-		log.Println("Failed to write response for editCard")
+		log.Println("Failed to write response for patchCard")
 	}
 }
 
@@ -217,9 +561,7 @@ func (controller *cardController) deleteCard(
 	req *http.Request,
 	params httprouter.Params,
 ) {
-	if !controller.authenticateRequest(resp, req) {
-		return
-	}
+	ownerId := controller.getPrincipal(req).TokenId
 
 	cardIdParam := controller.readIntParam("cardId", params)
 	if cardIdParam == nil {
@@ -228,19 +570,22 @@ func (controller *cardController) deleteCard(
 	}
 	cardId := *cardIdParam
 
-	targetCard, err := controller.db.GetCard(cardId)
-	if err != nil {
-		controller.writeInternalError(resp)
-		return
-	}
-	if targetCard == nil {
-		controller.writeNotFound(resp)
-		return
-	}
-
-	err = controller.db.DeleteCard(cardId)
+	err := controller.db.RunInTx(func(db database.DatabaseCardAdapter) error {
+		targetCard, err := db.GetCard(ownerId, cardId)
+		if err != nil {
+			return err
+		}
+		if targetCard == nil {
+			return errCardNotFound
+		}
+		if !controller.checkIfMatch(req, cardETag(targetCard)) {
+			return &database.VersionConflictError{}
+		}
+
+		return db.DeleteCard(ownerId, cardId, controller.expectedCardVersion(req, targetCard))
+	})
 	if err != nil {
-		controller.writeInternalError(resp)
+		controller.writeCardError(resp, err)
 		return
 	}
 
@@ -253,3 +598,111 @@ func (controller *cardController) deleteCard(
 		log.Println("Failed to write response for deleteCard")
 	}
 }
+
+func cardETag(card *model.Card) string {
+	return fmt.Sprintf(`"%d-%d"`, card.Id, card.Version)
+}
+
+// expectedCardVersion is the version a write must still find in the database,
+// closing the gap between checking If-Match and writing. Zero means the request
+// did not ask for a conditional write.
+func (controller *cardController) expectedCardVersion(req *http.Request, targetCard *model.Card) int {
+	if req.Header.Get("If-Match") == "" {
+		return 0
+	}
+	return targetCard.Version
+}
+
+// cardResponseError is an error with the HTTP status it is reported with. Raised
+// in a transaction callback, it also rolls the transaction back.
+type cardResponseError struct {
+	status  int
+	message string
+}
+
+func (err *cardResponseError) Error() string {
+	return err.message
+}
+
+var errCardNotFound = &cardResponseError{status: 404}
+
+// writeCardError writes the response for any error raised while handling a card
+func (controller *cardController) writeCardError(resp http.ResponseWriter, err error) {
+	var responseErr *cardResponseError
+	var uniqueKeyErr *database.UniqueKeyError
+	var versionConflictErr *database.VersionConflictError
+	var notFoundErr *database.NotFoundError
+	switch {
+	case errors.As(err, &responseErr) && responseErr.message == "":
+		controller.writeJsonType(resp, responseErr.status, []byte("{}"))
+	case errors.As(err, &responseErr):
+		controller.writeError(resp, responseErr.status, responseErr.message)
+	case errors.As(err, &uniqueKeyErr):
+		controller.writeError(resp, 409, "A card with the same unique ID already exists")
+	case errors.As(err, &versionConflictErr):
+		controller.writeError(resp, 412, "The card has been modified since it was last read")
+	case errors.As(err, &notFoundErr):
+		controller.writeNotFound(resp)
+	case err == errCardFieldsMissing:
+		controller.writeBadRequest(resp)
+	case errors.Is(err, errCardPatchTestFailed):
+		controller.writeError(resp, 409, err.Error())
+	default:
+		controller.writeDatabaseError(resp, err)
+	}
+}
+
+// maxCardTargetPrice keeps target prices within the NUMERIC(10, 2) column
+const maxCardTargetPrice = 1000000
+
+var (
+	errCardFieldsMissing    = errors.New("The uniqueId, pokemon and imageUrl fields are required")
+	errCardUrlInvalid       = &cardResponseError{status: 400, message: "The URL provided is invalid"}
+	errCardQuantityInvalid  = &cardResponseError{status: 400, message: "At least one copy must be wanted, and the owned quantity cannot be negative"}
+	errCardConditionInvalid = &cardResponseError{status: 400, message: "The condition must be one of " + strings.Join(model.CardConditions, ", ")}
+	errCardLanguageInvalid  = &cardResponseError{status: 400, message: "The language must be one of " + strings.Join(model.CardLanguages, ", ")}
+	errCardFinishInvalid    = &cardResponseError{status: 400, message: "The finish must be one of " + strings.Join(model.CardFinishes, ", ")}
+	errCardPriorityInvalid  = &cardResponseError{status: 400, message: "The priority must be one of " + strings.Join(model.CardPriorities, ", ")}
+	errCardTargetInvalid    = &cardResponseError{status: 400, message: fmt.Sprintf("The target price must be between 0 and %d", maxCardTargetPrice)}
+)
+
+// validateCardFields applies the rules every stored card must satisfy
+func validateCardFields(card *model.Card) error {
+	if card.ImageUrl == "" || card.Pokemon == "" || card.UniqueId == "" {
+		return errCardFieldsMissing
+	}
+
+	_, err := url.ParseRequestURI(card.ImageUrl)
+	if err != nil {
+		return errCardUrlInvalid
+	}
+
+	if card.WantQuantity < 1 || card.HaveQuantity < 0 {
+		return errCardQuantityInvalid
+	}
+	if !containsString(model.CardConditions, card.Condition) {
+		return errCardConditionInvalid
+	}
+	if !containsString(model.CardLanguages, card.Language) {
+		return errCardLanguageInvalid
+	}
+	if !containsString(model.CardFinishes, card.Finish) {
+		return errCardFinishInvalid
+	}
+	if !containsString(model.CardPriorities, card.Priority) {
+		return errCardPriorityInvalid
+	}
+	if card.TargetPrice < 0 || card.TargetPrice > maxCardTargetPrice {
+		return errCardTargetInvalid
+	}
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, item := range values {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
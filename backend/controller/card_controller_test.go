@@ -8,11 +8,18 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
 
+	"backend.cs3219.comp.nus.edu.sg/auth"
+	"backend.cs3219.comp.nus.edu.sg/catalogue"
 	"backend.cs3219.comp.nus.edu.sg/database"
 	"backend.cs3219.comp.nus.edu.sg/mocks"
 	"backend.cs3219.comp.nus.edu.sg/model"
+	"backend.cs3219.comp.nus.edu.sg/pricing"
+	"backend.cs3219.comp.nus.edu.sg/server"
 	"github.com/golang/mock/gomock"
 	"github.com/julienschmidt/httprouter"
 	"github.com/stretchr/testify/assert"
@@ -24,6 +31,7 @@ type CardControllerTestSuite struct {
 	dbAdapter    database.DatabaseCardAdapter
 	ctx          context.Context
 	seedModels   []*model.Card
+	principal    *model.Principal
 	unauthHeader map[string][]string
 	authHeader   map[string][]string
 }
@@ -39,6 +47,7 @@ var EMPTY_PARAMS = httprouter.Params{}
 const (
 	UNAUTH_TOKEN = "AAA"
 	AUTH_TOKEN   = "BBB"
+	OWNER_ID     = 7
 	VALID_URL    = "http://example.com"
 	INVALID_URL  = "notaurl"
 )
@@ -54,7 +63,7 @@ func (writer *StubResponseWriter) Header() http.Header {
 }
 
 func (writer *StubResponseWriter) Write(body []byte) (int, error) {
-	writer.body = body
+	writer.body = append(writer.body, body...)
 	return len(body), nil
 }
 
@@ -63,6 +72,10 @@ func (writer *StubResponseWriter) WriteHeader(statusCode int) {
 }
 
 func (suite *CardControllerTestSuite) SetupSuite() {
+	suite.principal = &model.Principal{
+		TokenId: OWNER_ID,
+		Scopes:  []string{auth.ScopeRead, auth.ScopeWrite},
+	}
 	suite.seedModels = []*model.Card{
 		{
 			Id:       101,
@@ -83,6 +96,9 @@ func (suite *CardControllerTestSuite) SetupSuite() {
 			ImageUrl: "http://url3.something.com",
 		},
 	}
+	for _, card := range suite.seedModels {
+		card.ApplyDefaults()
+	}
 	suite.unauthHeader = map[string][]string{
 		"Authorization": {
 			fmt.Sprintf("Bearer %s", UNAUTH_TOKEN),
@@ -101,13 +117,20 @@ func (suite *CardControllerTestSuite) TestGetAllCards() {
 
 	authenticator := mocks.NewMockTokenAuthenticator(mockCtrl)
 	cardAdapter := mocks.NewMockDatabaseCardAdapter(mockCtrl)
+	defaultQuery := &model.CardQuery{
+		Limit: defaultCardPageSize,
+		Sort:  model.CardSortId,
+	}
 	gomock.InOrder(
-		cardAdapter.EXPECT().GetAllCards().Return(nil, errors.New("Test error")),
-		cardAdapter.EXPECT().GetAllCards().Return(suite.seedModels, nil),
+		cardAdapter.EXPECT().ListCards(OWNER_ID, defaultQuery).Return(nil, errors.New("Test error")),
+		cardAdapter.EXPECT().ListCards(OWNER_ID, defaultQuery).Return(&model.CardPage{
+			Items: suite.seedModels,
+			Total: 3,
+		}, nil),
 	)
 	gomock.InOrder(
-		authenticator.EXPECT().IsValidToken(UNAUTH_TOKEN).Return(false),
-		authenticator.EXPECT().IsValidToken(AUTH_TOKEN).Return(true).Times(2),
+		authenticator.EXPECT().Authenticate(UNAUTH_TOKEN).Return(nil),
+		authenticator.EXPECT().Authenticate(AUTH_TOKEN).Return(suite.principal).Times(3),
 	)
 	controller := &cardController{
 		db: cardAdapter,
@@ -115,29 +138,126 @@ func (suite *CardControllerTestSuite) TestGetAllCards() {
 			authenticator: authenticator,
 		},
 	}
+	getAllCards := controller.authenticateRequest(auth.ScopeRead, controller.getAllCards)
 
 	// Case: Unauthorized GET
 	request := buildHTTPRequest(suite.unauthHeader, nil)
 	responseStub := newResponseWriter()
-	controller.getAllCards(responseStub, request, EMPTY_PARAMS)
+	getAllCards(responseStub, request, EMPTY_PARAMS)
 	assert.Equal(suite.T(), 401, responseStub.status)
 
 	// Case: Authorized GET, DB Error
 	request = buildHTTPRequest(suite.authHeader, nil)
 	responseStub = newResponseWriter()
-	controller.getAllCards(responseStub, request, EMPTY_PARAMS)
+	getAllCards(responseStub, request, EMPTY_PARAMS)
 	assert.Equal(suite.T(), 500, responseStub.status)
 
 	// Case: Authorized GET, No Error
 	responseStub = newResponseWriter()
-	controller.getAllCards(responseStub, request, EMPTY_PARAMS)
+	getAllCards(responseStub, request, EMPTY_PARAMS)
 	assert.Equal(suite.T(), 200, responseStub.status)
-	result := make([]model.Card, 0)
+	var result struct {
+		Items      []model.Card `json:"items"`
+		NextCursor *string      `json:"nextCursor"`
+		Total      int          `json:"total"`
+	}
 	err := json.Unmarshal(responseStub.body, &result)
 	assert.Nil(suite.T(), err)
-	assert.Contains(suite.T(), result, *(suite.seedModels[0]))
-	assert.Contains(suite.T(), result, *(suite.seedModels[1]))
-	assert.Contains(suite.T(), result, *(suite.seedModels[2]))
+	assert.Contains(suite.T(), result.Items, *(suite.seedModels[0]))
+	assert.Contains(suite.T(), result.Items, *(suite.seedModels[1]))
+	assert.Contains(suite.T(), result.Items, *(suite.seedModels[2]))
+	assert.Nil(suite.T(), result.NextCursor)
+	assert.Equal(suite.T(), 3, result.Total)
+
+	// Case: Invalid query, rejected before reaching the DB
+	request = buildRoutedHTTPRequest(http.MethodGet, "/api/card?limit=0", suite.authHeader)
+	responseStub = newResponseWriter()
+	getAllCards(responseStub, request, EMPTY_PARAMS)
+	assert.Equal(suite.T(), 400, responseStub.status)
+}
+
+func (suite *CardControllerTestSuite) TestGetAllCardsPagination() {
+	mockCtrl := gomock.NewController(suite.T())
+	defer mockCtrl.Finish()
+
+	authenticator := mocks.NewMockTokenAuthenticator(mockCtrl)
+	authenticator.EXPECT().Authenticate(AUTH_TOKEN).Return(suite.principal).AnyTimes()
+	cardAdapter := mocks.NewMockDatabaseCardAdapter(mockCtrl)
+	firstQuery := &model.CardQuery{
+		Limit:      2,
+		Sort:       model.CardSortPokemon,
+		Descending: true,
+		Pokemon:    "a",
+		Set:        "CARD",
+		Missing:    true,
+	}
+	secondQuery := *firstQuery
+	secondQuery.After = &model.CardCursor{
+		SortValue: suite.seedModels[1].Pokemon,
+		Id:        suite.seedModels[1].Id,
+	}
+	gomock.InOrder(
+		cardAdapter.EXPECT().ListCards(OWNER_ID, firstQuery).Return(&model.CardPage{
+			Items:   suite.seedModels[:2],
+			Total:   3,
+			HasMore: true,
+		}, nil),
+		cardAdapter.EXPECT().ListCards(OWNER_ID, &secondQuery).Return(&model.CardPage{
+			Items: suite.seedModels[2:],
+			Total: 3,
+		}, nil),
+	)
+	controller := &cardController{
+		db: cardAdapter,
+		baseController: baseController{
+			authenticator: authenticator,
+		},
+	}
+	getAllCards := controller.authenticateRequest(auth.ScopeRead, controller.getAllCards)
+
+	var result struct {
+		Items      []model.Card `json:"items"`
+		NextCursor *string      `json:"nextCursor"`
+		Total      int          `json:"total"`
+	}
+
+	// Case: First page hands out a cursor
+	route := "/api/card?limit=2&sort=-pokemon&pokemon=a&set=CARD&missing=true"
+	responseStub := newResponseWriter()
+	getAllCards(responseStub, buildRoutedHTTPRequest(http.MethodGet, route, suite.authHeader), EMPTY_PARAMS)
+	assert.Equal(suite.T(), 200, responseStub.status)
+	assert.Nil(suite.T(), json.Unmarshal(responseStub.body, &result))
+	assert.Len(suite.T(), result.Items, 2)
+	assert.NotNil(suite.T(), result.NextCursor)
+	nextCursor := *result.NextCursor
+
+	// Case: Cursor resumes after the last card of the previous page
+	result.NextCursor = nil
+	responseStub = newResponseWriter()
+	getAllCards(responseStub, buildRoutedHTTPRequest(http.MethodGet, route+"&cursor="+nextCursor, suite.authHeader), EMPTY_PARAMS)
+	assert.Equal(suite.T(), 200, responseStub.status)
+	assert.Nil(suite.T(), json.Unmarshal(responseStub.body, &result))
+	assert.Len(suite.T(), result.Items, 1)
+	assert.Nil(suite.T(), result.NextCursor)
+	assert.Equal(suite.T(), 3, result.Total)
+
+	// Case: Cursor replayed against another sort
+	responseStub = newResponseWriter()
+	getAllCards(responseStub, buildRoutedHTTPRequest(http.MethodGet, "/api/card?sort=uniqueId&cursor="+nextCursor, suite.authHeader), EMPTY_PARAMS)
+	assert.Equal(suite.T(), 400, responseStub.status)
+
+	// Case: Malformed parameters
+	for _, badRoute := range []string{
+		"/api/card?limit=abc",
+		"/api/card?limit=1000",
+		"/api/card?sort=image",
+		"/api/card?cursor=%21%21%21",
+		"/api/card?missing=maybe",
+	} {
+		responseStub = newResponseWriter()
+		getAllCards(responseStub, buildRoutedHTTPRequest(http.MethodGet, badRoute, suite.authHeader), EMPTY_PARAMS)
+		assert.Equal(suite.T(), 400, responseStub.status, badRoute)
+	}
 }
 
 func (suite *CardControllerTestSuite) TestGetCard() {
@@ -147,12 +267,12 @@ func (suite *CardControllerTestSuite) TestGetCard() {
 	authenticator := mocks.NewMockTokenAuthenticator(mockCtrl)
 	cardAdapter := mocks.NewMockDatabaseCardAdapter(mockCtrl)
 	gomock.InOrder(
-		cardAdapter.EXPECT().GetCard(gomock.Any()).Return(nil, errors.New("Test error")),
-		cardAdapter.EXPECT().GetCard(gomock.Any()).Return(suite.seedModels[0], nil),
+		cardAdapter.EXPECT().GetCard(OWNER_ID, gomock.Any()).Return(nil, errors.New("Test error")),
+		cardAdapter.EXPECT().GetCard(OWNER_ID, gomock.Any()).Return(suite.seedModels[0], nil),
 	)
 	gomock.InOrder(
-		authenticator.EXPECT().IsValidToken(UNAUTH_TOKEN).Return(false),
-		authenticator.EXPECT().IsValidToken(AUTH_TOKEN).Return(true).Times(4),
+		authenticator.EXPECT().Authenticate(UNAUTH_TOKEN).Return(nil),
+		authenticator.EXPECT().Authenticate(AUTH_TOKEN).Return(suite.principal).Times(4),
 	)
 	controller := &cardController{
 		db: cardAdapter,
@@ -160,32 +280,33 @@ func (suite *CardControllerTestSuite) TestGetCard() {
 			authenticator: authenticator,
 		},
 	}
+	getCard := controller.authenticateRequest(auth.ScopeRead, controller.getCard)
 
 	// Case: Unauthorized GET
 	request := buildHTTPRequest(suite.unauthHeader, nil)
 	responseStub := newResponseWriter()
-	controller.getCard(responseStub, request, buildRouteParams("101"))
+	getCard(responseStub, request, buildRouteParams("101"))
 	assert.Equal(suite.T(), 401, responseStub.status)
 
 	// Case: No Route Params
 	request = buildHTTPRequest(suite.authHeader, nil)
 	responseStub = newResponseWriter()
-	controller.getCard(responseStub, request, EMPTY_PARAMS)
+	getCard(responseStub, request, EMPTY_PARAMS)
 	assert.Equal(suite.T(), 400, responseStub.status)
 
 	// Case: Bad Route Param - Not Number
 	responseStub = newResponseWriter()
-	controller.getCard(responseStub, request, buildRouteParams("asdf"))
+	getCard(responseStub, request, buildRouteParams("asdf"))
 	assert.Equal(suite.T(), 400, responseStub.status)
 
 	// Case: Authorized GET, DB Error
 	responseStub = newResponseWriter()
-	controller.getCard(responseStub, request, buildRouteParams("101"))
+	getCard(responseStub, request, buildRouteParams("101"))
 	assert.Equal(suite.T(), 500, responseStub.status)
 
 	// Case: Authorized GET, No Error
 	responseStub = newResponseWriter()
-	controller.getCard(responseStub, request, buildRouteParams("101"))
+	getCard(responseStub, request, buildRouteParams("101"))
 	assert.Equal(suite.T(), 200, responseStub.status)
 	var result model.Card
 	err := json.Unmarshal(responseStub.body, &result)
@@ -193,31 +314,194 @@ func (suite *CardControllerTestSuite) TestGetCard() {
 	assert.Equal(suite.T(), result, *(suite.seedModels[0]))
 }
 
-func (suite *CardControllerTestSuite) TestCreateCard() {
+func (suite *CardControllerTestSuite) TestSearchCards() {
 	mockCtrl := gomock.NewController(suite.T())
 	defer mockCtrl.Finish()
 
 	authenticator := mocks.NewMockTokenAuthenticator(mockCtrl)
 	cardAdapter := mocks.NewMockDatabaseCardAdapter(mockCtrl)
+	searchResult := &model.CardSearchResult{
+		Card:             *suite.seedModels[0],
+		Rank:             0.5,
+		PokemonHighlight: "<mark>AAA</mark>",
+	}
+	gomock.InOrder(
+		cardAdapter.EXPECT().SearchCards(OWNER_ID, "aa", defaultSearchLimit).Return(nil, errors.New("Test error")),
+		cardAdapter.EXPECT().SearchCards(OWNER_ID, "aa", 5).Return([]*model.CardSearchResult{searchResult}, nil),
+	)
 	gomock.InOrder(
-		// Card already exists
-		cardAdapter.EXPECT().GetCardByUniqueId(gomock.Eq("CARD-101")).Return(suite.seedModels[0], nil),
+		authenticator.EXPECT().Authenticate(UNAUTH_TOKEN).Return(nil),
+		authenticator.EXPECT().Authenticate(AUTH_TOKEN).Return(suite.principal).Times(5),
+	)
+	controller := &cardController{
+		db: cardAdapter,
+		baseController: baseController{
+			authenticator: authenticator,
+		},
+	}
+	searchCards := controller.authenticateRequest(auth.ScopeRead, controller.searchCards)
 
-		// DB Error 1
-		cardAdapter.EXPECT().GetCardByUniqueId(gomock.Eq("CARD-101")).Return(nil, errors.New("Test Error")),
+	// Case: Unauthorized GET
+	responseStub := newResponseWriter()
+	searchCards(responseStub, buildRoutedHTTPRequest(http.MethodGet, "/api/card/search?q=aa", suite.unauthHeader), EMPTY_PARAMS)
+	assert.Equal(suite.T(), 401, responseStub.status)
 
-		// DB Error 2
-		cardAdapter.EXPECT().GetCardByUniqueId(gomock.Eq("CARD-101")).Return(nil, nil),
-		cardAdapter.EXPECT().CreateCard(gomock.Any()).Return(nil, errors.New("Test Error")),
+	// Case: Missing or invalid parameters
+	for _, route := range []string{"/api/card/search", "/api/card/search?q=%20", "/api/card/search?q=aa&limit=0"} {
+		responseStub = newResponseWriter()
+		searchCards(responseStub, buildRoutedHTTPRequest(http.MethodGet, route, suite.authHeader), EMPTY_PARAMS)
+		assert.Equal(suite.T(), 400, responseStub.status, route)
+	}
+
+	// Case: Authorized GET, DB Error
+	responseStub = newResponseWriter()
+	searchCards(responseStub, buildRoutedHTTPRequest(http.MethodGet, "/api/card/search?q=aa", suite.authHeader), EMPTY_PARAMS)
+	assert.Equal(suite.T(), 500, responseStub.status)
+
+	// Case: Authorized GET, No Error
+	responseStub = newResponseWriter()
+	searchCards(responseStub, buildRoutedHTTPRequest(http.MethodGet, "/api/card/search?q=aa&limit=5", suite.authHeader), EMPTY_PARAMS)
+	assert.Equal(suite.T(), 200, responseStub.status)
+	var result struct {
+		Items []model.CardSearchResult `json:"items"`
+	}
+	err := json.Unmarshal(responseStub.body, &result)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []model.CardSearchResult{*searchResult}, result.Items)
+}
+
+func (suite *CardControllerTestSuite) TestSearchRoute() {
+	mockCtrl := gomock.NewController(suite.T())
+	defer mockCtrl.Finish()
+
+	authenticator := mocks.NewMockTokenAuthenticator(mockCtrl)
+	authenticator.EXPECT().Authenticate(AUTH_TOKEN).Return(suite.principal).AnyTimes()
+	cardAdapter := mocks.NewMockDatabaseCardAdapter(mockCtrl)
+	cardAdapter.EXPECT().SearchCards(OWNER_ID, "aa", defaultSearchLimit).Return(nil, nil)
+	cardAdapter.EXPECT().GetCard(OWNER_ID, 101).Return(suite.seedModels[0], nil)
+
+	controller := &cardController{
+		db: cardAdapter,
+		baseController: baseController{
+			authenticator: authenticator,
+		},
+	}
+	httpServer := server.CreateHTTPServer(0)
+	controller.Attach(httpServer)
+
+	// The search route shares its path segment with the card ID wildcard
+	responseStub := newResponseWriter()
+	httpServer.GetRouter().ServeHTTP(responseStub, buildRoutedHTTPRequest(http.MethodGet, "/api/card/search?q=aa", suite.authHeader))
+	assert.Equal(suite.T(), 200, responseStub.status)
+	assert.JSONEq(suite.T(), `{"items": []}`, string(responseStub.body))
+
+	responseStub = newResponseWriter()
+	httpServer.GetRouter().ServeHTTP(responseStub, buildRoutedHTTPRequest(http.MethodGet, "/api/card/101", suite.authHeader))
+	assert.Equal(suite.T(), 200, responseStub.status)
+}
+
+func (suite *CardControllerTestSuite) TestExportCards() {
+	mockCtrl := gomock.NewController(suite.T())
+	defer mockCtrl.Finish()
+
+	authenticator := mocks.NewMockTokenAuthenticator(mockCtrl)
+	authenticator.EXPECT().Authenticate(AUTH_TOKEN).Return(suite.principal).AnyTimes()
+	cardAdapter := mocks.NewMockDatabaseCardAdapter(mockCtrl)
+	cardAdapter.EXPECT().RunInSnapshot(gomock.Any()).DoAndReturn(
+		func(fn func(txAdapter database.DatabaseCardAdapter) error) error {
+			return fn(cardAdapter)
+		}).Times(4)
+	exportedCards := []*model.Card{
+		{Id: 101, UniqueId: "xy1-1", Pokemon: "Venusaur-EX", ImageUrl: "http://example.com/1", Version: 1},
+		{Id: 102, UniqueId: "PROMO", Pokemon: "Pikachu, Jr", ImageUrl: "http://example.com/2", WantQuantity: 3, HaveQuantity: 1, Condition: "LP", Language: "JA", Finish: "holo", Priority: "high", TargetPrice: 12.5, Version: 1},
+		{Id: 103, UniqueId: "@xy1-2", Pokemon: "=HYPERLINK(\"http://example.com\")", ImageUrl: "http://example.com/3", Version: 1},
+	}
+	exportedCards[0].ApplyDefaults()
+	exportedCards[2].ApplyDefaults()
+	gomock.InOrder(
+		cardAdapter.EXPECT().CountCards(OWNER_ID).Return(nil, errors.New("Test Error")),
+		cardAdapter.EXPECT().CountCards(OWNER_ID).Return(&model.CardTotals{Cards: 3, WantQuantity: 5}, nil).Times(3),
+	)
+	cardAdapter.EXPECT().StreamCards(OWNER_ID, gomock.Any()).DoAndReturn(func(ownerId int, handler func(*model.Card) error) error {
+		for _, card := range exportedCards {
+			err := handler(card)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Times(3)
+	controller := &cardController{
+		db: cardAdapter,
+		baseController: baseController{
+			authenticator: authenticator,
+		},
+	}
+	httpServer := server.CreateHTTPServer(0)
+	controller.Attach(httpServer)
+	export := func(route string) *StubResponseWriter {
+		responseStub := newResponseWriter()
+		httpServer.GetRouter().ServeHTTP(responseStub, buildRoutedHTTPRequest(http.MethodGet, route, suite.authHeader))
+		return responseStub
+	}
+
+	// Case: Unknown format
+	responseStub := export("/api/card/export?format=xml")
+	assert.Equal(suite.T(), 400, responseStub.status)
+
+	// Case: DB Error
+	responseStub = export("/api/card/export")
+	assert.Equal(suite.T(), 500, responseStub.status)
+
+	// Case: CSV, the default
+	responseStub = export("/api/card/export")
+	assert.Equal(suite.T(), 200, responseStub.status)
+	assert.Equal(suite.T(), "3", http.Header(responseStub.headers).Get("X-Total-Count"))
+	assert.Contains(suite.T(), http.Header(responseStub.headers).Get("Content-Disposition"), "wishlist.csv")
+	assert.Equal(suite.T(), "uniqueId,pokemon,imageUrl,wantQuantity,haveQuantity,condition,language,finish,priority,targetPrice\n"+
+		"xy1-1,Venusaur-EX,http://example.com/1,1,0,NM,EN,normal,medium,\n"+
+		"PROMO,\"Pikachu, Jr\",http://example.com/2,3,1,LP,JA,holo,high,12.5\n"+
+		"'@xy1-2,\"'=HYPERLINK(\"\"http://example.com\"\")\",http://example.com/3,1,0,NM,EN,normal,medium,\n", string(responseStub.body))
+
+	// Case: JSON Lines
+	responseStub = export("/api/card/export?format=jsonl")
+	assert.Equal(suite.T(), 200, responseStub.status)
+	lines := strings.Split(strings.TrimSpace(string(responseStub.body)), "\n")
+	assert.Len(suite.T(), lines, 3)
+	var card model.Card
+	assert.Nil(suite.T(), json.Unmarshal([]byte(lines[1]), &card))
+	assert.Equal(suite.T(), *exportedCards[1], card)
+
+	// Case: Deck list
+	responseStub = export("/api/card/export?format=ptcgo")
+	assert.Equal(suite.T(), 200, responseStub.status)
+	assert.Equal(suite.T(), "Pokémon: 5\n"+
+		"1 Venusaur-EX XY1 1\n"+
+		"3 Pikachu, Jr PROMO\n"+
+		"1 =HYPERLINK(\"http://example.com\") @XY1 2\n"+
+		"\nTotal Cards: 5\n", string(responseStub.body))
+}
+
+func (suite *CardControllerTestSuite) TestCreateCard() {
+	mockCtrl := gomock.NewController(suite.T())
+	defer mockCtrl.Finish()
+
+	authenticator := mocks.NewMockTokenAuthenticator(mockCtrl)
+	cardAdapter := mocks.NewMockDatabaseCardAdapter(mockCtrl)
+	gomock.InOrder(
+		// Card already exists, caught by the unique constraint
+		cardAdapter.EXPECT().CreateCard(OWNER_ID, gomock.Any()).Return(nil, &database.UniqueKeyError{}),
+
+		// DB Error
+		cardAdapter.EXPECT().CreateCard(OWNER_ID, gomock.Any()).Return(nil, errors.New("Test Error")),
 
 		// Successful Create
-		cardAdapter.EXPECT().GetCardByUniqueId(gomock.Any()).Return(nil, nil),
-		cardAdapter.EXPECT().CreateCard(gomock.Eq(
-			&model.Card{
+		cardAdapter.EXPECT().CreateCard(OWNER_ID, gomock.Eq(
+			withCardDefaults(&model.Card{
 				UniqueId: "CARD-200",
 				Pokemon:  "AAA",
 				ImageUrl: VALID_URL,
-			},
+			}),
 		)).Return(&model.Card{
 			Id:       200,
 			UniqueId: "CARD-200",
@@ -226,8 +510,8 @@ func (suite *CardControllerTestSuite) TestCreateCard() {
 		}, nil),
 	)
 	gomock.InOrder(
-		authenticator.EXPECT().IsValidToken(UNAUTH_TOKEN).Return(false),
-		authenticator.EXPECT().IsValidToken(AUTH_TOKEN).Return(true).AnyTimes(),
+		authenticator.EXPECT().Authenticate(UNAUTH_TOKEN).Return(nil),
+		authenticator.EXPECT().Authenticate(AUTH_TOKEN).Return(suite.principal).AnyTimes(),
 	)
 	controller := &cardController{
 		db: cardAdapter,
@@ -235,17 +519,18 @@ func (suite *CardControllerTestSuite) TestCreateCard() {
 			authenticator: authenticator,
 		},
 	}
+	createCard := controller.authenticateRequest(auth.ScopeWrite, controller.createCard)
 
 	// Unauthorized POST
 	request := buildHTTPRequest(suite.unauthHeader, nil)
 	responseStub := newResponseWriter()
-	controller.createCard(responseStub, request, EMPTY_PARAMS)
+	createCard(responseStub, request, EMPTY_PARAMS)
 	assert.Equal(suite.T(), 401, responseStub.status)
 
 	// Authorized, no body
 	request = buildHTTPRequest(suite.authHeader, "")
 	responseStub = newResponseWriter()
-	controller.createCard(responseStub, request, EMPTY_PARAMS)
+	createCard(responseStub, request, EMPTY_PARAMS)
 	assert.Equal(suite.T(), 400, responseStub.status)
 
 	// Authorized, no Unique ID
@@ -255,7 +540,7 @@ func (suite *CardControllerTestSuite) TestCreateCard() {
 		ImageUrl: VALID_URL,
 	})
 	responseStub = newResponseWriter()
-	controller.createCard(responseStub, request, EMPTY_PARAMS)
+	createCard(responseStub, request, EMPTY_PARAMS)
 	assert.Equal(suite.T(), 400, responseStub.status)
 
 	// Authorized, no Pokemon
@@ -265,7 +550,7 @@ func (suite *CardControllerTestSuite) TestCreateCard() {
 		ImageUrl: VALID_URL,
 	})
 	responseStub = newResponseWriter()
-	controller.createCard(responseStub, request, EMPTY_PARAMS)
+	createCard(responseStub, request, EMPTY_PARAMS)
 	assert.Equal(suite.T(), 400, responseStub.status)
 
 	// Authorized, no image URL
@@ -275,7 +560,7 @@ func (suite *CardControllerTestSuite) TestCreateCard() {
 		ImageUrl: "",
 	})
 	responseStub = newResponseWriter()
-	controller.createCard(responseStub, request, EMPTY_PARAMS)
+	createCard(responseStub, request, EMPTY_PARAMS)
 	assert.Equal(suite.T(), 400, responseStub.status)
 
 	// Authorized, bad image URL
@@ -285,47 +570,59 @@ func (suite *CardControllerTestSuite) TestCreateCard() {
 		ImageUrl: INVALID_URL,
 	})
 	responseStub = newResponseWriter()
-	controller.createCard(responseStub, request, EMPTY_PARAMS)
+	createCard(responseStub, request, EMPTY_PARAMS)
 	assert.Equal(suite.T(), 400, responseStub.status)
 
-	// Authorized, card already exists
-	request = buildHTTPRequest(suite.authHeader, &model.Card{
-		UniqueId: "CARD-101",
-		Pokemon:  "AAA",
-		ImageUrl: VALID_URL,
-	})
-	responseStub = newResponseWriter()
-	controller.createCard(responseStub, request, EMPTY_PARAMS)
-	assert.Equal(suite.T(), 403, responseStub.status)
+	// Authorized, invalid quantities, printing details, priority or target price
+	for _, card := range []*model.Card{
+		{UniqueId: "CARD-200", Pokemon: "AAA", ImageUrl: VALID_URL, WantQuantity: -1},
+		{UniqueId: "CARD-200", Pokemon: "AAA", ImageUrl: VALID_URL, HaveQuantity: -1},
+		{UniqueId: "CARD-200", Pokemon: "AAA", ImageUrl: VALID_URL, Condition: "Mint"},
+		{UniqueId: "CARD-200", Pokemon: "AAA", ImageUrl: VALID_URL, Language: "XX"},
+		{UniqueId: "CARD-200", Pokemon: "AAA", ImageUrl: VALID_URL, Finish: "gold"},
+		{UniqueId: "CARD-200", Pokemon: "AAA", ImageUrl: VALID_URL, Priority: "urgent"},
+		{UniqueId: "CARD-200", Pokemon: "AAA", ImageUrl: VALID_URL, TargetPrice: -1},
+	} {
+		responseStub = newResponseWriter()
+		createCard(responseStub, buildHTTPRequest(suite.authHeader, card), EMPTY_PARAMS)
+		assert.Equal(suite.T(), 400, responseStub.status)
+		assert.Contains(suite.T(), string(responseStub.body), "errorMsg")
+	}
 
-	// DB Error 1
+	// Authorized, card already exists
 	request = buildHTTPRequest(suite.authHeader, &model.Card{
 		UniqueId: "CARD-101",
 		Pokemon:  "AAA",
 		ImageUrl: VALID_URL,
 	})
 	responseStub = newResponseWriter()
-	controller.createCard(responseStub, request, EMPTY_PARAMS)
-	assert.Equal(suite.T(), 500, responseStub.status)
+	createCard(responseStub, request, EMPTY_PARAMS)
+	assert.Equal(suite.T(), 409, responseStub.status)
 
-	// DB Error 2
+	// DB Error
 	request = buildHTTPRequest(suite.authHeader, &model.Card{
 		UniqueId: "CARD-101",
 		Pokemon:  "AAA",
 		ImageUrl: VALID_URL,
 	})
 	responseStub = newResponseWriter()
-	controller.createCard(responseStub, request, EMPTY_PARAMS)
+	createCard(responseStub, request, EMPTY_PARAMS)
 	assert.Equal(suite.T(), 500, responseStub.status)
 
-	// Successful Create
+	// Successful Create, dropping the catalogue details given for the card
+	forgedAt := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
 	request = buildHTTPRequest(suite.authHeader, model.Card{
 		UniqueId: "CARD-200",
 		Pokemon:  "AAA",
 		ImageUrl: VALID_URL,
+		SetName:  "Forged",
+		Rarity:   "Secret Rare",
+		Types:    []string{"Dragon"},
+		Number:   "999",
+		SyncedAt: &forgedAt,
 	})
 	responseStub = newResponseWriter()
-	controller.createCard(responseStub, request, EMPTY_PARAMS)
+	createCard(responseStub, request, EMPTY_PARAMS)
 	assert.Equal(suite.T(), 200, responseStub.status)
 	var result model.Card
 	err := json.Unmarshal(responseStub.body, &result)
@@ -338,58 +635,222 @@ func (suite *CardControllerTestSuite) TestCreateCard() {
 	}, result)
 }
 
+func (suite *CardControllerTestSuite) TestImportCards() {
+	mockCtrl := gomock.NewController(suite.T())
+	defer mockCtrl.Finish()
+
+	authenticator := mocks.NewMockTokenAuthenticator(mockCtrl)
+	authenticator.EXPECT().Authenticate(UNAUTH_TOKEN).Return(nil)
+	authenticator.EXPECT().Authenticate(AUTH_TOKEN).Return(suite.principal).AnyTimes()
+	cardAdapter := mocks.NewMockDatabaseCardAdapter(mockCtrl)
+	createdCard := func(id int, uniqueId string) *model.Card {
+		return &model.Card{
+			Id:       id,
+			OwnerId:  OWNER_ID,
+			UniqueId: uniqueId,
+			Pokemon:  "AAA",
+			ImageUrl: VALID_URL,
+			Version:  1,
+		}
+	}
+	gomock.InOrder(
+		// JSON import
+		cardAdapter.EXPECT().CreateCardIfAbsent(OWNER_ID, withCardDefaults(&model.Card{UniqueId: "CARD-201", Pokemon: "AAA", ImageUrl: VALID_URL})).Return(createdCard(201, "CARD-201"), nil),
+		cardAdapter.EXPECT().CreateCardIfAbsent(OWNER_ID, withCardDefaults(&model.Card{UniqueId: "CARD-101", Pokemon: "AAA", ImageUrl: VALID_URL})).Return(nil, nil),
+		cardAdapter.EXPECT().CreateCardIfAbsent(OWNER_ID, withCardDefaults(&model.Card{UniqueId: "CARD-202", Pokemon: "AAA", ImageUrl: VALID_URL})).Return(nil, errors.New("Test Error")),
+
+		// CSV import
+		cardAdapter.EXPECT().CreateCardIfAbsent(OWNER_ID, withCardDefaults(&model.Card{UniqueId: "CARD-203", Pokemon: "AAA", ImageUrl: VALID_URL})).Return(createdCard(203, "CARD-203"), nil),
+		cardAdapter.EXPECT().CreateCardIfAbsent(OWNER_ID, withCardDefaults(&model.Card{UniqueId: "CARD-204", Pokemon: "A, B", ImageUrl: VALID_URL, WantQuantity: 4, Condition: "LP"})).Return(createdCard(204, "CARD-204"), nil),
+		cardAdapter.EXPECT().CreateCardIfAbsent(OWNER_ID, withCardDefaults(&model.Card{UniqueId: "CARD-205", Pokemon: "=A+B", ImageUrl: VALID_URL})).Return(createdCard(205, "CARD-205"), nil),
+	)
+	controller := &cardController{
+		db: cardAdapter,
+		baseController: baseController{
+			authenticator: authenticator,
+		},
+	}
+	importCards := controller.authenticateRequest(auth.ScopeWrite, controller.importCards)
+	headersWithType := func(contentType string) map[string][]string {
+		return map[string][]string{
+			"Authorization": suite.authHeader["Authorization"],
+			"Content-Type":  {contentType},
+		}
+	}
+	var result struct {
+		Created int `json:"created"`
+		Failed  int `json:"failed"`
+		Results []struct {
+			Row    int         `json:"row"`
+			Status string      `json:"status"`
+			Card   *model.Card `json:"card"`
+			Error  string      `json:"error"`
+		} `json:"results"`
+	}
+
+	// Case: Unauthorized POST
+	responseStub := newResponseWriter()
+	importCards(responseStub, buildHTTPRequest(suite.unauthHeader, []interface{}{}), EMPTY_PARAMS)
+	assert.Equal(suite.T(), 401, responseStub.status)
+
+	// Case: Malformed imports are rejected as a whole
+	malformedImports := []struct {
+		contentType string
+		body        string
+		status      int
+	}{
+		{"application/xml", "<cards/>", 415},
+		{"application/json", `{"uniqueId": "CARD-201"}`, 400},
+		{"application/json", `[]`, 400},
+		{csvContentType, "uniqueId,pokemon\nCARD-201,AAA\n", 400},
+		{csvContentType, "uniqueId,pokemon,imageUrl\n\"CARD-201,AAA\n", 400},
+	}
+	for _, malformed := range malformedImports {
+		responseStub = newResponseWriter()
+		importCards(responseStub, buildRawHTTPRequest(headersWithType(malformed.contentType), malformed.body), EMPTY_PARAMS)
+		assert.Equal(suite.T(), malformed.status, responseStub.status, malformed.body)
+	}
+
+	// Case: JSON import reports every row
+	responseStub = newResponseWriter()
+	importCards(responseStub, buildHTTPRequest(suite.authHeader, []interface{}{
+		// Catalogue details given for the card are dropped
+		map[string]interface{}{
+			"uniqueId": "CARD-201", "pokemon": "AAA", "imageUrl": VALID_URL,
+			"setName": "Forged", "rarity": "Secret Rare", "types": []string{"Dragon"}, "number": "999", "syncedAt": "2022-10-01T00:00:00Z",
+		},
+		map[string]string{"uniqueId": "CARD-101", "pokemon": "AAA", "imageUrl": VALID_URL},
+		map[string]string{"uniqueId": "CARD-202", "pokemon": "AAA", "imageUrl": VALID_URL},
+		map[string]string{"uniqueId": "CARD-205", "pokemon": "AAA", "imageUrl": INVALID_URL},
+		map[string]string{"uniqueId": "CARD-206", "pokemon": "", "imageUrl": VALID_URL},
+		"not a card",
+	}), EMPTY_PARAMS)
+	assert.Equal(suite.T(), 200, responseStub.status)
+	assert.Nil(suite.T(), json.Unmarshal(responseStub.body, &result))
+	assert.Equal(suite.T(), 1, result.Created)
+	assert.Equal(suite.T(), 5, result.Failed)
+	statuses := make([]string, 0)
+	for i, row := range result.Results {
+		assert.Equal(suite.T(), i+1, row.Row)
+		statuses = append(statuses, row.Status)
+	}
+	assert.Equal(suite.T(), []string{
+		importStatusCreated,
+		importStatusConflict,
+		importStatusError,
+		importStatusInvalid,
+		importStatusInvalid,
+		importStatusInvalid,
+	}, statuses)
+	assert.Equal(suite.T(), 201, result.Results[0].Card.Id)
+	assert.Equal(suite.T(), errCardUrlInvalid.Error(), result.Results[3].Error)
+
+	// Case: CSV import with columns in any order, optional ones left blank or
+	// out, and quoted formulas from an export read back as they were
+	responseStub = newResponseWriter()
+	csvBody := "imageUrl,UniqueId,pokemon,wantQuantity,condition\n" +
+		VALID_URL + ",CARD-203,AAA,,\n" +
+		VALID_URL + ",CARD-204,\"A, B\",4,LP\n" +
+		VALID_URL + ",CARD-205,'=A+B,,\n" +
+		VALID_URL + ",CARD-207\n" +
+		VALID_URL + ",CARD-208,AAA,four,LP\n"
+	importCards(responseStub, buildRawHTTPRequest(headersWithType("text/csv; charset=utf-8"), csvBody), EMPTY_PARAMS)
+	assert.Equal(suite.T(), 200, responseStub.status)
+	assert.Nil(suite.T(), json.Unmarshal(responseStub.body, &result))
+	assert.Equal(suite.T(), 3, result.Created)
+	assert.Equal(suite.T(), 2, result.Failed)
+	assert.Equal(suite.T(), importStatusInvalid, result.Results[3].Status)
+	assert.Equal(suite.T(), importStatusInvalid, result.Results[4].Status)
+}
+
 func (suite *CardControllerTestSuite) TestEditCard() {
 	mockCtrl := gomock.NewController(suite.T())
 	defer mockCtrl.Finish()
 
 	authenticator := mocks.NewMockTokenAuthenticator(mockCtrl)
 	cardAdapter := mocks.NewMockDatabaseCardAdapter(mockCtrl)
+	expectTransactions(cardAdapter)
+	syncedAt := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	syncedCard := *suite.seedModels[0]
+	syncedCard.SetName = "XY"
+	syncedCard.Rarity = "Rare Holo EX"
+	syncedCard.Types = []string{"Grass"}
+	syncedCard.Number = "1"
+	syncedCard.SyncedAt = &syncedAt
+	collectedCard := model.Card{
+		Id:           101,
+		UniqueId:     "CARD-101",
+		Pokemon:      "XXXX",
+		ImageUrl:     VALID_URL,
+		WantQuantity: 3,
+		HaveQuantity: 1,
+		Condition:    model.CardConditionLightlyPlayed,
+		Language:     "JA",
+		Finish:       model.CardFinishHolo,
+		Priority:     model.CardPriorityHigh,
+		TargetPrice:  12.5,
+	}
 	gomock.InOrder(
-		cardAdapter.EXPECT().GetCardByUniqueId(gomock.Eq("CARD-102")).Return(suite.seedModels[1], nil),
+		// Unique ID taken by another card, caught by the unique constraint
+		cardAdapter.EXPECT().GetCard(OWNER_ID, gomock.Eq(101)).Return(suite.seedModels[0], nil),
+		cardAdapter.EXPECT().EditCard(OWNER_ID, gomock.Any(), gomock.Any()).Return(&database.UniqueKeyError{}),
 
 		// Card not found
-		cardAdapter.EXPECT().GetCardByUniqueId(gomock.Eq("CARD-300")).Return(nil, nil),
-		cardAdapter.EXPECT().GetCard(gomock.Eq(200)).Return(nil, nil),
+		cardAdapter.EXPECT().GetCard(OWNER_ID, gomock.Eq(200)).Return(nil, nil),
 
 		// DB Error 1
-		cardAdapter.EXPECT().GetCardByUniqueId(gomock.Eq("CARD-101")).Return(nil, errors.New("Test Error")),
+		cardAdapter.EXPECT().GetCard(OWNER_ID, gomock.Eq(101)).Return(nil, errors.New("Test Error")),
 
 		// DB Error 2
-		cardAdapter.EXPECT().GetCardByUniqueId(gomock.Eq("CARD-101")).Return(suite.seedModels[0], nil),
-		cardAdapter.EXPECT().GetCard(gomock.Eq(101)).Return(nil, errors.New("Test Error")),
-
-		// DB Error 3
-		cardAdapter.EXPECT().GetCardByUniqueId(gomock.Eq("CARD-101")).Return(suite.seedModels[0], nil),
-		cardAdapter.EXPECT().GetCard(gomock.Eq(101)).Return(suite.seedModels[0], nil),
-		cardAdapter.EXPECT().EditCard(gomock.Any()).Return(errors.New("Test Error")),
-
-		// Success Call 1
-		cardAdapter.EXPECT().GetCardByUniqueId(gomock.Eq("CARD-101")).Return(suite.seedModels[0], nil),
-		cardAdapter.EXPECT().GetCard(gomock.Eq(101)).Return(suite.seedModels[0], nil),
-		cardAdapter.EXPECT().EditCard(gomock.Eq(
-			&model.Card{
+		cardAdapter.EXPECT().GetCard(OWNER_ID, gomock.Eq(101)).Return(suite.seedModels[0], nil),
+		cardAdapter.EXPECT().EditCard(OWNER_ID, gomock.Any()).Return(errors.New("Test Error")),
+
+		// Success Call 1, keeping the catalogue details
+		cardAdapter.EXPECT().GetCard(OWNER_ID, gomock.Eq(101)).Return(&syncedCard, nil),
+		cardAdapter.EXPECT().EditCard(OWNER_ID, gomock.Eq(
+			withCardDefaults(&model.Card{
 				Id:       101,
 				UniqueId: "CARD-101",
 				Pokemon:  "XXXX",
 				ImageUrl: VALID_URL,
-			},
+				SetName:  "XY",
+				Rarity:   "Rare Holo EX",
+				Types:    []string{"Grass"},
+				Number:   "1",
+				SyncedAt: &syncedAt,
+			}),
 		)).Return(nil),
 
-		// Sucess Call 2
-		cardAdapter.EXPECT().GetCardByUniqueId(gomock.Eq("CARD-300")).Return(nil, nil),
-		cardAdapter.EXPECT().GetCard(gomock.Eq(101)).Return(suite.seedModels[0], nil),
-		cardAdapter.EXPECT().EditCard(gomock.Eq(
-			&model.Card{
+		// Sucess Call 2, clearing the catalogue details of the old unique ID
+		cardAdapter.EXPECT().GetCard(OWNER_ID, gomock.Eq(101)).Return(&syncedCard, nil),
+		cardAdapter.EXPECT().EditCard(OWNER_ID, gomock.Eq(
+			withCardDefaults(&model.Card{
 				Id:       101,
 				UniqueId: "CARD-300",
 				Pokemon:  "BBB",
 				ImageUrl: VALID_URL,
-			},
-		)).Return(nil),
+			}),
+		), cardFieldsWithCatalogueDetails(model.CardEditableFields)).Return(nil),
+
+		// Success Call 3, keeping the stored values of fields left out
+		cardAdapter.EXPECT().GetCard(OWNER_ID, gomock.Eq(101)).Return(&collectedCard, nil),
+		cardAdapter.EXPECT().EditCard(OWNER_ID, gomock.Eq(&model.Card{
+			Id:           101,
+			UniqueId:     "CARD-101",
+			Pokemon:      "Venusaur",
+			ImageUrl:     VALID_URL,
+			WantQuantity: 3,
+			HaveQuantity: 1,
+			Condition:    model.CardConditionLightlyPlayed,
+			Language:     "JA",
+			Finish:       model.CardFinishHolo,
+			Priority:     model.CardPriorityHigh,
+			TargetPrice:  12.5,
+		})).Return(nil),
 	)
 	gomock.InOrder(
-		authenticator.EXPECT().IsValidToken("AAA").Return(false),
-		authenticator.EXPECT().IsValidToken("BBB").Return(true).AnyTimes(),
+		authenticator.EXPECT().Authenticate("AAA").Return(nil),
+		authenticator.EXPECT().Authenticate("BBB").Return(suite.principal).AnyTimes(),
 	)
 	controller := &cardController{
 		db: cardAdapter,
@@ -397,11 +858,12 @@ func (suite *CardControllerTestSuite) TestEditCard() {
 			authenticator: authenticator,
 		},
 	}
+	editCard := controller.authenticateRequest(auth.ScopeWrite, controller.editCard)
 
 	// Unauthorized PUT
 	request := buildHTTPRequest(suite.unauthHeader, nil)
 	responseStub := newResponseWriter()
-	controller.editCard(responseStub, request, buildRouteParams("101"))
+	editCard(responseStub, request, buildRouteParams("101"))
 	assert.Equal(suite.T(), 401, responseStub.status)
 
 	// Authorized, no route param
@@ -412,13 +874,13 @@ func (suite *CardControllerTestSuite) TestEditCard() {
 		ImageUrl: VALID_URL,
 	})
 	responseStub = newResponseWriter()
-	controller.editCard(responseStub, request, EMPTY_PARAMS)
+	editCard(responseStub, request, EMPTY_PARAMS)
 	assert.Equal(suite.T(), 400, responseStub.status)
 
 	// Authorized, no body
 	request = buildHTTPRequest(suite.authHeader, "")
 	responseStub = newResponseWriter()
-	controller.editCard(responseStub, request, buildRouteParams("101"))
+	editCard(responseStub, request, buildRouteParams("101"))
 	assert.Equal(suite.T(), 400, responseStub.status)
 
 	// Authorized, no ID
@@ -429,7 +891,7 @@ func (suite *CardControllerTestSuite) TestEditCard() {
 		ImageUrl: VALID_URL,
 	})
 	responseStub = newResponseWriter()
-	controller.editCard(responseStub, request, buildRouteParams("101"))
+	editCard(responseStub, request, buildRouteParams("101"))
 	assert.Equal(suite.T(), 400, responseStub.status)
 
 	// Authorized, no Unique ID
@@ -440,7 +902,7 @@ func (suite *CardControllerTestSuite) TestEditCard() {
 		ImageUrl: VALID_URL,
 	})
 	responseStub = newResponseWriter()
-	controller.editCard(responseStub, request, buildRouteParams("101"))
+	editCard(responseStub, request, buildRouteParams("101"))
 	assert.Equal(suite.T(), 400, responseStub.status)
 
 	// Authorized, no Pokemon
@@ -451,7 +913,7 @@ func (suite *CardControllerTestSuite) TestEditCard() {
 		ImageUrl: VALID_URL,
 	})
 	responseStub = newResponseWriter()
-	controller.editCard(responseStub, request, buildRouteParams("101"))
+	editCard(responseStub, request, buildRouteParams("101"))
 	assert.Equal(suite.T(), 400, responseStub.status)
 
 	// Authorized, no URL
@@ -462,7 +924,7 @@ func (suite *CardControllerTestSuite) TestEditCard() {
 		ImageUrl: "",
 	})
 	responseStub = newResponseWriter()
-	controller.editCard(responseStub, request, buildRouteParams("101"))
+	editCard(responseStub, request, buildRouteParams("101"))
 	assert.Equal(suite.T(), 400, responseStub.status)
 
 	// Authorized, Bad URL
@@ -473,7 +935,7 @@ func (suite *CardControllerTestSuite) TestEditCard() {
 		ImageUrl: INVALID_URL,
 	})
 	responseStub = newResponseWriter()
-	controller.editCard(responseStub, request, buildRouteParams("101"))
+	editCard(responseStub, request, buildRouteParams("101"))
 	assert.Equal(suite.T(), 400, responseStub.status)
 
 	// Authorized, Route and Body ID Mismatch
@@ -484,7 +946,7 @@ func (suite *CardControllerTestSuite) TestEditCard() {
 		ImageUrl: VALID_URL,
 	})
 	responseStub = newResponseWriter()
-	controller.editCard(responseStub, request, buildRouteParams("101"))
+	editCard(responseStub, request, buildRouteParams("101"))
 	assert.Equal(suite.T(), 400, responseStub.status)
 
 	// Authorized, Card with same ID already exists
@@ -495,8 +957,8 @@ func (suite *CardControllerTestSuite) TestEditCard() {
 		ImageUrl: VALID_URL,
 	})
 	responseStub = newResponseWriter()
-	controller.editCard(responseStub, request, buildRouteParams("101"))
-	assert.Equal(suite.T(), 403, responseStub.status)
+	editCard(responseStub, request, buildRouteParams("101"))
+	assert.Equal(suite.T(), 409, responseStub.status)
 
 	// Authorized, Target Card not found
 	request = buildHTTPRequest(suite.authHeader, &model.Card{
@@ -506,7 +968,7 @@ func (suite *CardControllerTestSuite) TestEditCard() {
 		ImageUrl: VALID_URL,
 	})
 	responseStub = newResponseWriter()
-	controller.editCard(responseStub, request, buildRouteParams("200"))
+	editCard(responseStub, request, buildRouteParams("200"))
 	assert.Equal(suite.T(), 404, responseStub.status)
 
 	// Authorized, Database Error
@@ -517,7 +979,7 @@ func (suite *CardControllerTestSuite) TestEditCard() {
 		ImageUrl: VALID_URL,
 	})
 	responseStub = newResponseWriter()
-	controller.editCard(responseStub, request, buildRouteParams("101"))
+	editCard(responseStub, request, buildRouteParams("101"))
 	assert.Equal(suite.T(), 500, responseStub.status)
 
 	// DB Error 2
@@ -528,18 +990,7 @@ func (suite *CardControllerTestSuite) TestEditCard() {
 		ImageUrl: VALID_URL,
 	})
 	responseStub = newResponseWriter()
-	controller.editCard(responseStub, request, buildRouteParams("101"))
-	assert.Equal(suite.T(), 500, responseStub.status)
-
-	// DB Error 3
-	request = buildHTTPRequest(suite.authHeader, &model.Card{
-		Id:       101,
-		UniqueId: "CARD-101",
-		Pokemon:  "XXXX",
-		ImageUrl: VALID_URL,
-	})
-	responseStub = newResponseWriter()
-	controller.editCard(responseStub, request, buildRouteParams("101"))
+	editCard(responseStub, request, buildRouteParams("101"))
 	assert.Equal(suite.T(), 500, responseStub.status)
 
 	// Authorized, Change not Unique ID field
@@ -550,18 +1001,23 @@ func (suite *CardControllerTestSuite) TestEditCard() {
 		ImageUrl: VALID_URL,
 	})
 	responseStub = newResponseWriter()
-	controller.editCard(responseStub, request, buildRouteParams("101"))
+	editCard(responseStub, request, buildRouteParams("101"))
 	assert.Equal(suite.T(), 200, responseStub.status)
 	var result model.Card
 	err := json.Unmarshal(responseStub.body, &result)
 	assert.Nil(suite.T(), err)
 
-	assert.Equal(suite.T(), model.Card{
+	assert.Equal(suite.T(), *withCardDefaults(&model.Card{
 		Id:       101,
 		UniqueId: "CARD-101",
 		Pokemon:  "XXXX",
 		ImageUrl: VALID_URL,
-	}, result)
+		SetName:  "XY",
+		Rarity:   "Rare Holo EX",
+		Types:    []string{"Grass"},
+		Number:   "1",
+		SyncedAt: &syncedAt,
+	}), result)
 
 	// Authorized, Change Unique ID
 	request = buildHTTPRequest(suite.authHeader, &model.Card{
@@ -571,17 +1027,209 @@ func (suite *CardControllerTestSuite) TestEditCard() {
 		ImageUrl: VALID_URL,
 	})
 	responseStub = newResponseWriter()
-	controller.editCard(responseStub, request, buildRouteParams("101"))
+	editCard(responseStub, request, buildRouteParams("101"))
 	assert.Equal(suite.T(), 200, responseStub.status)
+	result = model.Card{}
 	err = json.Unmarshal(responseStub.body, &result)
 	assert.Nil(suite.T(), err)
 
-	assert.Equal(suite.T(), model.Card{
+	assert.Equal(suite.T(), *withCardDefaults(&model.Card{
 		Id:       101,
 		UniqueId: "CARD-300",
 		Pokemon:  "BBB",
 		ImageUrl: VALID_URL,
-	}, result)
+	}), result)
+
+	// Authorized, only the required fields, as the frontend sends them
+	request = buildRawHTTPRequest(suite.authHeader, fmt.Sprintf(
+		`{"id": 101, "uniqueId": "CARD-101", "pokemon": "Venusaur", "imageUrl": "%s"}`, VALID_URL,
+	))
+	responseStub = newResponseWriter()
+	editCard(responseStub, request, buildRouteParams("101"))
+	assert.Equal(suite.T(), 200, responseStub.status)
+	result = model.Card{}
+	err = json.Unmarshal(responseStub.body, &result)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 3, result.WantQuantity)
+	assert.Equal(suite.T(), 1, result.HaveQuantity)
+	assert.Equal(suite.T(), 12.5, result.TargetPrice)
+}
+
+func (suite *CardControllerTestSuite) TestPatchCard() {
+	mockCtrl := gomock.NewController(suite.T())
+	defer mockCtrl.Finish()
+
+	authenticator := mocks.NewMockTokenAuthenticator(mockCtrl)
+	authenticator.EXPECT().Authenticate(UNAUTH_TOKEN).Return(nil)
+	authenticator.EXPECT().Authenticate(AUTH_TOKEN).Return(suite.principal).AnyTimes()
+	cardAdapter := mocks.NewMockDatabaseCardAdapter(mockCtrl)
+	expectTransactions(cardAdapter)
+	targetCard := func() *model.Card {
+		card := *suite.seedModels[0]
+		return &card
+	}
+	syncedAt := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	syncedCard := func() *model.Card {
+		card := targetCard()
+		card.SetName = "XY"
+		card.Number = "1"
+		card.SyncedAt = &syncedAt
+		return card
+	}
+	gomock.InOrder(
+		// DB Error
+		cardAdapter.EXPECT().GetCard(OWNER_ID, 101).Return(nil, errors.New("Test Error")),
+
+		// Card does not exist
+		cardAdapter.EXPECT().GetCard(OWNER_ID, 999).Return(nil, nil),
+
+		// Invalid patches, rejected before any write
+		cardAdapter.EXPECT().GetCard(OWNER_ID, 101).DoAndReturn(func(int, int) (*model.Card, error) {
+			return targetCard(), nil
+		}).Times(12),
+
+		// Unique ID taken by another card, caught by the unique constraint
+		cardAdapter.EXPECT().GetCard(OWNER_ID, 101).Return(targetCard(), nil),
+		cardAdapter.EXPECT().EditCard(OWNER_ID, gomock.Any(), gomock.Any()).Return(&database.UniqueKeyError{}),
+
+		// No-op patch
+		cardAdapter.EXPECT().GetCard(OWNER_ID, 101).Return(targetCard(), nil),
+
+		// Merge patch only writes the changed field
+		cardAdapter.EXPECT().GetCard(OWNER_ID, 101).Return(targetCard(), nil),
+		cardAdapter.EXPECT().EditCard(OWNER_ID, withCardDefaults(&model.Card{
+			Id:       101,
+			UniqueId: "CARD-101",
+			Pokemon:  "Mew",
+			ImageUrl: "http://url1.something.com",
+		}), model.CardFieldPokemon).Return(nil),
+
+		// JSON patch, clearing the catalogue details of the old unique ID
+		cardAdapter.EXPECT().GetCard(OWNER_ID, 101).Return(syncedCard(), nil),
+		cardAdapter.EXPECT().EditCard(OWNER_ID, withCardDefaults(&model.Card{
+			Id:       101,
+			UniqueId: "CARD-500",
+			Pokemon:  "CARD-500",
+			ImageUrl: "http://url1.something.com",
+		}), model.CardFieldUniqueId, model.CardFieldPokemon, model.CardFieldSetName, model.CardFieldRarity,
+			model.CardFieldTypes, model.CardFieldNumber, model.CardFieldSyncedAt).Return(nil),
+
+		// Quantity patch
+		cardAdapter.EXPECT().GetCard(OWNER_ID, 101).Return(targetCard(), nil),
+		cardAdapter.EXPECT().EditCard(OWNER_ID, withCardDefaults(&model.Card{
+			Id:           101,
+			UniqueId:     "CARD-101",
+			Pokemon:      "AAA",
+			ImageUrl:     "http://url1.something.com",
+			HaveQuantity: 2,
+			Finish:       model.CardFinishHolo,
+		}), model.CardFieldHaveQuantity, model.CardFieldFinish).Return(nil),
+	)
+	controller := &cardController{
+		db: cardAdapter,
+		baseController: baseController{
+			authenticator: authenticator,
+		},
+	}
+	patchCard := controller.authenticateRequest(auth.ScopeWrite, controller.patchCard)
+	headersWithType := func(contentType string) map[string][]string {
+		return map[string][]string{
+			"Authorization": suite.authHeader["Authorization"],
+			"Content-Type":  {contentType},
+		}
+	}
+
+	// Case: Unauthorized PATCH
+	responseStub := newResponseWriter()
+	patchCard(responseStub, buildHTTPRequest(suite.unauthHeader, nil), buildRouteParams("101"))
+	assert.Equal(suite.T(), 401, responseStub.status)
+
+	// Case: Invalid route param
+	responseStub = newResponseWriter()
+	patchCard(responseStub, buildHTTPRequest(suite.authHeader, map[string]string{}), buildRouteParams("asdf"))
+	assert.Equal(suite.T(), 400, responseStub.status)
+
+	// Case: Unsupported content type
+	responseStub = newResponseWriter()
+	patchCard(responseStub, buildHTTPRequest(headersWithType("text/plain"), map[string]string{}), buildRouteParams("101"))
+	assert.Equal(suite.T(), 415, responseStub.status)
+
+	// Case: DB Error
+	responseStub = newResponseWriter()
+	patchCard(responseStub, buildHTTPRequest(suite.authHeader, map[string]string{}), buildRouteParams("101"))
+	assert.Equal(suite.T(), 500, responseStub.status)
+
+	// Case: Card does not exist
+	responseStub = newResponseWriter()
+	patchCard(responseStub, buildHTTPRequest(suite.authHeader, map[string]string{}), buildRouteParams("999"))
+	assert.Equal(suite.T(), 404, responseStub.status)
+
+	// Case: Invalid patches
+	invalidPatches := []struct {
+		contentType string
+		body        interface{}
+		status      int
+	}{
+		{mergePatchContentType, []string{"pokemon"}, 400},
+		{mergePatchContentType, map[string]interface{}{"pokemon": nil}, 400},
+		{mergePatchContentType, map[string]interface{}{"pokemon": 5}, 400},
+		{mergePatchContentType, map[string]interface{}{"id": 102}, 400},
+		{mergePatchContentType, map[string]interface{}{"imageUrl": INVALID_URL}, 400},
+		{mergePatchContentType, map[string]interface{}{"uniqueId": ""}, 400},
+		{jsonPatchContentType, []map[string]interface{}{{"op": "remove", "path": "/pokemon"}}, 400},
+		{jsonPatchContentType, []map[string]interface{}{{"op": "test", "path": "/pokemon", "value": "ZZZ"}}, 409},
+		{mergePatchContentType, map[string]interface{}{"haveQuantity": "two"}, 400},
+		{mergePatchContentType, map[string]interface{}{"condition": "Mint"}, 400},
+		{mergePatchContentType, map[string]interface{}{"targetPrice": "cheap"}, 400},
+		{jsonPatchContentType, []map[string]interface{}{{"op": "copy", "from": "/pokemon", "path": "/wantQuantity"}}, 400},
+	}
+	for _, patch := range invalidPatches {
+		responseStub = newResponseWriter()
+		patchCard(responseStub, buildHTTPRequest(headersWithType(patch.contentType), patch.body), buildRouteParams("101"))
+		assert.Equal(suite.T(), patch.status, responseStub.status, patch.body)
+	}
+
+	// Case: Unique ID taken by another card
+	responseStub = newResponseWriter()
+	patchCard(responseStub, buildHTTPRequest(suite.authHeader, map[string]string{"uniqueId": "CARD-102"}), buildRouteParams("101"))
+	assert.Equal(suite.T(), 409, responseStub.status)
+
+	// Case: Patch that changes nothing skips the write
+	responseStub = newResponseWriter()
+	patchCard(responseStub, buildHTTPRequest(suite.authHeader, map[string]interface{}{"id": 101, "pokemon": "AAA"}), buildRouteParams("101"))
+	assert.Equal(suite.T(), 200, responseStub.status)
+
+	// Case: Merge patch
+	responseStub = newResponseWriter()
+	patchCard(responseStub, buildHTTPRequest(headersWithType(mergePatchContentType), map[string]string{"pokemon": "Mew"}), buildRouteParams("101"))
+	assert.Equal(suite.T(), 200, responseStub.status)
+	var result model.Card
+	assert.Nil(suite.T(), json.Unmarshal(responseStub.body, &result))
+	assert.Equal(suite.T(), "Mew", result.Pokemon)
+	assert.Equal(suite.T(), "CARD-101", result.UniqueId)
+
+	// Case: JSON patch
+	responseStub = newResponseWriter()
+	patchCard(responseStub, buildHTTPRequest(headersWithType(jsonPatchContentType), []map[string]interface{}{
+		{"op": "test", "path": "/id", "value": 101},
+		{"op": "replace", "path": "/uniqueId", "value": "CARD-500"},
+		{"op": "copy", "from": "/uniqueId", "path": "/pokemon"},
+	}), buildRouteParams("101"))
+	assert.Equal(suite.T(), 200, responseStub.status)
+	assert.Nil(suite.T(), json.Unmarshal(responseStub.body, &result))
+	assert.Equal(suite.T(), "CARD-500", result.Pokemon)
+
+	// Case: JSON patch of quantities and printing details
+	responseStub = newResponseWriter()
+	patchCard(responseStub, buildHTTPRequest(headersWithType(jsonPatchContentType), []map[string]interface{}{
+		{"op": "test", "path": "/wantQuantity", "value": 1},
+		{"op": "replace", "path": "/haveQuantity", "value": 2},
+		{"op": "replace", "path": "/finish", "value": model.CardFinishHolo},
+	}), buildRouteParams("101"))
+	assert.Equal(suite.T(), 200, responseStub.status)
+	assert.Nil(suite.T(), json.Unmarshal(responseStub.body, &result))
+	assert.Equal(suite.T(), 2, result.HaveQuantity)
+	assert.Equal(suite.T(), model.CardFinishHolo, result.Finish)
 }
 
 func (suite *CardControllerTestSuite) TestDeleteCard() {
@@ -590,23 +1238,28 @@ func (suite *CardControllerTestSuite) TestDeleteCard() {
 
 	authenticator := mocks.NewMockTokenAuthenticator(mockCtrl)
 	cardAdapter := mocks.NewMockDatabaseCardAdapter(mockCtrl)
+	expectTransactions(cardAdapter)
 	gomock.InOrder(
 		// DB Error 1
-		cardAdapter.EXPECT().GetCard(gomock.Any()).Return(nil, errors.New("Test Error")),
+		cardAdapter.EXPECT().GetCard(OWNER_ID, gomock.Any()).Return(nil, errors.New("Test Error")),
 
 		// DB Error 2
-		cardAdapter.EXPECT().GetCard(gomock.Eq(101)).Return(suite.seedModels[0], nil),
-		cardAdapter.EXPECT().DeleteCard(gomock.Any()).Return(errors.New("Test error")),
+		cardAdapter.EXPECT().GetCard(OWNER_ID, gomock.Eq(101)).Return(suite.seedModels[0], nil),
+		cardAdapter.EXPECT().DeleteCard(OWNER_ID, gomock.Any(), 0).Return(errors.New("Test error")),
 
-		cardAdapter.EXPECT().GetCard(gomock.Eq(101)).Return(nil, nil),
+		cardAdapter.EXPECT().GetCard(OWNER_ID, gomock.Eq(101)).Return(nil, nil),
+
+		// Deleted by another request after it was read
+		cardAdapter.EXPECT().GetCard(OWNER_ID, gomock.Eq(101)).Return(suite.seedModels[0], nil),
+		cardAdapter.EXPECT().DeleteCard(OWNER_ID, gomock.Any(), 0).Return(&database.NotFoundError{}),
 
 		// Successful Delete
-		cardAdapter.EXPECT().GetCard(gomock.Eq(101)).Return(suite.seedModels[0], nil),
-		cardAdapter.EXPECT().DeleteCard(gomock.Any()).Return(nil),
+		cardAdapter.EXPECT().GetCard(OWNER_ID, gomock.Eq(101)).Return(suite.seedModels[0], nil),
+		cardAdapter.EXPECT().DeleteCard(OWNER_ID, gomock.Any(), 0).Return(nil),
 	)
 	gomock.InOrder(
-		authenticator.EXPECT().IsValidToken(UNAUTH_TOKEN).Return(false),
-		authenticator.EXPECT().IsValidToken(AUTH_TOKEN).Return(true).Times(6),
+		authenticator.EXPECT().Authenticate(UNAUTH_TOKEN).Return(nil),
+		authenticator.EXPECT().Authenticate(AUTH_TOKEN).Return(suite.principal).Times(7),
 	)
 	controller := &cardController{
 		db: cardAdapter,
@@ -614,47 +1267,54 @@ func (suite *CardControllerTestSuite) TestDeleteCard() {
 			authenticator: authenticator,
 		},
 	}
+	deleteCard := controller.authenticateRequest(auth.ScopeWrite, controller.deleteCard)
 
 	// Unauthorized DELETE
 	request := buildHTTPRequest(suite.unauthHeader, nil)
 	responseStub := newResponseWriter()
-	controller.deleteCard(responseStub, request, buildRouteParams("101"))
+	deleteCard(responseStub, request, buildRouteParams("101"))
 	assert.Equal(suite.T(), 401, responseStub.status)
 
 	// Authorized, Empty route params
 	request = buildHTTPRequest(suite.authHeader, nil)
 	responseStub = newResponseWriter()
-	controller.deleteCard(responseStub, request, EMPTY_PARAMS)
+	deleteCard(responseStub, request, EMPTY_PARAMS)
 	assert.Equal(suite.T(), 400, responseStub.status)
 
 	// Authorized, Bad Card ID
 	request = buildHTTPRequest(suite.authHeader, nil)
 	responseStub = newResponseWriter()
-	controller.deleteCard(responseStub, request, buildRouteParams("asdf"))
+	deleteCard(responseStub, request, buildRouteParams("asdf"))
 	assert.Equal(suite.T(), 400, responseStub.status)
 
 	// Authorized, Database error
 	request = buildHTTPRequest(suite.authHeader, nil)
 	responseStub = newResponseWriter()
-	controller.deleteCard(responseStub, request, buildRouteParams("101"))
+	deleteCard(responseStub, request, buildRouteParams("101"))
 	assert.Equal(suite.T(), 500, responseStub.status)
 
 	// Authorized, Database error
 	request = buildHTTPRequest(suite.authHeader, nil)
 	responseStub = newResponseWriter()
-	controller.deleteCard(responseStub, request, buildRouteParams("101"))
+	deleteCard(responseStub, request, buildRouteParams("101"))
 	assert.Equal(suite.T(), 500, responseStub.status)
 
 	// Authorized, Card not found
 	request = buildHTTPRequest(suite.authHeader, nil)
 	responseStub = newResponseWriter()
-	controller.deleteCard(responseStub, request, buildRouteParams("101"))
+	deleteCard(responseStub, request, buildRouteParams("101"))
+	assert.Equal(suite.T(), 404, responseStub.status)
+
+	// Authorized, Card deleted while deleting
+	request = buildHTTPRequest(suite.authHeader, nil)
+	responseStub = newResponseWriter()
+	deleteCard(responseStub, request, buildRouteParams("101"))
 	assert.Equal(suite.T(), 404, responseStub.status)
 
 	// Successful delete
 	request = buildHTTPRequest(suite.authHeader, nil)
 	responseStub = newResponseWriter()
-	controller.deleteCard(responseStub, request, buildRouteParams("101"))
+	deleteCard(responseStub, request, buildRouteParams("101"))
 	assert.Equal(suite.T(), 200, responseStub.status)
 	var result map[string]bool
 	err := json.Unmarshal(responseStub.body, &result)
@@ -662,12 +1322,457 @@ func (suite *CardControllerTestSuite) TestDeleteCard() {
 	assert.True(suite.T(), result["success"])
 }
 
+func (suite *CardControllerTestSuite) TestConditionalRequests() {
+	mockCtrl := gomock.NewController(suite.T())
+	defer mockCtrl.Finish()
+
+	authenticator := mocks.NewMockTokenAuthenticator(mockCtrl)
+	authenticator.EXPECT().Authenticate(AUTH_TOKEN).Return(suite.principal).AnyTimes()
+	cardAdapter := mocks.NewMockDatabaseCardAdapter(mockCtrl)
+	expectTransactions(cardAdapter)
+	versionedCard := func() *model.Card {
+		card := *suite.seedModels[0]
+		card.Version = 3
+		return &card
+	}
+	cardAdapter.EXPECT().GetCard(OWNER_ID, 101).DoAndReturn(func(int, int) (*model.Card, error) {
+		return versionedCard(), nil
+	}).AnyTimes()
+	gomock.InOrder(
+		cardAdapter.EXPECT().EditCard(OWNER_ID, gomock.Any()).Return(&database.VersionConflictError{}),
+		cardAdapter.EXPECT().EditCard(OWNER_ID, gomock.Any()).DoAndReturn(func(ownerId int, card *model.Card, fields ...string) error {
+			assert.Equal(suite.T(), 3, card.Version)
+			card.Version = 4
+			return nil
+		}),
+	)
+	cardAdapter.EXPECT().DeleteCard(OWNER_ID, 101, 3).Return(nil)
+	controller := &cardController{
+		db: cardAdapter,
+		baseController: baseController{
+			authenticator: authenticator,
+		},
+	}
+	getCard := controller.authenticateRequest(auth.ScopeRead, controller.getCard)
+	editCard := controller.authenticateRequest(auth.ScopeWrite, controller.editCard)
+	patchCard := controller.authenticateRequest(auth.ScopeWrite, controller.patchCard)
+	deleteCard := controller.authenticateRequest(auth.ScopeWrite, controller.deleteCard)
+	conditionalHeader := func(name string, etag string) map[string][]string {
+		return map[string][]string{
+			"Authorization": suite.authHeader["Authorization"],
+			name:            {etag},
+		}
+	}
+	editedCard := *suite.seedModels[0]
+
+	// Case: Reads carry the ETag
+	responseStub := newResponseWriter()
+	getCard(responseStub, buildHTTPRequest(suite.authHeader, nil), buildRouteParams("101"))
+	assert.Equal(suite.T(), 200, responseStub.status)
+	assert.Equal(suite.T(), `"101-3"`, http.Header(responseStub.headers).Get("ETag"))
+
+	// Case: If-None-Match with the current ETag
+	responseStub = newResponseWriter()
+	getCard(responseStub, buildHTTPRequest(conditionalHeader("If-None-Match", `"101-3"`), nil), buildRouteParams("101"))
+	assert.Equal(suite.T(), 304, responseStub.status)
+
+	// Case: If-None-Match with an outdated ETag
+	responseStub = newResponseWriter()
+	getCard(responseStub, buildHTTPRequest(conditionalHeader("If-None-Match", `"101-2"`), nil), buildRouteParams("101"))
+	assert.Equal(suite.T(), 200, responseStub.status)
+
+	// Case: Outdated If-Match is rejected before writing
+	staleHeader := conditionalHeader("If-Match", `"101-2"`)
+	responseStub = newResponseWriter()
+	editCard(responseStub, buildHTTPRequest(staleHeader, &editedCard), buildRouteParams("101"))
+	assert.Equal(suite.T(), 412, responseStub.status)
+	responseStub = newResponseWriter()
+	patchCard(responseStub, buildHTTPRequest(staleHeader, map[string]string{"pokemon": "Mew"}), buildRouteParams("101"))
+	assert.Equal(suite.T(), 412, responseStub.status)
+	responseStub = newResponseWriter()
+	deleteCard(responseStub, buildHTTPRequest(staleHeader, nil), buildRouteParams("101"))
+	assert.Equal(suite.T(), 412, responseStub.status)
+
+	// Case: Card changed between the check and the write
+	currentHeader := conditionalHeader("If-Match", `"101-3"`)
+	responseStub = newResponseWriter()
+	editCard(responseStub, buildHTTPRequest(currentHeader, &editedCard), buildRouteParams("101"))
+	assert.Equal(suite.T(), 412, responseStub.status)
+
+	// Case: Matching If-Match
+	responseStub = newResponseWriter()
+	editCard(responseStub, buildHTTPRequest(currentHeader, &editedCard), buildRouteParams("101"))
+	assert.Equal(suite.T(), 200, responseStub.status)
+	assert.Equal(suite.T(), `"101-4"`, http.Header(responseStub.headers).Get("ETag"))
+
+	responseStub = newResponseWriter()
+	deleteCard(responseStub, buildHTTPRequest(currentHeader, nil), buildRouteParams("101"))
+	assert.Equal(suite.T(), 200, responseStub.status)
+}
+
+func (suite *CardControllerTestSuite) TestGetCardPrice() {
+	mockCtrl := gomock.NewController(suite.T())
+	defer mockCtrl.Finish()
+
+	authenticator := mocks.NewMockTokenAuthenticator(mockCtrl)
+	authenticator.EXPECT().Authenticate(AUTH_TOKEN).Return(suite.principal).AnyTimes()
+	cardAdapter := mocks.NewMockDatabaseCardAdapter(mockCtrl)
+	cardAdapter.EXPECT().GetCard(OWNER_ID, 999).Return(nil, nil)
+	cardAdapter.EXPECT().GetCard(OWNER_ID, 101).Return(suite.seedModels[0], nil).Times(2)
+	cardAdapter.EXPECT().GetCard(OWNER_ID, 102).Return(suite.seedModels[1], nil)
+
+	priceProvider := pricing.NewFakePriceProvider()
+	priceProvider.SetPrices("CARD-101", &model.CardPrices{
+		UpdatedAt: "2022/10/01",
+		Prices: map[string]*model.PriceRange{
+			model.PriceVariantNormal:   {MarketPrice: 1.5},
+			model.PriceVariantHolofoil: {MarketPrice: 6},
+		},
+	})
+	controller := &cardController{
+		db:     cardAdapter,
+		prices: priceProvider,
+		baseController: baseController{
+			authenticator: authenticator,
+		},
+	}
+	httpServer := server.CreateHTTPServer(0)
+	controller.Attach(httpServer)
+	getPrice := func(route string) *StubResponseWriter {
+		responseStub := newResponseWriter()
+		httpServer.GetRouter().ServeHTTP(responseStub, buildRoutedHTTPRequest(http.MethodGet, route, suite.authHeader))
+		return responseStub
+	}
+
+	// Case: Invalid card ID, or card not found
+	assert.Equal(suite.T(), 400, getPrice("/api/card/abc/price").status)
+	assert.Equal(suite.T(), 404, getPrice("/api/card/999/price").status)
+
+	// Case: Priced card, with the variant matching its finish
+	responseStub := getPrice("/api/card/101/price")
+	assert.Equal(suite.T(), 200, responseStub.status)
+	var result cardPriceResponse
+	assert.Nil(suite.T(), json.Unmarshal(responseStub.body, &result))
+	assert.Equal(suite.T(), 101, result.CardId)
+	assert.Equal(suite.T(), "2022/10/01", result.UpdatedAt)
+	assert.Equal(suite.T(), model.PriceVariantNormal, *result.Variant)
+	assert.Len(suite.T(), result.Prices, 2)
+
+	// Case: Card without prices
+	responseStub = getPrice("/api/card/102/price")
+	assert.Equal(suite.T(), 200, responseStub.status)
+	assert.JSONEq(suite.T(), `{"cardId": 102, "uniqueId": "CARD-102", "variant": null, "updatedAt": "", "prices": {}}`, string(responseStub.body))
+
+	// Case: Provider failure
+	priceProvider.SetError(errors.New("Test error"))
+	assert.Equal(suite.T(), 502, getPrice("/api/card/101/price").status)
+
+	// Case: No provider configured
+	controller.prices = nil
+	assert.Equal(suite.T(), 503, getPrice("/api/card/101/price").status)
+}
+
+func (suite *CardControllerTestSuite) TestGetCardPriceHistory() {
+	mockCtrl := gomock.NewController(suite.T())
+	defer mockCtrl.Finish()
+
+	authenticator := mocks.NewMockTokenAuthenticator(mockCtrl)
+	authenticator.EXPECT().Authenticate(AUTH_TOKEN).Return(suite.principal).AnyTimes()
+	cardAdapter := mocks.NewMockDatabaseCardAdapter(mockCtrl)
+	cardAdapter.EXPECT().GetCard(OWNER_ID, 999).Return(nil, nil)
+	cardAdapter.EXPECT().GetCard(OWNER_ID, 101).Return(suite.seedModels[0], nil).Times(2)
+
+	from := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2022, 10, 3, 0, 0, 0, 0, time.UTC)
+	historyAdapter := mocks.NewMockDatabasePriceHistoryAdapter(mockCtrl)
+	gomock.InOrder(
+		historyAdapter.EXPECT().GetPriceStats("CARD-101", from, to).Return([]*model.PriceStats{
+			{Variant: model.PriceVariantHolofoil, Samples: 2, MinPrice: 4, MaxPrice: 6, AvgPrice: 5},
+			{Variant: model.PriceVariantNormal, Samples: 1, MinPrice: 1, MaxPrice: 1, AvgPrice: 1},
+		}, nil),
+		historyAdapter.EXPECT().GetPriceStats("CARD-101", gomock.Any(), gomock.Any()).Return(nil, errors.New("Test error")),
+	)
+	historyAdapter.EXPECT().GetPriceHistory("CARD-101", from, to).Return([]*model.PricePoint{
+		{Variant: model.PriceVariantHolofoil, RecordedAt: from, MarketPrice: 4},
+		{Variant: model.PriceVariantHolofoil, RecordedAt: from.Add(24 * time.Hour), MarketPrice: 6},
+		{Variant: model.PriceVariantNormal, RecordedAt: from, MarketPrice: 1},
+	}, nil)
+
+	controller := &cardController{
+		db:      cardAdapter,
+		history: historyAdapter,
+		baseController: baseController{
+			authenticator: authenticator,
+		},
+	}
+	httpServer := server.CreateHTTPServer(0)
+	controller.Attach(httpServer)
+	getPrices := func(route string) *StubResponseWriter {
+		responseStub := newResponseWriter()
+		httpServer.GetRouter().ServeHTTP(responseStub, buildRoutedHTTPRequest(http.MethodGet, route, suite.authHeader))
+		return responseStub
+	}
+
+	// Case: Invalid ranges
+	for _, route := range []string{
+		"/api/card/101/prices?from=yesterday",
+		"/api/card/101/prices?to=2022-13-01",
+		"/api/card/101/prices?from=2022-10-03&to=2022-10-01",
+		"/api/card/101/prices?from=2020-01-01&to=2022-10-01",
+		"/api/card/abc/prices",
+	} {
+		assert.Equal(suite.T(), 400, getPrices(route).status, route)
+	}
+
+	// Case: Card not found
+	assert.Equal(suite.T(), 404, getPrices("/api/card/999/prices").status)
+
+	// Case: Prices grouped by variant, with the to date included whole
+	responseStub := getPrices("/api/card/101/prices?from=2022-10-01T00:00:00Z&to=2022-10-02")
+	assert.Equal(suite.T(), 200, responseStub.status)
+	var result struct {
+		CardId   int       `json:"cardId"`
+		From     time.Time `json:"from"`
+		To       time.Time `json:"to"`
+		Variants []struct {
+			model.PriceStats
+			Points []*model.PricePoint `json:"points"`
+		} `json:"variants"`
+	}
+	assert.Nil(suite.T(), json.Unmarshal(responseStub.body, &result))
+	assert.Equal(suite.T(), 101, result.CardId)
+	assert.True(suite.T(), from.Equal(result.From))
+	assert.True(suite.T(), to.Equal(result.To))
+	assert.Len(suite.T(), result.Variants, 2)
+	assert.Equal(suite.T(), model.PriceVariantHolofoil, result.Variants[0].Variant)
+	assert.Equal(suite.T(), 5.0, result.Variants[0].AvgPrice)
+	assert.Len(suite.T(), result.Variants[0].Points, 2)
+	assert.Equal(suite.T(), 6.0, result.Variants[0].Points[1].MarketPrice)
+	assert.Len(suite.T(), result.Variants[1].Points, 1)
+
+	// Case: Database error
+	assert.Equal(suite.T(), 500, getPrices("/api/card/101/prices").status)
+}
+
+func (suite *CardControllerTestSuite) TestCreateCardFromCatalogue() {
+	mockCtrl := gomock.NewController(suite.T())
+	defer mockCtrl.Finish()
+
+	authenticator := mocks.NewMockTokenAuthenticator(mockCtrl)
+	authenticator.EXPECT().Authenticate(AUTH_TOKEN).Return(suite.principal).AnyTimes()
+	cardAdapter := mocks.NewMockDatabaseCardAdapter(mockCtrl)
+	cardAdapter.EXPECT().CreateCard(OWNER_ID, gomock.Any()).DoAndReturn(
+		func(ownerId int, card *model.Card) (*model.Card, error) {
+			return card, nil
+		}).Times(3)
+
+	catalogueProvider := catalogue.NewFakeCatalogueProvider()
+	catalogueProvider.SetCard(&model.CatalogueCard{
+		UniqueId: "xy1-1",
+		Name:     "Venusaur-EX",
+		ImageUrl: VALID_URL,
+		SetName:  "XY",
+		Rarity:   "Rare Holo EX",
+		Types:    []string{"Grass"},
+		Number:   "1",
+	})
+	controller := &cardController{
+		db:        cardAdapter,
+		catalogue: catalogueProvider,
+		baseController: baseController{
+			authenticator: authenticator,
+		},
+	}
+	createCard := controller.authenticateRequest(auth.ScopeWrite, controller.createCard)
+	create := func(card *model.Card) *StubResponseWriter {
+		responseStub := newResponseWriter()
+		createCard(responseStub, buildHTTPRequest(suite.authHeader, card), EMPTY_PARAMS)
+		return responseStub
+	}
+
+	// Case: Only the unique ID given
+	responseStub := create(&model.Card{UniqueId: "xy1-1"})
+	assert.Equal(suite.T(), 200, responseStub.status)
+	var result model.Card
+	assert.Nil(suite.T(), json.Unmarshal(responseStub.body, &result))
+	assert.Equal(suite.T(), "Venusaur-EX", result.Pokemon)
+	assert.Equal(suite.T(), VALID_URL, result.ImageUrl)
+	assert.Equal(suite.T(), "XY", result.SetName)
+	assert.Equal(suite.T(), "Rare Holo EX", result.Rarity)
+	assert.Equal(suite.T(), []string{"Grass"}, result.Types)
+	assert.Equal(suite.T(), "1", result.Number)
+	assert.NotNil(suite.T(), result.SyncedAt)
+
+	// Case: A given name is kept
+	responseStub = create(&model.Card{UniqueId: "xy1-1", Pokemon: "My Venusaur"})
+	assert.Equal(suite.T(), 200, responseStub.status)
+	assert.Nil(suite.T(), json.Unmarshal(responseStub.body, &result))
+	assert.Equal(suite.T(), "My Venusaur", result.Pokemon)
+	assert.Equal(suite.T(), "XY", result.SetName)
+
+	// Case: Card missing from the catalogue
+	responseStub = create(&model.Card{UniqueId: "xy1-999"})
+	assert.Equal(suite.T(), 400, responseStub.status)
+	assert.Contains(suite.T(), string(responseStub.body), "not found in the catalogue")
+
+	// Case: Catalogue failure, with and without the details to fall back on
+	catalogueProvider.SetError(errors.New("Test error"))
+	assert.Equal(suite.T(), 502, create(&model.Card{UniqueId: "xy1-1"}).status)
+	responseStub = create(&model.Card{UniqueId: "xy1-1", Pokemon: "AAA", ImageUrl: VALID_URL})
+	assert.Equal(suite.T(), 200, responseStub.status)
+	assert.Nil(suite.T(), json.Unmarshal(responseStub.body, &result))
+	assert.Nil(suite.T(), result.SyncedAt)
+}
+
+func (suite *CardControllerTestSuite) TestSyncCard() {
+	mockCtrl := gomock.NewController(suite.T())
+	defer mockCtrl.Finish()
+
+	authenticator := mocks.NewMockTokenAuthenticator(mockCtrl)
+	authenticator.EXPECT().Authenticate(AUTH_TOKEN).Return(suite.principal).AnyTimes()
+	cardAdapter := mocks.NewMockDatabaseCardAdapter(mockCtrl)
+	cardAdapter.EXPECT().GetCard(OWNER_ID, 999).Return(nil, nil)
+	cardAdapter.EXPECT().GetCard(OWNER_ID, 101).Return(suite.seedModels[0], nil).Times(3)
+	cardAdapter.EXPECT().GetCard(OWNER_ID, 102).Return(suite.seedModels[1], nil)
+	gomock.InOrder(
+		cardAdapter.EXPECT().EditCard(OWNER_ID, gomock.Any(), model.CardCatalogueFields).DoAndReturn(
+			func(ownerId int, card *model.Card, fields ...string) error {
+				card.Version++
+				return nil
+			}),
+		cardAdapter.EXPECT().EditCard(OWNER_ID, gomock.Any(), model.CardCatalogueFields).Return(&database.VersionConflictError{}),
+	)
+
+	catalogueProvider := catalogue.NewFakeCatalogueProvider()
+	catalogueProvider.SetCard(&model.CatalogueCard{
+		UniqueId: "CARD-101",
+		Name:     "Venusaur-EX",
+		ImageUrl: VALID_URL,
+		SetName:  "XY",
+		Types:    []string{"Grass"},
+		Number:   "1",
+	})
+	controller := &cardController{
+		db:        cardAdapter,
+		catalogue: catalogueProvider,
+		baseController: baseController{
+			authenticator: authenticator,
+		},
+	}
+	httpServer := server.CreateHTTPServer(0)
+	controller.Attach(httpServer)
+	syncCard := func(route string) *StubResponseWriter {
+		responseStub := newResponseWriter()
+		httpServer.GetRouter().ServeHTTP(responseStub, buildRoutedHTTPRequest(http.MethodPost, route, suite.authHeader))
+		return responseStub
+	}
+
+	// Case: Invalid card ID, or card not found
+	assert.Equal(suite.T(), 400, syncCard("/api/card/abc/sync").status)
+	assert.Equal(suite.T(), 404, syncCard("/api/card/999/sync").status)
+
+	// Case: Card missing from the catalogue
+	responseStub := syncCard("/api/card/102/sync")
+	assert.Equal(suite.T(), 404, responseStub.status)
+	assert.Contains(suite.T(), string(responseStub.body), "not found in the catalogue")
+
+	// Case: Synced card, with its name and image replaced
+	responseStub = syncCard("/api/card/101/sync")
+	assert.Equal(suite.T(), 200, responseStub.status)
+	var result model.Card
+	assert.Nil(suite.T(), json.Unmarshal(responseStub.body, &result))
+	assert.Equal(suite.T(), "Venusaur-EX", result.Pokemon)
+	assert.Equal(suite.T(), VALID_URL, result.ImageUrl)
+	assert.Equal(suite.T(), []string{"Grass"}, result.Types)
+	assert.Equal(suite.T(), suite.seedModels[0].Version+1, result.Version)
+	assert.Equal(suite.T(), cardETag(&result), http.Header(responseStub.headers).Get("ETag"))
+	assert.Equal(suite.T(), "AAA", suite.seedModels[0].Pokemon)
+
+	// Case: Card changed during the sync
+	assert.Equal(suite.T(), 412, syncCard("/api/card/101/sync").status)
+
+	// Case: Catalogue failure
+	catalogueProvider.SetError(errors.New("Test error"))
+	assert.Equal(suite.T(), 502, syncCard("/api/card/101/sync").status)
+
+	// Case: No catalogue configured
+	controller.catalogue = nil
+	assert.Equal(suite.T(), 503, syncCard("/api/card/101/sync").status)
+}
+
+func (suite *CardControllerTestSuite) TestAttachScopes() {
+	mockCtrl := gomock.NewController(suite.T())
+	defer mockCtrl.Finish()
+
+	readOnlyPrincipal := &model.Principal{
+		TokenId: OWNER_ID,
+		Scopes:  []string{auth.ScopeRead},
+	}
+	authenticator := mocks.NewMockTokenAuthenticator(mockCtrl)
+	authenticator.EXPECT().Authenticate(AUTH_TOKEN).Return(readOnlyPrincipal).AnyTimes()
+	cardAdapter := mocks.NewMockDatabaseCardAdapter(mockCtrl)
+	expectTransactions(cardAdapter)
+	cardAdapter.EXPECT().ListCards(OWNER_ID, gomock.Any()).Return(&model.CardPage{Items: suite.seedModels}, nil)
+	cardAdapter.EXPECT().GetCard(OWNER_ID, 101).Return(suite.seedModels[0], nil)
+
+	controller := &cardController{
+		db: cardAdapter,
+		baseController: baseController{
+			authenticator: authenticator,
+		},
+	}
+	httpServer := server.CreateHTTPServer(0)
+	controller.Attach(httpServer)
+
+	// Reads are allowed with the read scope
+	for _, route := range []string{"/api/card", "/api/card/101"} {
+		responseStub := newResponseWriter()
+		httpServer.GetRouter().ServeHTTP(responseStub, buildRoutedHTTPRequest(http.MethodGet, route, suite.authHeader))
+		assert.Equal(suite.T(), 200, responseStub.status)
+	}
+
+	// Mutations require the write scope
+	mutations := map[string]string{
+		"/api/card":          http.MethodPost,
+		"/api/card/import":   http.MethodPost,
+		"/api/card/101":      http.MethodPut,
+		"/api/card/101/sync": http.MethodPost,
+	}
+	for route, method := range mutations {
+		responseStub := newResponseWriter()
+		httpServer.GetRouter().ServeHTTP(responseStub, buildRoutedHTTPRequest(method, route, suite.authHeader))
+		assert.Equal(suite.T(), 403, responseStub.status)
+		assert.Contains(suite.T(), string(responseStub.body), "'write' scope")
+	}
+	for _, method := range []string{http.MethodPatch, http.MethodDelete} {
+		responseStub := newResponseWriter()
+		httpServer.GetRouter().ServeHTTP(responseStub, buildRoutedHTTPRequest(method, "/api/card/101", suite.authHeader))
+		assert.Equal(suite.T(), 403, responseStub.status)
+	}
+}
+
 func TestCardControllerTestSuite(t *testing.T) {
 	suite.Run(t, new(CardControllerTestSuite))
 }
 
+// withCardDefaults returns the card as stored when created or replaced without
+// quantities or printing details
+func withCardDefaults(card *model.Card) *model.Card {
+	card.ApplyDefaults()
+	return card
+}
+
+// expectTransactions runs transaction callbacks directly against the mock adapter
+func expectTransactions(cardAdapter *mocks.MockDatabaseCardAdapter) {
+	cardAdapter.EXPECT().RunInTx(gomock.Any()).DoAndReturn(
+		func(fn func(txAdapter database.DatabaseCardAdapter) error) error {
+			return fn(cardAdapter)
+		}).AnyTimes()
+}
+
 func buildHTTPRequest(headers map[string][]string, bodyData interface{}) *http.Request {
 	req := &http.Request{
+		URL:    &url.URL{},
 		Header: headers,
 	}
 
@@ -679,6 +1784,20 @@ func buildHTTPRequest(headers map[string][]string, bodyData interface{}) *http.R
 	return req
 }
 
+func buildRawHTTPRequest(headers map[string][]string, body string) *http.Request {
+	return &http.Request{
+		URL:    &url.URL{},
+		Header: headers,
+		Body:   io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func buildRoutedHTTPRequest(method string, route string, headers map[string][]string) *http.Request {
+	req, _ := http.NewRequest(method, route, nil)
+	req.Header = headers
+	return req
+}
+
 func buildRouteParams(cardId string) httprouter.Params {
 	return httprouter.Params{
 		{
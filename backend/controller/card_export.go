@@ -0,0 +1,148 @@
+package controller
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"backend.cs3219.comp.nus.edu.sg/model"
+)
+
+type cardExportWriter interface {
+	WriteCard(card *model.Card) error
+	Close() error
+}
+
+type cardExportFormat struct {
+	contentType string
+	extension   string
+	newWriter   func(writer io.Writer, totals *model.CardTotals) (cardExportWriter, error)
+}
+
+var cardExportFormats = map[string]*cardExportFormat{
+	"csv": {
+		contentType: "text/csv; charset=utf-8",
+		extension:   "csv",
+		newWriter:   newCsvExportWriter,
+	},
+	"jsonl": {
+		contentType: "application/x-ndjson",
+		extension:   "jsonl",
+		newWriter:   newJsonlExportWriter,
+	},
+	"ptcgo": {
+		contentType: "text/plain; charset=utf-8",
+		extension:   "txt",
+		newWriter:   newPtcgoExportWriter,
+	},
+}
+
+// Spreadsheets run cells starting with any of these as formulas, so the export
+// quotes such cells with a leading apostrophe, which the import strips again
+const csvFormulaPrefixes = "=+-@\t\r"
+
+func escapeCsvCell(text string) string {
+	if text != "" && strings.ContainsRune(csvFormulaPrefixes, rune(text[0])) {
+		return "'" + text
+	}
+	return text
+}
+
+func unescapeCsvCell(text string) string {
+	if len(text) > 1 && text[0] == '\'' && strings.ContainsRune(csvFormulaPrefixes, rune(text[1])) {
+		return text[1:]
+	}
+	return text
+}
+
+// csvExportWriter writes the same columns the CSV import reads
+type csvExportWriter struct {
+	writer *csv.Writer
+}
+
+func newCsvExportWriter(writer io.Writer, totals *model.CardTotals) (cardExportWriter, error) {
+	csvWriter := csv.NewWriter(writer)
+	err := csvWriter.Write(editableCardFields)
+	if err != nil {
+		return nil, err
+	}
+	return &csvExportWriter{
+		writer: csvWriter,
+	}, nil
+}
+
+func (exporter *csvExportWriter) WriteCard(card *model.Card) error {
+	record := make([]string, len(editableCardFields))
+	for i, field := range editableCardFields {
+		record[i] = escapeCsvCell(cardFieldText(card, field))
+	}
+	return exporter.writer.Write(record)
+}
+
+func (exporter *csvExportWriter) Close() error {
+	exporter.writer.Flush()
+	return exporter.writer.Error()
+}
+
+type jsonlExportWriter struct {
+	encoder *json.Encoder
+}
+
+func newJsonlExportWriter(writer io.Writer, totals *model.CardTotals) (cardExportWriter, error) {
+	return &jsonlExportWriter{
+		encoder: json.NewEncoder(writer),
+	}, nil
+}
+
+func (exporter *jsonlExportWriter) WriteCard(card *model.Card) error {
+	return exporter.encoder.Encode(card)
+}
+
+func (exporter *jsonlExportWriter) Close() error {
+	return nil
+}
+
+// ptcgoExportWriter writes a deck list in the text format the Pokemon TCG
+// Online and Live clients import, with the copies wanted of each card:
+//
+//	Pokémon: 4
+//	3 Venusaur-EX XY1 1
+//	1 Weedle XY1 3
+//
+//	Total Cards: 4
+//
+// The set code and number come from the "<set>-<number>" unique ID.
+type ptcgoExportWriter struct {
+	writer io.Writer
+	total  int
+}
+
+var ptcgoLineSanitizer = strings.NewReplacer("\r", " ", "\n", " ")
+
+func newPtcgoExportWriter(writer io.Writer, totals *model.CardTotals) (cardExportWriter, error) {
+	_, err := fmt.Fprintf(writer, "Pokémon: %d\n", totals.WantQuantity)
+	if err != nil {
+		return nil, err
+	}
+	return &ptcgoExportWriter{
+		writer: writer,
+		total:  totals.WantQuantity,
+	}, nil
+}
+
+func (exporter *ptcgoExportWriter) WriteCard(card *model.Card) error {
+	setCode, number, found := strings.Cut(card.UniqueId, "-")
+	line := fmt.Sprintf("%d %s %s", card.WantQuantity, card.Pokemon, strings.ToUpper(setCode))
+	if found {
+		line = fmt.Sprintf("%s %s", line, number)
+	}
+	_, err := fmt.Fprintln(exporter.writer, ptcgoLineSanitizer.Replace(line))
+	return err
+}
+
+func (exporter *ptcgoExportWriter) Close() error {
+	_, err := fmt.Fprintf(exporter.writer, "\nTotal Cards: %d\n", exporter.total)
+	return err
+}
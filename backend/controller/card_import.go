@@ -0,0 +1,115 @@
+package controller
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"backend.cs3219.comp.nus.edu.sg/model"
+)
+
+const (
+	maxImportRows  = 1000
+	maxImportBytes = 5 << 20
+
+	csvContentType = "text/csv"
+)
+
+const (
+	importStatusCreated  = "created"
+	importStatusConflict = "conflict"
+	importStatusInvalid  = "invalid"
+	importStatusError    = "error"
+)
+
+// cardImportRow is one parsed row of an import, or the reason it could not be
+// parsed. Rows fail independently, so one bad row never rejects the whole file.
+type cardImportRow struct {
+	card *model.Card
+	err  error
+}
+
+type cardImportResult struct {
+	Row    int         `json:"row"`
+	Status string      `json:"status"`
+	Card   *model.Card `json:"card,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
+
+type cardImportResponse struct {
+	Created int                 `json:"created"`
+	Failed  int                 `json:"failed"`
+	Results []*cardImportResult `json:"results"`
+}
+
+// readJsonImport parses a JSON array of cards
+func readJsonImport(body io.Reader) ([]*cardImportRow, error) {
+	var rawRows []json.RawMessage
+	err := json.NewDecoder(body).Decode(&rawRows)
+	if err != nil {
+		return nil, errors.New("The import must be a JSON array of cards")
+	}
+
+	rows := make([]*cardImportRow, len(rawRows))
+	for i, rawRow := range rawRows {
+		var card model.Card
+		err = json.Unmarshal(rawRow, &card)
+		if err != nil {
+			rows[i] = &cardImportRow{err: errors.New("The row is not a valid card")}
+			continue
+		}
+		rows[i] = &cardImportRow{card: &card}
+	}
+	return rows, nil
+}
+
+// readCsvImport parses CSV with a header row naming the uniqueId, pokemon and
+// imageUrl columns, and optionally any other card field, in any order
+func readCsvImport(body io.Reader) ([]*cardImportRow, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.New("The CSV must start with a header row")
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, field := range requiredCardFields {
+		if _, ok := columns[strings.ToLower(field)]; !ok {
+			return nil, fmt.Errorf("The CSV is missing the '%s' column", field)
+		}
+	}
+
+	rows := make([]*cardImportRow, 0)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("Line %d of the CSV could not be read", len(rows)+2)
+		}
+
+		card := &model.Card{}
+		var rowErr error
+		for _, field := range editableCardFields {
+			column, ok := columns[strings.ToLower(field)]
+			text := ""
+			if ok && column < len(record) {
+				text = unescapeCsvCell(strings.TrimSpace(record[column]))
+			}
+			// Blank optional columns fall back to the card defaults
+			if text != "" && rowErr == nil {
+				rowErr = setCardFieldText(card, field, text)
+			}
+		}
+		rows = append(rows, &cardImportRow{card: card, err: rowErr})
+	}
+	return rows, nil
+}
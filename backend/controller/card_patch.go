@@ -0,0 +1,245 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"backend.cs3219.comp.nus.edu.sg/model"
+)
+
+const (
+	mergePatchContentType = "application/merge-patch+json"
+	jsonPatchContentType  = "application/json-patch+json"
+)
+
+var editableCardFields = model.CardEditableFields
+
+// readOnlyCardFields are copied from the catalogue and only change on a sync
+var readOnlyCardFields = []string{
+	model.CardFieldSetName,
+	model.CardFieldRarity,
+	model.CardFieldTypes,
+	model.CardFieldNumber,
+	model.CardFieldSyncedAt,
+}
+
+// requiredCardFields have no default, so every card must be given them
+var requiredCardFields = []string{
+	model.CardFieldUniqueId,
+	model.CardFieldPokemon,
+	model.CardFieldImageUrl,
+}
+
+var errCardPatchTestFailed = errors.New("A test operation in the patch did not match the card")
+
+type jsonPatchOperation struct {
+	Op    string           `json:"op"`
+	Path  string           `json:"path"`
+	From  string           `json:"from"`
+	Value *json.RawMessage `json:"value"`
+}
+
+// applyMergePatch applies an RFC 7386 JSON Merge Patch to the card. Every
+// field is required, so a null member (which would remove it) is rejected.
+func applyMergePatch(card *model.Card, data []byte) error {
+	var patch map[string]json.RawMessage
+	err := json.Unmarshal(data, &patch)
+	if err != nil || patch == nil {
+		return errors.New("The merge patch must be a JSON object")
+	}
+
+	for key, rawValue := range patch {
+		if key == "id" {
+			var id int
+			if json.Unmarshal(rawValue, &id) != nil || id != card.Id {
+				return errors.New("The card ID cannot be changed")
+			}
+			continue
+		}
+		if key == "version" || containsString(readOnlyCardFields, key) {
+			// Read-only, so a patch built from a fetched card can carry it along
+			continue
+		}
+
+		field := cardFieldRef(card, key)
+		if field == nil {
+			return fmt.Errorf("Unknown field '%s'", key)
+		}
+		if string(rawValue) == "null" {
+			return fmt.Errorf("The field '%s' cannot be removed", key)
+		}
+		if json.Unmarshal(rawValue, field) != nil {
+			return fmt.Errorf("The field '%s' must be a %s", key, cardFieldKind(field))
+		}
+	}
+	return nil
+}
+
+// applyJsonPatch applies an RFC 6902 JSON Patch to the card. Only operations
+// that leave every field in place are accepted, so remove and move are not.
+func applyJsonPatch(card *model.Card, data []byte) error {
+	var operations []jsonPatchOperation
+	err := json.Unmarshal(data, &operations)
+	if err != nil {
+		return errors.New("The JSON patch must be an array of operations")
+	}
+
+	for _, operation := range operations {
+		key := strings.TrimPrefix(operation.Path, "/")
+		if operation.Op == "test" && key == "id" {
+			var id int
+			if operation.Value == nil || json.Unmarshal(*operation.Value, &id) != nil || id != card.Id {
+				return errCardPatchTestFailed
+			}
+			continue
+		}
+
+		field := cardFieldRef(card, key)
+		if field == nil || !strings.HasPrefix(operation.Path, "/") {
+			return fmt.Errorf("Unsupported path '%s'", operation.Path)
+		}
+
+		switch operation.Op {
+		case "add", "replace", "test":
+			value := reflect.New(reflect.TypeOf(field).Elem())
+			if operation.Value == nil || json.Unmarshal(*operation.Value, value.Interface()) != nil {
+				return fmt.Errorf("The value for '%s' must be a %s", operation.Path, cardFieldKind(field))
+			}
+			if operation.Op == "test" {
+				if reflect.ValueOf(field).Elem().Interface() != value.Elem().Interface() {
+					return errCardPatchTestFailed
+				}
+				continue
+			}
+			reflect.ValueOf(field).Elem().Set(value.Elem())
+		case "copy":
+			source := cardFieldRef(card, strings.TrimPrefix(operation.From, "/"))
+			if source == nil || !strings.HasPrefix(operation.From, "/") {
+				return fmt.Errorf("Unsupported path '%s'", operation.From)
+			}
+			if reflect.TypeOf(source) != reflect.TypeOf(field) {
+				return fmt.Errorf("Cannot copy '%s' to '%s' as they differ in type", operation.From, operation.Path)
+			}
+			reflect.ValueOf(field).Elem().Set(reflect.ValueOf(source).Elem())
+		case "remove", "move":
+			return fmt.Errorf("The field '%s' cannot be removed", key)
+		default:
+			return fmt.Errorf("Unknown patch operation '%s'", operation.Op)
+		}
+	}
+	return nil
+}
+
+// omittedCardFields lists the editable fields that the JSON card leaves out,
+// other than the required ones. A PUT keeps the stored value of each of them.
+func omittedCardFields(data []byte) []string {
+	var cardJson map[string]json.RawMessage
+	if json.Unmarshal(data, &cardJson) != nil {
+		return nil
+	}
+
+	omittedFields := make([]string, 0)
+	for _, field := range editableCardFields {
+		if _, found := cardJson[field]; !found && !containsString(requiredCardFields, field) {
+			omittedFields = append(omittedFields, field)
+		}
+	}
+	return omittedFields
+}
+
+// copyCardFields copies the named editable fields from another card
+func copyCardFields(card *model.Card, from *model.Card, fields []string) {
+	for _, field := range fields {
+		reflect.ValueOf(cardFieldRef(card, field)).Elem().Set(reflect.ValueOf(cardFieldRef(from, field)).Elem())
+	}
+}
+
+// cardFieldRef points at the editable card field with the given JSON name,
+// a *string, *int or *float64, or returns nil if there is no such field
+func cardFieldRef(card *model.Card, field string) interface{} {
+	switch field {
+	case model.CardFieldUniqueId:
+		return &card.UniqueId
+	case model.CardFieldPokemon:
+		return &card.Pokemon
+	case model.CardFieldImageUrl:
+		return &card.ImageUrl
+	case model.CardFieldWantQuantity:
+		return &card.WantQuantity
+	case model.CardFieldHaveQuantity:
+		return &card.HaveQuantity
+	case model.CardFieldCondition:
+		return &card.Condition
+	case model.CardFieldLanguage:
+		return &card.Language
+	case model.CardFieldFinish:
+		return &card.Finish
+	case model.CardFieldPriority:
+		return &card.Priority
+	case model.CardFieldTargetPrice:
+		return &card.TargetPrice
+	}
+	return nil
+}
+
+func cardFieldKind(field interface{}) string {
+	switch field.(type) {
+	case *int, *float64:
+		return "number"
+	}
+	return "string"
+}
+
+// cardFieldText formats a card field as it appears in CSV
+func cardFieldText(card *model.Card, field string) string {
+	switch value := cardFieldRef(card, field).(type) {
+	case *int:
+		return strconv.Itoa(*value)
+	case *float64:
+		// Only prices are fractional, and an unset price is left blank
+		if *value == 0 {
+			return ""
+		}
+		return strconv.FormatFloat(*value, 'f', -1, 64)
+	case *string:
+		return *value
+	}
+	return ""
+}
+
+// setCardFieldText parses a card field from its CSV text
+func setCardFieldText(card *model.Card, field string, text string) error {
+	switch value := cardFieldRef(card, field).(type) {
+	case *int:
+		number, err := strconv.Atoi(text)
+		if err != nil {
+			return fmt.Errorf("The field '%s' must be a number", field)
+		}
+		*value = number
+	case *float64:
+		number, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return fmt.Errorf("The field '%s' must be a number", field)
+		}
+		*value = number
+	case *string:
+		*value = text
+	}
+	return nil
+}
+
+func changedCardFields(original *model.Card, patched *model.Card) []string {
+	changed := make([]string, 0, len(editableCardFields))
+	for _, field := range editableCardFields {
+		originalValue := reflect.ValueOf(cardFieldRef(original, field)).Elem().Interface()
+		patchedValue := reflect.ValueOf(cardFieldRef(patched, field)).Elem().Interface()
+		if originalValue != patchedValue {
+			changed = append(changed, field)
+		}
+	}
+	return changed
+}
@@ -0,0 +1,210 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"backend.cs3219.comp.nus.edu.sg/model"
+	"backend.cs3219.comp.nus.edu.sg/pricing"
+	"github.com/julienschmidt/httprouter"
+)
+
+const (
+	defaultPriceHistoryRange = 30 * 24 * time.Hour
+	maxPriceHistoryRange     = 366 * 24 * time.Hour
+)
+
+// cardPriceResponse carries the current prices of a card, with Variant naming
+// the one matching its finish when that variant is priced
+type cardPriceResponse struct {
+	CardId    int                          `json:"cardId"`
+	UniqueId  string                       `json:"uniqueId"`
+	Variant   *string                      `json:"variant"`
+	UpdatedAt string                       `json:"updatedAt"`
+	Prices    map[string]*model.PriceRange `json:"prices"`
+}
+
+type cardPriceHistoryResponse struct {
+	CardId   int                        `json:"cardId"`
+	UniqueId string                     `json:"uniqueId"`
+	From     time.Time                  `json:"from"`
+	To       time.Time                  `json:"to"`
+	Variants []*cardPriceVariantHistory `json:"variants"`
+}
+
+type cardPriceVariantHistory struct {
+	*model.PriceStats
+	Points []*model.PricePoint `json:"points"`
+}
+
+func (controller *cardController) getCardPrice(
+	resp http.ResponseWriter,
+	req *http.Request,
+	params httprouter.Params,
+) {
+	ownerId := controller.getPrincipal(req).TokenId
+
+	cardIdParam := controller.readIntParam("cardId", params)
+	if cardIdParam == nil {
+		controller.writeBadRequest(resp)
+		return
+	}
+	if controller.prices == nil {
+		controller.writeError(resp, 503, "Price lookups are not configured")
+		return
+	}
+
+	card, err := controller.db.GetCard(ownerId, *cardIdParam)
+	if err != nil {
+		controller.writeDatabaseError(resp, err)
+		return
+	}
+	if card == nil {
+		controller.writeNotFound(resp)
+		return
+	}
+
+	prices, err := controller.prices.GetPrices(card.UniqueId)
+	if err != nil {
+		log.Printf("Failed to get prices for %s: %v", card.UniqueId, err)
+		controller.writeError(resp, 502, "The price provider could not be reached")
+		return
+	}
+
+	// A card without prices is reported with none rather than as an error
+	response := cardPriceResponse{
+		CardId:   card.Id,
+		UniqueId: card.UniqueId,
+		Prices:   make(map[string]*model.PriceRange),
+	}
+	if prices != nil {
+		response.UpdatedAt = prices.UpdatedAt
+		if prices.Prices != nil {
+			response.Prices = prices.Prices
+		}
+		if variant, priceRange := pricing.SelectPrice(card, prices); priceRange != nil {
+			response.Variant = &variant
+		}
+	}
+
+	err = controller.writeJson(resp, &response)
+	if err != nil {
+		log.Println("Failed to write response for getCardPrice")
+	}
+}
+
+func (controller *cardController) getCardPriceHistory(
+	resp http.ResponseWriter,
+	req *http.Request,
+	params httprouter.Params,
+) {
+	ownerId := controller.getPrincipal(req).TokenId
+
+	cardIdParam := controller.readIntParam("cardId", params)
+	if cardIdParam == nil {
+		controller.writeBadRequest(resp)
+		return
+	}
+
+	from, to, err := readPriceHistoryRange(req, time.Now())
+	if err != nil {
+		controller.writeError(resp, 400, err.Error())
+		return
+	}
+
+	card, err := controller.db.GetCard(ownerId, *cardIdParam)
+	if err != nil {
+		controller.writeDatabaseError(resp, err)
+		return
+	}
+	if card == nil {
+		controller.writeNotFound(resp)
+		return
+	}
+
+	stats, err := controller.history.GetPriceStats(card.UniqueId, from, to)
+	if err != nil {
+		controller.writeDatabaseError(resp, err)
+		return
+	}
+	points, err := controller.history.GetPriceHistory(card.UniqueId, from, to)
+	if err != nil {
+		controller.writeDatabaseError(resp, err)
+		return
+	}
+
+	response := cardPriceHistoryResponse{
+		CardId:   card.Id,
+		UniqueId: card.UniqueId,
+		From:     from,
+		To:       to,
+		Variants: make([]*cardPriceVariantHistory, 0, len(stats)),
+	}
+	variants := make(map[string]*cardPriceVariantHistory, len(stats))
+	for _, variantStats := range stats {
+		history := &cardPriceVariantHistory{
+			PriceStats: variantStats,
+			Points:     make([]*model.PricePoint, 0),
+		}
+		variants[variantStats.Variant] = history
+		response.Variants = append(response.Variants, history)
+	}
+	for _, point := range points {
+		if history, ok := variants[point.Variant]; ok {
+			history.Points = append(history.Points, point)
+		}
+	}
+
+	err = controller.writeJson(resp, &response)
+	if err != nil {
+		log.Println("Failed to write response for getCardPriceHistory")
+	}
+}
+
+// readPriceHistoryRange reads the from and to parameters, each either an RFC
+// 3339 time or a date. A date in to includes the whole of that day. The range
+// defaults to the 30 days up to now.
+func readPriceHistoryRange(req *http.Request, now time.Time) (time.Time, time.Time, error) {
+	values := req.URL.Query()
+
+	to := now.UTC()
+	if toParam := values.Get("to"); toParam != "" {
+		parsed, isDate, err := parsePriceHistoryTime(toParam)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("The to time '%s' must be an RFC 3339 time or a date", toParam)
+		}
+		if isDate {
+			parsed = parsed.AddDate(0, 0, 1)
+		}
+		to = parsed
+	}
+
+	from := to.Add(-defaultPriceHistoryRange)
+	if fromParam := values.Get("from"); fromParam != "" {
+		parsed, _, err := parsePriceHistoryTime(fromParam)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("The from time '%s' must be an RFC 3339 time or a date", fromParam)
+		}
+		from = parsed
+	}
+
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, errors.New("The from time must be before the to time")
+	}
+	if to.Sub(from) > maxPriceHistoryRange {
+		return time.Time{}, time.Time{}, errors.New("The range can span at most 366 days")
+	}
+	return from, to, nil
+}
+
+func parsePriceHistoryTime(value string) (time.Time, bool, error) {
+	date, err := time.Parse("2006-01-02", value)
+	if err == nil {
+		return date, true, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	return parsed.UTC(), false, err
+}
@@ -0,0 +1,138 @@
+package controller
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"backend.cs3219.comp.nus.edu.sg/model"
+)
+
+const (
+	defaultCardPageSize = 50
+	maxCardPageSize     = 200
+
+	defaultSearchLimit  = 20
+	maxSearchLimit      = 100
+	maxSearchTextLength = 200
+)
+
+// cardCursorToken is the payload behind the opaque nextCursor string. The sort
+// is carried along so a cursor cannot be replayed against a different ordering.
+type cardCursorToken struct {
+	Sort       string `json:"s"`
+	Descending bool   `json:"d,omitempty"`
+	model.CardCursor
+}
+
+type cardListResponse struct {
+	Items      []*model.Card `json:"items"`
+	NextCursor *string       `json:"nextCursor"`
+	Total      int           `json:"total"`
+}
+
+func (controller *cardController) readCardQuery(req *http.Request) (*model.CardQuery, error) {
+	values := req.URL.Query()
+	query := &model.CardQuery{
+		Limit:   defaultCardPageSize,
+		Sort:    model.CardSortId,
+		Pokemon: strings.TrimSpace(values.Get("pokemon")),
+		Set:     strings.TrimSpace(values.Get("set")),
+	}
+
+	if limitParam := values.Get("limit"); limitParam != "" {
+		limit, err := strconv.Atoi(limitParam)
+		if err != nil || limit < 1 || limit > maxCardPageSize {
+			return nil, fmt.Errorf("The limit must be between 1 and %d", maxCardPageSize)
+		}
+		query.Limit = limit
+	}
+
+	if missingParam := values.Get("missing"); missingParam != "" {
+		missing, err := strconv.ParseBool(missingParam)
+		if err != nil {
+			return nil, errors.New("The missing filter must be true or false")
+		}
+		query.Missing = missing
+	}
+
+	if sortParam := values.Get("sort"); sortParam != "" {
+		query.Descending = strings.HasPrefix(sortParam, "-")
+		query.Sort = strings.TrimPrefix(sortParam, "-")
+		if query.Sort != model.CardSortId && query.Sort != model.CardSortPokemon && query.Sort != model.CardSortUniqueId {
+			return nil, fmt.Errorf("Unknown sort '%s'", sortParam)
+		}
+	}
+
+	if cursorParam := values.Get("cursor"); cursorParam != "" {
+		cursor, err := decodeCardCursor(cursorParam)
+		if err != nil {
+			return nil, errors.New("The cursor is invalid")
+		}
+		if cursor.Sort != query.Sort || cursor.Descending != query.Descending {
+			return nil, errors.New("The cursor does not match the requested sort")
+		}
+		query.After = &cursor.CardCursor
+	}
+
+	return query, nil
+}
+
+func encodeCardCursor(query *model.CardQuery, lastCard *model.Card) string {
+	token := cardCursorToken{
+		Sort:       query.Sort,
+		Descending: query.Descending,
+		CardCursor: model.CardCursor{
+			Id: lastCard.Id,
+		},
+	}
+	switch query.Sort {
+	case model.CardSortPokemon:
+		token.SortValue = lastCard.Pokemon
+	case model.CardSortUniqueId:
+		token.SortValue = lastCard.UniqueId
+	}
+
+	data, _ := json.Marshal(token)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCardCursor(cursor string) (*cardCursorToken, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	var token cardCursorToken
+	err = json.Unmarshal(data, &token)
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (controller *cardController) readSearchQuery(req *http.Request) (string, int, error) {
+	values := req.URL.Query()
+	text := strings.TrimSpace(values.Get("q"))
+	if text == "" {
+		return "", 0, errors.New("A search query is required")
+	}
+	if len(text) > maxSearchTextLength {
+		return "", 0, fmt.Errorf("The search query must be at most %d characters", maxSearchTextLength)
+	}
+
+	limit := defaultSearchLimit
+	if limitParam := values.Get("limit"); limitParam != "" {
+		parsedLimit, err := strconv.Atoi(limitParam)
+		if err != nil || parsedLimit < 1 || parsedLimit > maxSearchLimit {
+			return "", 0, fmt.Errorf("The limit must be between 1 and %d", maxSearchLimit)
+		}
+		limit = parsedLimit
+	}
+
+	return text, limit, nil
+}
@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"backend.cs3219.comp.nus.edu.sg/auth"
+	"backend.cs3219.comp.nus.edu.sg/database"
+	"backend.cs3219.comp.nus.edu.sg/model"
+	"backend.cs3219.comp.nus.edu.sg/server"
+	"github.com/julienschmidt/httprouter"
+)
+
+const (
+	defaultAlertPageSize = 50
+	maxAlertPageSize     = 200
+)
+
+type PriceAlertController interface {
+	Attach(server server.HTTPServer)
+}
+
+type priceAlertController struct {
+	baseController
+	db database.DatabasePriceAlertAdapter
+}
+
+type priceAlertListResponse struct {
+	Items []*model.PriceAlert `json:"items"`
+}
+
+func NewPriceAlertController(db *database.DatabaseConnection, authenticator auth.TokenAuthenticator) PriceAlertController {
+	return &priceAlertController{
+		db: database.NewDatabasePriceAlertAdapter(db),
+		baseController: baseController{
+			authenticator: authenticator,
+		},
+	}
+}
+
+func (controller *priceAlertController) Attach(server server.HTTPServer) {
+	server.Get("/api/alerts", controller.authenticateRequest(auth.ScopeRead, controller.getAlerts))
+}
+
+func (controller *priceAlertController) getAlerts(
+	resp http.ResponseWriter,
+	req *http.Request,
+	params httprouter.Params,
+) {
+	ownerId := controller.getPrincipal(req).TokenId
+
+	values := req.URL.Query()
+	query := &model.PriceAlertQuery{
+		Limit: defaultAlertPageSize,
+	}
+	if limitParam := values.Get("limit"); limitParam != "" {
+		limit, err := strconv.Atoi(limitParam)
+		if err != nil || limit < 1 || limit > maxAlertPageSize {
+			controller.writeError(resp, 400, fmt.Sprintf("The limit must be between 1 and %d", maxAlertPageSize))
+			return
+		}
+		query.Limit = limit
+	}
+	if cardIdParam := values.Get("cardId"); cardIdParam != "" {
+		cardId, err := strconv.Atoi(cardIdParam)
+		if err != nil || cardId < 1 {
+			controller.writeError(resp, 400, "The cardId must be a card ID")
+			return
+		}
+		query.CardId = cardId
+	}
+
+	alerts, err := controller.db.ListPriceAlerts(ownerId, query)
+	if err != nil {
+		controller.writeDatabaseError(resp, err)
+		return
+	}
+
+	response := priceAlertListResponse{
+		Items: alerts,
+	}
+	if response.Items == nil {
+		response.Items = make([]*model.PriceAlert, 0)
+	}
+	err = controller.writeJson(resp, &response)
+	if err != nil {
+		log.Println("Failed to write response for getAlerts")
+	}
+}
@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"backend.cs3219.comp.nus.edu.sg/auth"
+	"backend.cs3219.comp.nus.edu.sg/mocks"
+	"backend.cs3219.comp.nus.edu.sg/model"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetAlerts(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	authHeader := map[string][]string{
+		"Authorization": {fmt.Sprintf("Bearer %s", AUTH_TOKEN)},
+	}
+	authenticator := mocks.NewMockTokenAuthenticator(mockCtrl)
+	authenticator.EXPECT().Authenticate(UNAUTH_TOKEN).Return(nil).AnyTimes()
+	authenticator.EXPECT().Authenticate(AUTH_TOKEN).Return(&model.Principal{
+		TokenId: OWNER_ID,
+		Scopes:  []string{auth.ScopeRead},
+	}).AnyTimes()
+
+	alerts := []*model.PriceAlert{
+		{
+			Id:           2,
+			CardId:       101,
+			Variant:      model.PriceVariantHolofoil,
+			MarketPrice:  8.5,
+			TargetPrice:  10,
+			CreatedAt:    time.Date(2022, 10, 2, 0, 0, 0, 0, time.UTC),
+			CardUniqueId: "xy1-1",
+			CardPokemon:  "Venusaur-EX",
+			CardPriority: model.CardPriorityHigh,
+		},
+	}
+	alertAdapter := mocks.NewMockDatabasePriceAlertAdapter(mockCtrl)
+	gomock.InOrder(
+		alertAdapter.EXPECT().ListPriceAlerts(OWNER_ID, &model.PriceAlertQuery{Limit: defaultAlertPageSize}).Return(alerts, nil),
+		alertAdapter.EXPECT().ListPriceAlerts(OWNER_ID, &model.PriceAlertQuery{CardId: 101, Limit: 10}).Return(nil, nil),
+		alertAdapter.EXPECT().ListPriceAlerts(OWNER_ID, gomock.Any()).Return(nil, errors.New("Test error")),
+	)
+	controller := &priceAlertController{
+		db: alertAdapter,
+		baseController: baseController{
+			authenticator: authenticator,
+		},
+	}
+	getAlerts := controller.authenticateRequest(auth.ScopeRead, controller.getAlerts)
+
+	// Case: Unauthorized
+	responseStub := newResponseWriter()
+	getAlerts(responseStub, buildRoutedHTTPRequest(http.MethodGet, "/api/alerts", map[string][]string{
+		"Authorization": {fmt.Sprintf("Bearer %s", UNAUTH_TOKEN)},
+	}), EMPTY_PARAMS)
+	assert.Equal(t, 401, responseStub.status)
+
+	// Case: Invalid parameters
+	for _, route := range []string{"/api/alerts?limit=0", "/api/alerts?limit=201", "/api/alerts?cardId=abc"} {
+		responseStub = newResponseWriter()
+		getAlerts(responseStub, buildRoutedHTTPRequest(http.MethodGet, route, authHeader), EMPTY_PARAMS)
+		assert.Equal(t, 400, responseStub.status, route)
+	}
+
+	// Case: Every alert of the owner
+	responseStub = newResponseWriter()
+	getAlerts(responseStub, buildRoutedHTTPRequest(http.MethodGet, "/api/alerts", authHeader), EMPTY_PARAMS)
+	assert.Equal(t, 200, responseStub.status)
+	var result priceAlertListResponse
+	assert.Nil(t, json.Unmarshal(responseStub.body, &result))
+	assert.Equal(t, alerts, result.Items)
+
+	// Case: Alerts of one card, with none found
+	responseStub = newResponseWriter()
+	getAlerts(responseStub, buildRoutedHTTPRequest(http.MethodGet, "/api/alerts?cardId=101&limit=10", authHeader), EMPTY_PARAMS)
+	assert.Equal(t, 200, responseStub.status)
+	assert.JSONEq(t, `{"items": []}`, string(responseStub.body))
+
+	// Case: Database error
+	responseStub = newResponseWriter()
+	getAlerts(responseStub, buildRoutedHTTPRequest(http.MethodGet, "/api/alerts", authHeader), EMPTY_PARAMS)
+	assert.Equal(t, 500, responseStub.status)
+}
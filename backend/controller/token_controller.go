@@ -0,0 +1,243 @@
+package controller
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"backend.cs3219.comp.nus.edu.sg/auth"
+	"backend.cs3219.comp.nus.edu.sg/database"
+	"backend.cs3219.comp.nus.edu.sg/server"
+	"github.com/julienschmidt/httprouter"
+)
+
+type TokenController interface {
+	Attach(server server.HTTPServer)
+}
+
+type tokenController struct {
+	baseController
+	db         database.DatabaseApiTokenAdapter
+	adminToken string
+}
+
+type mintedTokenResponse struct {
+	Id        int        `json:"id"`
+	Token     string     `json:"token"`
+	Prefix    string     `json:"prefix"`
+	Scopes    []string   `json:"scopes"`
+	IsEnabled bool       `json:"isEnabled"`
+	CreatedAt time.Time  `json:"createdAt"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+type tokenCreateRequest struct {
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+type tokenStateRequest struct {
+	IsEnabled *bool `json:"isEnabled"`
+}
+
+func NewTokenController(
+	db *database.DatabaseConnection,
+	authenticator auth.TokenAuthenticator,
+	adminToken string,
+) TokenController {
+	return &tokenController{
+		db:         database.NewDatabaseApiTokenAdapter(db),
+		adminToken: adminToken,
+		baseController: baseController{
+			authenticator: authenticator,
+		},
+	}
+}
+
+func (controller *tokenController) Attach(server server.HTTPServer) {
+	server.Get("/api/token", controller.authenticateAdmin(controller.getAllTokens))
+	server.Post("/api/token", controller.authenticateAdmin(controller.createToken))
+	server.Put("/api/token/:tokenId", controller.authenticateAdmin(controller.setTokenState))
+	server.Delete("/api/token/:tokenId", controller.authenticateAdmin(controller.deleteToken))
+}
+
+// authenticateAdmin accepts either the bootstrap ADMIN_TOKEN or any API token holding the admin scope.
+func (controller *tokenController) authenticateAdmin(handler server.HTTPHandler) server.HTTPHandler {
+	scopedHandler := controller.authenticateRequest(auth.ScopeAdmin, handler)
+	return func(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
+		bearerToken := controller.readBearerToken(req)
+		if controller.adminToken != "" && bearerToken != "" &&
+			subtle.ConstantTimeCompare([]byte(bearerToken), []byte(controller.adminToken)) == 1 {
+			handler(resp, req, params)
+			return
+		}
+		scopedHandler(resp, req, params)
+	}
+}
+
+func (controller *tokenController) getAllTokens(
+	resp http.ResponseWriter,
+	req *http.Request,
+	params httprouter.Params,
+) {
+	tokens, err := controller.db.GetAllApiTokens()
+	if err != nil {
+		controller.writeDatabaseError(resp, err)
+		return
+	}
+
+	err = controller.writeJson(resp, tokens)
+	if err != nil {
+		log.Println("Failed to write response for getAllTokens")
+	}
+}
+
+func (controller *tokenController) createToken(
+	resp http.ResponseWriter,
+	req *http.Request,
+	params httprouter.Params,
+) {
+	var tokenData tokenCreateRequest
+	err := controller.readOptionalJson(req, &tokenData)
+	if err != nil {
+		controller.writeBadRequest(resp)
+		return
+	}
+
+	scopes := tokenData.Scopes
+	if len(scopes) == 0 {
+		scopes = auth.DefaultScopes
+	}
+	for _, scope := range scopes {
+		if !auth.IsValidScope(scope) {
+			controller.writeError(resp, 400, fmt.Sprintf("Unknown scope '%s'", scope))
+			return
+		}
+	}
+
+	if tokenData.ExpiresAt != nil && !tokenData.ExpiresAt.After(time.Now()) {
+		controller.writeError(resp, 400, "The expiry time must be in the future")
+		return
+	}
+
+	secret, err := auth.GenerateToken()
+	if err != nil {
+		log.Println(err)
+		controller.writeInternalError(resp)
+		return
+	}
+
+	hashedToken, err := auth.NewHashedApiToken(secret, scopes)
+	if err != nil {
+		log.Println(err)
+		controller.writeInternalError(resp)
+		return
+	}
+
+	hashedToken.ExpiresAt = tokenData.ExpiresAt
+
+	token, err := controller.db.CreateApiToken(hashedToken)
+	if err != nil {
+		controller.writeDatabaseError(resp, err)
+		return
+	}
+
+	// The secret is only ever returned here; listings never expose it.
+	err = controller.writeJson(resp, &mintedTokenResponse{
+		Id:        token.Id,
+		Token:     secret,
+		Prefix:    token.Prefix,
+		Scopes:    token.Scopes,
+		IsEnabled: token.IsEnabled,
+		CreatedAt: token.CreatedAt,
+		ExpiresAt: token.ExpiresAt,
+	})
+	if err != nil {
+		log.Println("Failed to write response for createToken")
+	}
+}
+
+func (controller *tokenController) setTokenState(
+	resp http.ResponseWriter,
+	req *http.Request,
+	params httprouter.Params,
+) {
+	tokenIdParam := controller.readIntParam("tokenId", params)
+	if tokenIdParam == nil {
+		controller.writeBadRequest(resp)
+		return
+	}
+	tokenId := *tokenIdParam
+
+	var stateData tokenStateRequest
+	err := controller.readJson(req, &stateData)
+	if err != nil || stateData.IsEnabled == nil {
+		controller.writeBadRequest(resp)
+		return
+	}
+
+	token, err := controller.db.GetApiToken(tokenId)
+	if err != nil {
+		controller.writeDatabaseError(resp, err)
+		return
+	}
+	if token == nil {
+		controller.writeNotFound(resp)
+		return
+	}
+
+	err = controller.db.SetApiTokenState(tokenId, *stateData.IsEnabled)
+	if err != nil {
+		controller.writeDatabaseError(resp, err)
+		return
+	}
+	controller.authenticator.InvalidateToken(tokenId)
+
+	token.IsEnabled = *stateData.IsEnabled
+	err = controller.writeJson(resp, token)
+	if err != nil {
+		log.Println("Failed to write response for setTokenState")
+	}
+}
+
+func (controller *tokenController) deleteToken(
+	resp http.ResponseWriter,
+	req *http.Request,
+	params httprouter.Params,
+) {
+	tokenIdParam := controller.readIntParam("tokenId", params)
+	if tokenIdParam == nil {
+		controller.writeBadRequest(resp)
+		return
+	}
+	tokenId := *tokenIdParam
+
+	token, err := controller.db.GetApiToken(tokenId)
+	if err != nil {
+		controller.writeDatabaseError(resp, err)
+		return
+	}
+	if token == nil {
+		controller.writeNotFound(resp)
+		return
+	}
+
+	// Revoking a token also removes the wishlist it owns.
+	err = controller.db.DeleteApiToken(tokenId)
+	if err != nil {
+		controller.writeDatabaseError(resp, err)
+		return
+	}
+	controller.authenticator.InvalidateToken(tokenId)
+
+	var response struct {
+		Success bool `json:"success"`
+	}
+	response.Success = true
+	err = controller.writeJson(resp, &response)
+	if err != nil {
+		log.Println("Failed to write response for deleteToken")
+	}
+}
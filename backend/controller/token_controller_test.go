@@ -0,0 +1,389 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"backend.cs3219.comp.nus.edu.sg/auth"
+	"backend.cs3219.comp.nus.edu.sg/mocks"
+	"backend.cs3219.comp.nus.edu.sg/model"
+	"backend.cs3219.comp.nus.edu.sg/util"
+	"github.com/golang/mock/gomock"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type TokenControllerTestSuite struct {
+	suite.Suite
+	seedModels        []*model.ApiToken
+	unauthHeader      map[string][]string
+	userHeader        map[string][]string
+	adminHeader       map[string][]string
+	scopedAdminHeader map[string][]string
+}
+
+const (
+	ADMIN_TOKEN        = "ADMIN"
+	SCOPED_ADMIN_TOKEN = "SCOPED-ADMIN"
+)
+
+func (suite *TokenControllerTestSuite) SetupSuite() {
+	suite.seedModels = []*model.ApiToken{
+		{
+			Id:        1,
+			Prefix:    "AAA",
+			Hash:      "HASH-AAA",
+			IsEnabled: true,
+			CreatedAt: time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			Id:        2,
+			Prefix:    "BBB",
+			Hash:      "HASH-BBB",
+			IsEnabled: false,
+			CreatedAt: time.Date(2022, 10, 2, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	suite.unauthHeader = map[string][]string{
+		"Authorization": {
+			fmt.Sprintf("Bearer %s", UNAUTH_TOKEN),
+		},
+	}
+	suite.userHeader = map[string][]string{
+		"Authorization": {
+			fmt.Sprintf("Bearer %s", AUTH_TOKEN),
+		},
+	}
+	suite.scopedAdminHeader = map[string][]string{
+		"Authorization": {
+			fmt.Sprintf("Bearer %s", SCOPED_ADMIN_TOKEN),
+		},
+	}
+	suite.adminHeader = map[string][]string{
+		"Authorization": {
+			fmt.Sprintf("Bearer %s", ADMIN_TOKEN),
+		},
+	}
+}
+
+func (suite *TokenControllerTestSuite) newController(
+	mockCtrl *gomock.Controller,
+	tokenAdapter *mocks.MockDatabaseApiTokenAdapter,
+) (*tokenController, *mocks.MockTokenAuthenticator) {
+	authenticator := mocks.NewMockTokenAuthenticator(mockCtrl)
+	authenticator.EXPECT().Authenticate(UNAUTH_TOKEN).Return(nil).AnyTimes()
+	authenticator.EXPECT().Authenticate(AUTH_TOKEN).Return(&model.Principal{
+		TokenId: 1,
+		Scopes:  []string{auth.ScopeRead, auth.ScopeWrite},
+	}).AnyTimes()
+	authenticator.EXPECT().Authenticate(SCOPED_ADMIN_TOKEN).Return(&model.Principal{
+		TokenId: 2,
+		Scopes:  []string{auth.ScopeAdmin},
+	}).AnyTimes()
+
+	return &tokenController{
+		db:         tokenAdapter,
+		adminToken: ADMIN_TOKEN,
+		baseController: baseController{
+			authenticator: authenticator,
+		},
+	}, authenticator
+}
+
+func (suite *TokenControllerTestSuite) TestAdminAuthentication() {
+	mockCtrl := gomock.NewController(suite.T())
+	defer mockCtrl.Finish()
+
+	tokenAdapter := mocks.NewMockDatabaseApiTokenAdapter(mockCtrl)
+	tokenAdapter.EXPECT().GetAllApiTokens().Return(suite.seedModels, nil).Times(2)
+	controller, _ := suite.newController(mockCtrl, tokenAdapter)
+	getAllTokens := controller.authenticateAdmin(controller.getAllTokens)
+
+	// Case: Unknown token
+	responseStub := newResponseWriter()
+	getAllTokens(responseStub, buildHTTPRequest(suite.unauthHeader, nil), EMPTY_PARAMS)
+	assert.Equal(suite.T(), 401, responseStub.status)
+
+	// Case: Token without the admin scope
+	responseStub = newResponseWriter()
+	getAllTokens(responseStub, buildHTTPRequest(suite.userHeader, nil), EMPTY_PARAMS)
+	assert.Equal(suite.T(), 403, responseStub.status)
+
+	// Case: Bootstrap admin token
+	responseStub = newResponseWriter()
+	getAllTokens(responseStub, buildHTTPRequest(suite.adminHeader, nil), EMPTY_PARAMS)
+	assert.Equal(suite.T(), 200, responseStub.status)
+
+	// Case: Token with the admin scope
+	responseStub = newResponseWriter()
+	getAllTokens(responseStub, buildHTTPRequest(suite.scopedAdminHeader, nil), EMPTY_PARAMS)
+	assert.Equal(suite.T(), 200, responseStub.status)
+
+	// Case: Bootstrap admin token disabled
+	controller.adminToken = ""
+	responseStub = newResponseWriter()
+	getAllTokens(responseStub, buildHTTPRequest(map[string][]string{
+		"Authorization": {"Bearer "},
+	}, nil), EMPTY_PARAMS)
+	assert.Equal(suite.T(), 401, responseStub.status)
+}
+
+func (suite *TokenControllerTestSuite) TestGetAllTokens() {
+	mockCtrl := gomock.NewController(suite.T())
+	defer mockCtrl.Finish()
+
+	tokenAdapter := mocks.NewMockDatabaseApiTokenAdapter(mockCtrl)
+	gomock.InOrder(
+		tokenAdapter.EXPECT().GetAllApiTokens().Return(nil, errors.New("Test error")),
+		tokenAdapter.EXPECT().GetAllApiTokens().Return(suite.seedModels, nil),
+	)
+	controller, _ := suite.newController(mockCtrl, tokenAdapter)
+	getAllTokens := controller.authenticateAdmin(controller.getAllTokens)
+	request := buildHTTPRequest(suite.adminHeader, nil)
+
+	// Case: DB Error
+	responseStub := newResponseWriter()
+	getAllTokens(responseStub, request, EMPTY_PARAMS)
+	assert.Equal(suite.T(), 500, responseStub.status)
+
+	// Case: Listing does not leak secrets
+	responseStub = newResponseWriter()
+	getAllTokens(responseStub, request, EMPTY_PARAMS)
+	assert.Equal(suite.T(), 200, responseStub.status)
+	assert.NotContains(suite.T(), string(responseStub.body), "HASH-AAA")
+	result := make([]model.ApiToken, 0)
+	err := json.Unmarshal(responseStub.body, &result)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 2, len(result))
+	assert.Equal(suite.T(), 2, result[1].Id)
+	assert.False(suite.T(), result[1].IsEnabled)
+}
+
+func (suite *TokenControllerTestSuite) TestCreateToken() {
+	mockCtrl := gomock.NewController(suite.T())
+	defer mockCtrl.Finish()
+
+	var storedToken *model.ApiToken
+	tokenAdapter := mocks.NewMockDatabaseApiTokenAdapter(mockCtrl)
+	gomock.InOrder(
+		tokenAdapter.EXPECT().CreateApiToken(gomock.Any()).Return(nil, errors.New("Test error")),
+		tokenAdapter.EXPECT().CreateApiToken(gomock.Any()).DoAndReturn(func(token *model.ApiToken) (*model.ApiToken, error) {
+			storedToken = token
+			createdToken := *token
+			createdToken.Id = 3
+			createdToken.CreatedAt = time.Now()
+			return &createdToken, nil
+		}),
+		tokenAdapter.EXPECT().CreateApiToken(gomock.Any()).DoAndReturn(func(token *model.ApiToken) (*model.ApiToken, error) {
+			createdToken := *token
+			createdToken.Id = 4
+			return &createdToken, nil
+		}),
+		tokenAdapter.EXPECT().CreateApiToken(gomock.Any()).DoAndReturn(func(token *model.ApiToken) (*model.ApiToken, error) {
+			storedToken = token
+			createdToken := *token
+			createdToken.Id = 5
+			return &createdToken, nil
+		}),
+	)
+	controller, _ := suite.newController(mockCtrl, tokenAdapter)
+	createToken := controller.authenticateAdmin(controller.createToken)
+	request := buildHTTPRequest(suite.adminHeader, nil)
+
+	// Case: DB Error
+	responseStub := newResponseWriter()
+	createToken(responseStub, request, EMPTY_PARAMS)
+	assert.Equal(suite.T(), 500, responseStub.status)
+
+	// Case: Secret is returned once
+	responseStub = newResponseWriter()
+	createToken(responseStub, request, EMPTY_PARAMS)
+	assert.Equal(suite.T(), 200, responseStub.status)
+	var result mintedTokenResponse
+	err := json.Unmarshal(responseStub.body, &result)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 3, result.Id)
+	assert.NotEmpty(suite.T(), result.Token)
+	assert.True(suite.T(), result.IsEnabled)
+
+	// Only the hash of the secret is stored
+	assert.Equal(suite.T(), util.GetTokenPrefix(result.Token), storedToken.Prefix)
+	assert.Equal(suite.T(), result.Token[:util.TokenPrefixLength], result.Prefix)
+	assert.Equal(suite.T(), util.HashToken(storedToken.Salt, result.Token), storedToken.Hash)
+	assert.NotContains(suite.T(), storedToken.Hash, result.Token)
+	assert.Equal(suite.T(), auth.DefaultScopes, result.Scopes)
+
+	// Case: Unknown scope
+	responseStub = newResponseWriter()
+	createToken(responseStub, buildHTTPRequest(suite.adminHeader, map[string][]string{
+		"scopes": {"read", "superuser"},
+	}), EMPTY_PARAMS)
+	assert.Equal(suite.T(), 400, responseStub.status)
+
+	// Case: Read-only token
+	responseStub = newResponseWriter()
+	createToken(responseStub, buildHTTPRequest(suite.adminHeader, map[string][]string{
+		"scopes": {"read"},
+	}), EMPTY_PARAMS)
+	assert.Equal(suite.T(), 200, responseStub.status)
+	err = json.Unmarshal(responseStub.body, &result)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 4, result.Id)
+	assert.Equal(suite.T(), []string{auth.ScopeRead}, result.Scopes)
+
+	// Case: Expiry in the past
+	responseStub = newResponseWriter()
+	createToken(responseStub, buildHTTPRequest(suite.adminHeader, map[string]interface{}{
+		"expiresAt": time.Now().Add(-time.Hour),
+	}), EMPTY_PARAMS)
+	assert.Equal(suite.T(), 400, responseStub.status)
+
+	// Case: Expiring token
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	responseStub = newResponseWriter()
+	createToken(responseStub, buildHTTPRequest(suite.adminHeader, map[string]interface{}{
+		"expiresAt": expiresAt,
+	}), EMPTY_PARAMS)
+	assert.Equal(suite.T(), 200, responseStub.status)
+	assert.True(suite.T(), expiresAt.Equal(*storedToken.ExpiresAt))
+	result = mintedTokenResponse{}
+	err = json.Unmarshal(responseStub.body, &result)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 5, result.Id)
+	assert.True(suite.T(), expiresAt.Equal(*result.ExpiresAt))
+}
+
+func (suite *TokenControllerTestSuite) TestSetTokenState() {
+	mockCtrl := gomock.NewController(suite.T())
+	defer mockCtrl.Finish()
+
+	tokenAdapter := mocks.NewMockDatabaseApiTokenAdapter(mockCtrl)
+	gomock.InOrder(
+		// Token not found
+		tokenAdapter.EXPECT().GetApiToken(gomock.Eq(100)).Return(nil, nil),
+
+		// DB Error 1
+		tokenAdapter.EXPECT().GetApiToken(gomock.Eq(1)).Return(nil, errors.New("Test error")),
+
+		// DB Error 2
+		tokenAdapter.EXPECT().GetApiToken(gomock.Eq(1)).Return(suite.seedModels[0], nil),
+		tokenAdapter.EXPECT().SetApiTokenState(gomock.Eq(1), gomock.Eq(false)).Return(errors.New("Test error")),
+
+		// Successful disable
+		tokenAdapter.EXPECT().GetApiToken(gomock.Eq(1)).Return(suite.seedModels[0], nil),
+		tokenAdapter.EXPECT().SetApiTokenState(gomock.Eq(1), gomock.Eq(false)).Return(nil),
+	)
+	controller, authenticator := suite.newController(mockCtrl, tokenAdapter)
+	authenticator.EXPECT().InvalidateToken(1).Times(1)
+	setTokenState := controller.authenticateAdmin(controller.setTokenState)
+	disableBody := map[string]bool{"isEnabled": false}
+
+	// Case: Unauthorized
+	responseStub := newResponseWriter()
+	setTokenState(responseStub, buildHTTPRequest(suite.unauthHeader, disableBody), buildTokenRouteParams("1"))
+	assert.Equal(suite.T(), 401, responseStub.status)
+
+	// Case: Bad route param
+	responseStub = newResponseWriter()
+	setTokenState(responseStub, buildHTTPRequest(suite.adminHeader, disableBody), buildTokenRouteParams("asdf"))
+	assert.Equal(suite.T(), 400, responseStub.status)
+
+	// Case: Missing state
+	responseStub = newResponseWriter()
+	setTokenState(responseStub, buildHTTPRequest(suite.adminHeader, map[string]bool{}), buildTokenRouteParams("1"))
+	assert.Equal(suite.T(), 400, responseStub.status)
+
+	// Case: Token not found
+	responseStub = newResponseWriter()
+	setTokenState(responseStub, buildHTTPRequest(suite.adminHeader, disableBody), buildTokenRouteParams("100"))
+	assert.Equal(suite.T(), 404, responseStub.status)
+
+	// Case: DB Error 1
+	responseStub = newResponseWriter()
+	setTokenState(responseStub, buildHTTPRequest(suite.adminHeader, disableBody), buildTokenRouteParams("1"))
+	assert.Equal(suite.T(), 500, responseStub.status)
+
+	// Case: DB Error 2
+	responseStub = newResponseWriter()
+	setTokenState(responseStub, buildHTTPRequest(suite.adminHeader, disableBody), buildTokenRouteParams("1"))
+	assert.Equal(suite.T(), 500, responseStub.status)
+
+	// Case: Successful disable
+	responseStub = newResponseWriter()
+	setTokenState(responseStub, buildHTTPRequest(suite.adminHeader, disableBody), buildTokenRouteParams("1"))
+	assert.Equal(suite.T(), 200, responseStub.status)
+	var result model.ApiToken
+	err := json.Unmarshal(responseStub.body, &result)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 1, result.Id)
+	assert.False(suite.T(), result.IsEnabled)
+}
+
+func (suite *TokenControllerTestSuite) TestDeleteToken() {
+	mockCtrl := gomock.NewController(suite.T())
+	defer mockCtrl.Finish()
+
+	tokenAdapter := mocks.NewMockDatabaseApiTokenAdapter(mockCtrl)
+	gomock.InOrder(
+		// Token not found
+		tokenAdapter.EXPECT().GetApiToken(gomock.Eq(100)).Return(nil, nil),
+
+		// DB Error
+		tokenAdapter.EXPECT().GetApiToken(gomock.Eq(1)).Return(suite.seedModels[0], nil),
+		tokenAdapter.EXPECT().DeleteApiToken(gomock.Eq(1)).Return(errors.New("Test error")),
+
+		// Successful revoke
+		tokenAdapter.EXPECT().GetApiToken(gomock.Eq(1)).Return(suite.seedModels[0], nil),
+		tokenAdapter.EXPECT().DeleteApiToken(gomock.Eq(1)).Return(nil),
+	)
+	controller, authenticator := suite.newController(mockCtrl, tokenAdapter)
+	authenticator.EXPECT().InvalidateToken(1).Times(1)
+	deleteToken := controller.authenticateAdmin(controller.deleteToken)
+	request := buildHTTPRequest(suite.adminHeader, nil)
+
+	// Case: Unauthorized
+	responseStub := newResponseWriter()
+	deleteToken(responseStub, buildHTTPRequest(suite.unauthHeader, nil), buildTokenRouteParams("1"))
+	assert.Equal(suite.T(), 401, responseStub.status)
+
+	// Case: Empty route params
+	responseStub = newResponseWriter()
+	deleteToken(responseStub, request, EMPTY_PARAMS)
+	assert.Equal(suite.T(), 400, responseStub.status)
+
+	// Case: Token not found
+	responseStub = newResponseWriter()
+	deleteToken(responseStub, request, buildTokenRouteParams("100"))
+	assert.Equal(suite.T(), 404, responseStub.status)
+
+	// Case: DB Error
+	responseStub = newResponseWriter()
+	deleteToken(responseStub, request, buildTokenRouteParams("1"))
+	assert.Equal(suite.T(), 500, responseStub.status)
+
+	// Case: Successful revoke
+	responseStub = newResponseWriter()
+	deleteToken(responseStub, request, buildTokenRouteParams("1"))
+	assert.Equal(suite.T(), 200, responseStub.status)
+	var result map[string]bool
+	err := json.Unmarshal(responseStub.body, &result)
+	assert.Nil(suite.T(), err)
+	assert.True(suite.T(), result["success"])
+}
+
+func TestTokenControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(TokenControllerTestSuite))
+}
+
+func buildTokenRouteParams(tokenId string) httprouter.Params {
+	return httprouter.Params{
+		{
+			Key:   "tokenId",
+			Value: tokenId,
+		},
+	}
+}
@@ -1,16 +1,23 @@
 package database
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"backend.cs3219.comp.nus.edu.sg/model"
+	"github.com/uptrace/bun/dialect/pgdialect"
 )
 
 //go:generate mockgen -destination=../mocks/mock_database_api_token_adapter.go -build_flags=-mod=mod -package=mocks backend.cs3219.comp.nus.edu.sg/database DatabaseApiTokenAdapter
 type DatabaseApiTokenAdapter interface {
-	CreateApiToken(token string) error
+	CreateApiToken(token *model.ApiToken) (*model.ApiToken, error)
+	GetApiToken(id int) (*model.ApiToken, error)
 	DeleteApiToken(id int) error
 	SetApiTokenState(id int, isActive bool) error
-	IsValidToken(token string) (bool, error)
+	GetEnabledApiTokensByPrefix(prefix string) ([]*model.ApiToken, error)
 	GetAllApiTokens() ([]*model.ApiToken, error)
+	UpdateApiTokensLastUsed(lastUsed map[int]time.Time) error
 }
 
 type databaseApiTokenAdapter struct {
@@ -23,13 +30,28 @@ func NewDatabaseApiTokenAdapter(connector *DatabaseConnection) DatabaseApiTokenA
 	}
 }
 
-func (adapter *databaseApiTokenAdapter) CreateApiToken(token string) error {
-	return adapter.dbAdapter.Execute(
-		"INSERT INTO api_tokens (token, is_enabled, created_at) VALUES(?, true, NOW())",
-		token,
+func (adapter *databaseApiTokenAdapter) CreateApiToken(token *model.ApiToken) (*model.ApiToken, error) {
+	return adapter.dbAdapter.QuerySingle(
+		"INSERT INTO api_tokens (token_prefix, token_salt, token_hash, scopes, is_enabled, created_at, expires_at) VALUES(?, ?, ?, ?, true, NOW(), ?) RETURNING *",
+		token.Prefix,
+		token.Salt,
+		token.Hash,
+		pgdialect.Array(token.Scopes),
+		token.ExpiresAt,
 	)
 }
 
+func (adapter *databaseApiTokenAdapter) GetApiToken(id int) (*model.ApiToken, error) {
+	row, err := adapter.dbAdapter.QuerySingle(
+		"SELECT * FROM api_tokens WHERE token_id=?",
+		id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return row, nil
+}
+
 func (adapter *databaseApiTokenAdapter) SetApiTokenState(id int, isActive bool) error {
 	return adapter.dbAdapter.Execute(
 		"UPDATE api_tokens SET is_enabled=? WHERE token_id=?",
@@ -45,22 +67,43 @@ func (adapter *databaseApiTokenAdapter) DeleteApiToken(id int) error {
 	)
 }
 
-func (adapter *databaseApiTokenAdapter) IsValidToken(token string) (bool, error) {
-	row, err := adapter.dbAdapter.QuerySingle(
-		"SELECT * FROM api_tokens WHERE token=? AND is_enabled = TRUE",
-		token,
+func (adapter *databaseApiTokenAdapter) GetEnabledApiTokensByPrefix(prefix string) ([]*model.ApiToken, error) {
+	results, err := adapter.dbAdapter.QueryMany(
+		"SELECT * FROM api_tokens WHERE token_prefix=? AND is_enabled = TRUE AND (expires_at IS NULL OR expires_at > NOW())",
+		prefix,
 	)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
-
-	return row != nil, nil
+	return results, nil
 }
 
 func (adapter *databaseApiTokenAdapter) GetAllApiTokens() ([]*model.ApiToken, error) {
-	results, err := adapter.dbAdapter.QueryMany("SELECT * FROM api_tokens")
+	results, err := adapter.dbAdapter.QueryMany("SELECT * FROM api_tokens ORDER BY token_id ASC")
 	if err != nil {
 		return nil, err
 	}
 	return results, nil
 }
+
+func (adapter *databaseApiTokenAdapter) UpdateApiTokensLastUsed(lastUsed map[int]time.Time) error {
+	if len(lastUsed) == 0 {
+		return nil
+	}
+
+	valueRows := make([]string, 0, len(lastUsed))
+	args := make([]interface{}, 0, len(lastUsed)*2)
+	for tokenId, usedAt := range lastUsed {
+		valueRows = append(valueRows, "(?::INTEGER, ?::TIMESTAMP)")
+		args = append(args, tokenId, usedAt.UTC())
+	}
+
+	return adapter.dbAdapter.Execute(
+		fmt.Sprintf(
+			"UPDATE api_tokens AS t SET last_used_at = GREATEST(t.last_used_at, v.last_used_at) "+
+				"FROM (VALUES %s) AS v(token_id, last_used_at) WHERE t.token_id = v.token_id",
+			strings.Join(valueRows, ", "),
+		),
+		args...,
+	)
+}
@@ -32,32 +32,20 @@ func (suite *ApiTokenAdapterTestSuite) SetupSuite() {
 	suite.ctx = context.Background()
 	suite.conn = conn
 
+	migrator, err := NewMigrator(conn)
+	assert.Nil(suite.T(), err)
+	_, err = migrator.Up()
+	assert.Nil(suite.T(), err)
+
 	suite.seedModels = []*model.ApiToken{
-		{
-			Id:        1,
-			Token:     "AAA",
-			IsEnabled: true,
-			CreatedAt: time.Now(),
-		},
-		{
-			Id:        2,
-			Token:     "BBB",
-			IsEnabled: true,
-			CreatedAt: time.Now(),
-		},
-		{
-			Id:        3,
-			Token:     "CCC",
-			IsEnabled: false,
-			CreatedAt: time.Now(),
-		},
-		{
-			Id:        4,
-			Token:     "DDD",
-			IsEnabled: true,
-			CreatedAt: time.Now(),
-		},
+		newSeedApiToken(1, "AAA", true),
+		newSeedApiToken(2, "BBB", true),
+		newSeedApiToken(3, "CCC", false),
+		newSeedApiToken(4, "DDD", true),
+		newSeedApiToken(5, "EXPIRED", true),
 	}
+	expiredAt := time.Now().Add(-time.Hour)
+	suite.seedModels[4].ExpiresAt = &expiredAt
 
 	_, _ = conn.Conn.NewTruncateTable().Model(&model.ApiToken{}).Cascade().Exec(suite.ctx)
 	for _, item := range suite.seedModels {
@@ -68,16 +56,31 @@ func (suite *ApiTokenAdapterTestSuite) SetupSuite() {
 
 func (suite *ApiTokenAdapterTestSuite) TestCreateApiToken() {
 	adapter := NewDatabaseApiTokenAdapter(suite.conn)
-	token := "EEE"
-	err := adapter.CreateApiToken(token)
+	token := newSeedApiToken(0, "EEE", true)
+	token.Scopes = []string{"read", "admin"}
+	createdToken, err := adapter.CreateApiToken(token)
 	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), token.Hash, createdToken.Hash)
+	assert.Equal(suite.T(), token.Scopes, createdToken.Scopes)
+	assert.True(suite.T(), createdToken.IsEnabled)
 
 	results := make([]*model.ApiToken, 0)
-	suite.conn.Conn.NewSelect().Model(&model.ApiToken{}).Where("token = ?", token).Scan(suite.ctx, &results)
+	suite.conn.Conn.NewSelect().Model(&model.ApiToken{}).Where("token_prefix = ?", token.Prefix).Scan(suite.ctx, &results)
 
 	assert.Equal(suite.T(), 1, len(results))
 }
 
+func (suite *ApiTokenAdapterTestSuite) TestGetApiToken() {
+	adapter := NewDatabaseApiTokenAdapter(suite.conn)
+	token, err := adapter.GetApiToken(3)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "CCC", token.Prefix)
+
+	token, err = adapter.GetApiToken(100)
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), token)
+}
+
 func (suite *ApiTokenAdapterTestSuite) TestSetApiTokenState() {
 	adapter := NewDatabaseApiTokenAdapter(suite.conn)
 	err := adapter.SetApiTokenState(1, false)
@@ -101,15 +104,47 @@ func (suite *ApiTokenAdapterTestSuite) TestDeleteApiToken() {
 	assert.Equal(suite.T(), 0, len(results))
 }
 
-func (suite *ApiTokenAdapterTestSuite) TestIsValidToken() {
+func (suite *ApiTokenAdapterTestSuite) TestGetEnabledApiTokensByPrefix() {
+	adapter := NewDatabaseApiTokenAdapter(suite.conn)
+	tokens, err := adapter.GetEnabledApiTokensByPrefix("CCC")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 0, len(tokens))
+
+	tokens, err = adapter.GetEnabledApiTokensByPrefix("DDD")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 1, len(tokens))
+	assert.Equal(suite.T(), util.HashToken(tokens[0].Salt, "DDD"), tokens[0].Hash)
+
+	tokens, err = adapter.GetEnabledApiTokensByPrefix("EXPIRED")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 0, len(tokens))
+}
+
+func (suite *ApiTokenAdapterTestSuite) TestUpdateApiTokensLastUsed() {
 	adapter := NewDatabaseApiTokenAdapter(suite.conn)
-	isValid, err := adapter.IsValidToken("CCC")
+	usedAt := time.Now().UTC().Truncate(time.Second)
+	err := adapter.UpdateApiTokensLastUsed(map[int]time.Time{
+		3: usedAt,
+		4: usedAt.Add(-time.Minute),
+	})
 	assert.Nil(suite.T(), err)
-	assert.False(suite.T(), isValid)
 
-	isValid, err = adapter.IsValidToken("DDD")
+	// Older usage never overwrites newer usage
+	err = adapter.UpdateApiTokensLastUsed(map[int]time.Time{
+		3: usedAt.Add(-time.Hour),
+	})
+	assert.Nil(suite.T(), err)
+
+	token, err := adapter.GetApiToken(3)
+	assert.Nil(suite.T(), err)
+	assert.True(suite.T(), usedAt.Equal(*token.LastUsedAt))
+
+	token, err = adapter.GetApiToken(4)
+	assert.Nil(suite.T(), err)
+	assert.True(suite.T(), usedAt.Add(-time.Minute).Equal(*token.LastUsedAt))
+
+	err = adapter.UpdateApiTokensLastUsed(map[int]time.Time{})
 	assert.Nil(suite.T(), err)
-	assert.True(suite.T(), isValid)
 }
 
 func (suite *ApiTokenAdapterTestSuite) TestGetAllApiTokens() {
@@ -122,3 +157,16 @@ func (suite *ApiTokenAdapterTestSuite) TestGetAllApiTokens() {
 func TestApiTokenAdapterTestSuite(t *testing.T) {
 	suite.Run(t, new(ApiTokenAdapterTestSuite))
 }
+
+func newSeedApiToken(id int, secret string, isEnabled bool) *model.ApiToken {
+	salt, _ := util.NewTokenSalt()
+	return &model.ApiToken{
+		Id:        id,
+		Prefix:    util.GetTokenPrefix(secret),
+		Salt:      salt,
+		Hash:      util.HashToken(salt, secret),
+		Scopes:    []string{"read", "write"},
+		IsEnabled: isEnabled,
+		CreatedAt: time.Now(),
+	}
+}
@@ -1,68 +1,293 @@
 package database
 
 import (
-	"log"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
 
 	"backend.cs3219.comp.nus.edu.sg/model"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
 )
 
 //go:generate mockgen -destination=../mocks/mock_database_card_adapter.go -build_flags=-mod=mod -package=mocks backend.cs3219.comp.nus.edu.sg/database DatabaseCardAdapter
 type DatabaseCardAdapter interface {
-	CreateCard(card *model.Card) (*model.Card, error)
-	EditCard(card *model.Card) error
-	DeleteCard(id int) error
-	GetCard(id int) (*model.Card, error)
-	GetCardByUniqueId(uniqueId string) (*model.Card, error)
-	GetAllCards() ([]*model.Card, error)
+	CreateCard(ownerId int, card *model.Card) (*model.Card, error)
+	CreateCardIfAbsent(ownerId int, card *model.Card) (*model.Card, error)
+	EditCard(ownerId int, card *model.Card, fields ...string) error
+	DeleteCard(ownerId int, id int, version int) error
+	GetCard(ownerId int, id int) (*model.Card, error)
+	GetCardByUniqueId(ownerId int, uniqueId string) (*model.Card, error)
+	GetAllCards(ownerId int) ([]*model.Card, error)
+	ListCards(ownerId int, query *model.CardQuery) (*model.CardPage, error)
+	CountCards(ownerId int) (*model.CardTotals, error)
+	StreamCards(ownerId int, handler func(card *model.Card) error) error
+	SearchCards(ownerId int, text string, limit int) ([]*model.CardSearchResult, error)
+	// RunInTx hands fn an adapter whose calls all run in one transaction, which
+	// is rolled back if fn returns an error
+	RunInTx(fn func(txAdapter DatabaseCardAdapter) error) error
+	// RunInSnapshot hands fn an adapter whose reads all see the same snapshot
+	// of the database, in a transaction that cannot write and that fails any
+	// reads made after it has been open for too long
+	RunInSnapshot(fn func(txAdapter DatabaseCardAdapter) error) error
 }
 
 type databaseCardAdapter struct {
-	dbAdapter DatabaseAdapter[model.Card]
+	dbAdapter     DatabaseAdapter[model.Card]
+	countAdapter  DatabaseAdapter[cardCountRow]
+	searchAdapter DatabaseAdapter[model.CardSearchResult]
+}
+
+type cardCountRow struct {
+	Total        int `bun:"total"`
+	WantQuantity int `bun:"want_quantity"`
+}
+
+// Private use code points mark highlighted terms in ts_headline output, as card
+// text may itself contain anything that looks like markup
+const (
+	searchHighlightStart = "\uE000"
+	searchHighlightStop  = "\uE001"
+)
+
+var searchTermPattern = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// Cards from before names and unique IDs were required may have neither, and
+// sort as if they were empty so a cursor taken from such a card still compares
+var cardSortColumns = map[string]string{
+	model.CardSortId:       "card_id",
+	model.CardSortPokemon:  "COALESCE(card_pokemon, '')",
+	model.CardSortUniqueId: "COALESCE(card_unique_id, '')",
 }
 
 func NewDatabaseCardAdapter(connector *DatabaseConnection) DatabaseCardAdapter {
 	return &databaseCardAdapter{
-		dbAdapter: newDatabaseAdapter[model.Card](connector),
+		dbAdapter:     newDatabaseAdapter[model.Card](connector),
+		countAdapter:  newDatabaseAdapter[cardCountRow](connector),
+		searchAdapter: newDatabaseAdapter[model.CardSearchResult](connector),
 	}
 }
 
-func (adapter *databaseCardAdapter) CreateCard(card *model.Card) (*model.Card, error) {
+func (adapter *databaseCardAdapter) RunInTx(fn func(txAdapter DatabaseCardAdapter) error) error {
+	return adapter.dbAdapter.RunInTx(func(tx bun.IDB) error {
+		return fn(&databaseCardAdapter{
+			dbAdapter:     adapter.dbAdapter.WithConn(tx),
+			countAdapter:  adapter.countAdapter.WithConn(tx),
+			searchAdapter: adapter.searchAdapter.WithConn(tx),
+		})
+	})
+}
+
+func (adapter *databaseCardAdapter) RunInSnapshot(fn func(txAdapter DatabaseCardAdapter) error) error {
+	return adapter.dbAdapter.RunInSnapshot(func(tx bun.IDB) error {
+		return fn(&databaseCardAdapter{
+			dbAdapter:     adapter.dbAdapter.WithConn(tx),
+			countAdapter:  adapter.countAdapter.WithConn(tx),
+			searchAdapter: adapter.searchAdapter.WithConn(tx),
+		})
+	})
+}
+
+func (adapter *databaseCardAdapter) CreateCard(ownerId int, card *model.Card) (*model.Card, error) {
+	cardDuplicated := *card
+	cardDuplicated.ApplyDefaults()
 	result, err := adapter.dbAdapter.QuerySingle(
-		"INSERT INTO cards (card_unique_id, card_pokemon, card_image) VALUES(?, ?, ?) RETURNING card_id;",
-		card.UniqueId,
-		card.Pokemon,
-		card.ImageUrl,
+		"INSERT INTO cards (owner_id, card_unique_id, card_pokemon, card_image, card_want_quantity, card_have_quantity, card_condition, card_language, card_finish, card_priority, card_target_price, card_set_name, card_rarity, card_types, card_number, card_synced_at) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING card_id, card_version;",
+		ownerId,
+		cardDuplicated.UniqueId,
+		cardDuplicated.Pokemon,
+		cardDuplicated.ImageUrl,
+		cardDuplicated.WantQuantity,
+		cardDuplicated.HaveQuantity,
+		cardDuplicated.Condition,
+		cardDuplicated.Language,
+		cardDuplicated.Finish,
+		cardDuplicated.Priority,
+		nullablePrice(cardDuplicated.TargetPrice),
+		nullableText(cardDuplicated.SetName),
+		nullableText(cardDuplicated.Rarity),
+		pgdialect.Array(cardDuplicated.Types),
+		nullableText(cardDuplicated.Number),
+		cardDuplicated.SyncedAt,
 	)
 	if err != nil {
-		log.Println(err)
 		return nil, err
 	}
+	cardDuplicated.Id = result.Id
+	cardDuplicated.OwnerId = ownerId
+	cardDuplicated.Version = result.Version
+	return &cardDuplicated, nil
+}
+
+// CreateCardIfAbsent inserts the card unless the owner already has one with the
+// same unique ID, in which case it returns nil without an error.
+func (adapter *databaseCardAdapter) CreateCardIfAbsent(ownerId int, card *model.Card) (*model.Card, error) {
 	cardDuplicated := *card
+	cardDuplicated.ApplyDefaults()
+	result, err := adapter.dbAdapter.QuerySingle(
+		"INSERT INTO cards (owner_id, card_unique_id, card_pokemon, card_image, card_want_quantity, card_have_quantity, card_condition, card_language, card_finish, card_priority, card_target_price, card_set_name, card_rarity, card_types, card_number, card_synced_at) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) ON CONFLICT (owner_id, card_unique_id) DO NOTHING RETURNING card_id, card_version;",
+		ownerId,
+		cardDuplicated.UniqueId,
+		cardDuplicated.Pokemon,
+		cardDuplicated.ImageUrl,
+		cardDuplicated.WantQuantity,
+		cardDuplicated.HaveQuantity,
+		cardDuplicated.Condition,
+		cardDuplicated.Language,
+		cardDuplicated.Finish,
+		cardDuplicated.Priority,
+		nullablePrice(cardDuplicated.TargetPrice),
+		nullableText(cardDuplicated.SetName),
+		nullableText(cardDuplicated.Rarity),
+		pgdialect.Array(cardDuplicated.Types),
+		nullableText(cardDuplicated.Number),
+		cardDuplicated.SyncedAt,
+	)
+	if err != nil || result == nil {
+		return nil, err
+	}
 	cardDuplicated.Id = result.Id
+	cardDuplicated.OwnerId = ownerId
+	cardDuplicated.Version = result.Version
 	return &cardDuplicated, nil
 }
 
-func (adapter *databaseCardAdapter) EditCard(card *model.Card) error {
-	return adapter.dbAdapter.Execute(
-		"UPDATE cards SET card_unique_id=?, card_pokemon=?, card_image=? WHERE card_id=?;",
-		card.UniqueId,
-		card.Pokemon,
-		card.ImageUrl,
-		card.Id,
+// EditCard writes the given fields of the card, or every editable field when
+// none are named, leaving the other columns untouched. A non-zero card.Version
+// makes the write conditional on the stored version still matching it, and
+// card.Version is updated to the new version on success.
+func (adapter *databaseCardAdapter) EditCard(ownerId int, card *model.Card, fields ...string) error {
+	if len(fields) == 0 {
+		fields = model.CardEditableFields
+	}
+
+	assignments := make([]string, 0, len(fields))
+	args := make([]interface{}, 0, len(fields)+2)
+	for _, field := range fields {
+		switch field {
+		case model.CardFieldUniqueId:
+			assignments = append(assignments, "card_unique_id=?")
+			args = append(args, card.UniqueId)
+		case model.CardFieldPokemon:
+			assignments = append(assignments, "card_pokemon=?")
+			args = append(args, card.Pokemon)
+		case model.CardFieldImageUrl:
+			assignments = append(assignments, "card_image=?")
+			args = append(args, card.ImageUrl)
+		case model.CardFieldWantQuantity:
+			assignments = append(assignments, "card_want_quantity=?")
+			args = append(args, card.WantQuantity)
+		case model.CardFieldHaveQuantity:
+			assignments = append(assignments, "card_have_quantity=?")
+			args = append(args, card.HaveQuantity)
+		case model.CardFieldCondition:
+			assignments = append(assignments, "card_condition=?")
+			args = append(args, card.Condition)
+		case model.CardFieldLanguage:
+			assignments = append(assignments, "card_language=?")
+			args = append(args, card.Language)
+		case model.CardFieldFinish:
+			assignments = append(assignments, "card_finish=?")
+			args = append(args, card.Finish)
+		case model.CardFieldPriority:
+			assignments = append(assignments, "card_priority=?")
+			args = append(args, card.Priority)
+		case model.CardFieldTargetPrice:
+			assignments = append(assignments, "card_target_price=?")
+			args = append(args, nullablePrice(card.TargetPrice))
+		case model.CardFieldSetName:
+			assignments = append(assignments, "card_set_name=?")
+			args = append(args, nullableText(card.SetName))
+		case model.CardFieldRarity:
+			assignments = append(assignments, "card_rarity=?")
+			args = append(args, nullableText(card.Rarity))
+		case model.CardFieldTypes:
+			assignments = append(assignments, "card_types=?")
+			args = append(args, pgdialect.Array(card.Types))
+		case model.CardFieldNumber:
+			assignments = append(assignments, "card_number=?")
+			args = append(args, nullableText(card.Number))
+		case model.CardFieldSyncedAt:
+			assignments = append(assignments, "card_synced_at=?")
+			args = append(args, card.SyncedAt)
+		default:
+			return fmt.Errorf("unknown card field %s", field)
+		}
+	}
+	assignments = append(assignments, "card_version=card_version+1")
+	args = append(args, card.Id, ownerId, card.Version, card.Version)
+
+	result, err := adapter.dbAdapter.QuerySingle(
+		fmt.Sprintf(
+			"UPDATE cards SET %s WHERE card_id=? AND owner_id=? AND (?=0 OR card_version=?) RETURNING card_id, card_version;",
+			strings.Join(assignments, ", "),
+		),
+		args...,
 	)
+	if err != nil {
+		return err
+	}
+	if result == nil {
+		return adapter.unmatchedCardError(ownerId, card.Id)
+	}
+	card.Version = result.Version
+	return nil
+}
+
+// unmatchedCardError explains why a write matched no card: either the card
+// does not exist, or it is no longer at the version the write expected
+func (adapter *databaseCardAdapter) unmatchedCardError(ownerId int, id int) error {
+	existing, err := adapter.GetCard(ownerId, id)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return &NotFoundError{}
+	}
+	return &VersionConflictError{}
+}
+
+// nullablePrice stores an unset price of 0 as NULL
+func nullablePrice(price float64) interface{} {
+	if price == 0 {
+		return nil
+	}
+	return price
 }
 
-func (adapter *databaseCardAdapter) DeleteCard(id int) error {
-	return adapter.dbAdapter.Execute(
-		"DELETE FROM cards WHERE card_id=?",
+// nullableText stores blank catalogue details as NULL
+func nullableText(text string) interface{} {
+	if text == "" {
+		return nil
+	}
+	return text
+}
+
+// DeleteCard removes the card, only if it is still at the given version
+// unless the version is zero. A missing card is a NotFoundError.
+func (adapter *databaseCardAdapter) DeleteCard(ownerId int, id int, version int) error {
+	result, err := adapter.dbAdapter.QuerySingle(
+		"DELETE FROM cards WHERE card_id=? AND owner_id=? AND (?=0 OR card_version=?) RETURNING card_id",
 		id,
+		ownerId,
+		version,
+		version,
 	)
+	if err != nil {
+		return err
+	}
+	if result == nil {
+		return adapter.unmatchedCardError(ownerId, id)
+	}
+	return nil
 }
 
-func (adapter *databaseCardAdapter) GetCard(id int) (*model.Card, error) {
+func (adapter *databaseCardAdapter) GetCard(ownerId int, id int) (*model.Card, error) {
 	result, err := adapter.dbAdapter.QuerySingle(
-		"SELECT * FROM cards WHERE card_id=?",
+		"SELECT * FROM cards WHERE card_id=? AND owner_id=?",
 		id,
+		ownerId,
 	)
 	if err != nil {
 		return nil, err
@@ -70,10 +295,11 @@ func (adapter *databaseCardAdapter) GetCard(id int) (*model.Card, error) {
 	return result, nil
 }
 
-func (adapter *databaseCardAdapter) GetCardByUniqueId(uniqueId string) (*model.Card, error) {
+func (adapter *databaseCardAdapter) GetCardByUniqueId(ownerId int, uniqueId string) (*model.Card, error) {
 	result, err := adapter.dbAdapter.QuerySingle(
-		"SELECT * FROM cards WHERE card_unique_id=? ORDER BY card_id ASC",
+		"SELECT * FROM cards WHERE card_unique_id=? AND owner_id=? ORDER BY card_id ASC",
 		uniqueId,
+		ownerId,
 	)
 	if err != nil {
 		return nil, err
@@ -81,10 +307,163 @@ func (adapter *databaseCardAdapter) GetCardByUniqueId(uniqueId string) (*model.C
 	return result, nil
 }
 
-func (adapter *databaseCardAdapter) GetAllCards() ([]*model.Card, error) {
-	results, err := adapter.dbAdapter.QueryMany("SELECT * FROM cards ORDER BY card_id ASC")
+func (adapter *databaseCardAdapter) GetAllCards(ownerId int) ([]*model.Card, error) {
+	results, err := adapter.dbAdapter.QueryMany(
+		"SELECT * FROM cards WHERE owner_id=? ORDER BY card_id ASC",
+		ownerId,
+	)
 	if err != nil {
 		return nil, err
 	}
 	return results, nil
 }
+
+func (adapter *databaseCardAdapter) CountCards(ownerId int) (*model.CardTotals, error) {
+	countRow, err := adapter.countAdapter.QuerySingle(
+		"SELECT COUNT(*) AS total, COALESCE(SUM(card_want_quantity), 0) AS want_quantity FROM cards WHERE owner_id=?",
+		ownerId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &model.CardTotals{
+		Cards:        countRow.Total,
+		WantQuantity: countRow.WantQuantity,
+	}, nil
+}
+
+// StreamCards passes every card of the owner to the handler in ID order,
+// reading them from the database as the handler consumes them.
+func (adapter *databaseCardAdapter) StreamCards(ownerId int, handler func(card *model.Card) error) error {
+	return adapter.dbAdapter.QueryEach(
+		handler,
+		"SELECT * FROM cards WHERE owner_id=? ORDER BY card_id ASC",
+		ownerId,
+	)
+}
+
+func (adapter *databaseCardAdapter) ListCards(ownerId int, query *model.CardQuery) (*model.CardPage, error) {
+	filters := []string{"owner_id=?"}
+	args := []interface{}{ownerId}
+	if query.Pokemon != "" {
+		filters = append(filters, "card_pokemon ILIKE ?")
+		args = append(args, "%"+escapeLikePattern(query.Pokemon)+"%")
+	}
+	if query.Set != "" {
+		filters = append(filters, "card_unique_id LIKE ?")
+		args = append(args, escapeLikePattern(query.Set)+"-%")
+	}
+	if query.Missing {
+		filters = append(filters, "card_have_quantity < card_want_quantity")
+	}
+
+	// The total ignores the cursor, so it stays the same across every page
+	countRow, err := adapter.countAdapter.QuerySingle(
+		"SELECT COUNT(*) AS total FROM cards WHERE "+strings.Join(filters, " AND "),
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	sortColumn, ok := cardSortColumns[query.Sort]
+	if !ok {
+		sortColumn = "card_id"
+	}
+	direction, comparator := "ASC", ">"
+	if query.Descending {
+		direction, comparator = "DESC", "<"
+	}
+
+	// card_id breaks ties between equal sort values so the cursor is always unambiguous
+	orderBy := fmt.Sprintf("card_id %s", direction)
+	if sortColumn != "card_id" {
+		orderBy = fmt.Sprintf("%s %s, %s", sortColumn, direction, orderBy)
+	}
+	if query.After != nil {
+		if sortColumn == "card_id" {
+			filters = append(filters, fmt.Sprintf("card_id %s ?", comparator))
+			args = append(args, query.After.Id)
+		} else {
+			filters = append(filters, fmt.Sprintf("(%s, card_id) %s (?, ?)", sortColumn, comparator))
+			args = append(args, query.After.SortValue, query.After.Id)
+		}
+	}
+
+	// Fetch one extra row to find out whether another page follows
+	args = append(args, query.Limit+1)
+	results, err := adapter.dbAdapter.QueryMany(
+		fmt.Sprintf("SELECT * FROM cards WHERE %s ORDER BY %s LIMIT ?", strings.Join(filters, " AND "), orderBy),
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &model.CardPage{
+		Items: results,
+		Total: countRow.Total,
+	}
+	if len(results) > query.Limit {
+		page.Items = results[:query.Limit]
+		page.HasMore = true
+	}
+	return page, nil
+}
+
+func escapeLikePattern(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+// SearchCards ranks the owner's cards against free text. Words match by prefix
+// through full-text search, while trigram similarity on the name tolerates typos
+// and a substring match on the unique ID catches partial set numbers.
+func (adapter *databaseCardAdapter) SearchCards(ownerId int, text string, limit int) ([]*model.CardSearchResult, error) {
+	results, err := adapter.searchAdapter.QueryMany(
+		`SELECT cards.*,
+			ts_rank(to_tsvector('simple', coalesce(card_pokemon, '') || ' ' || coalesce(card_unique_id, '')), search.query)
+				+ GREATEST(similarity(coalesce(card_pokemon, ''), search.text), similarity(coalesce(card_unique_id, ''), search.text)) AS rank,
+			ts_headline('simple', coalesce(card_pokemon, ''), search.query, search.options) AS pokemon_highlight,
+			ts_headline('simple', coalesce(card_unique_id, ''), search.query, search.options) AS unique_id_highlight
+		FROM cards, (SELECT to_tsquery('simple', ?) AS query, ?::TEXT AS text, ?::TEXT AS options) AS search
+		WHERE owner_id=? AND (
+			to_tsvector('simple', coalesce(card_pokemon, '') || ' ' || coalesce(card_unique_id, '')) @@ search.query
+			OR card_pokemon % search.text
+			OR card_unique_id ILIKE ?
+		)
+		ORDER BY rank DESC, card_id ASC
+		LIMIT ?`,
+		buildPrefixTsQuery(text),
+		text,
+		fmt.Sprintf("StartSel=%s, StopSel=%s, HighlightAll=true", searchHighlightStart, searchHighlightStop),
+		ownerId,
+		"%"+escapeLikePattern(text)+"%",
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, result := range results {
+		result.PokemonHighlight = formatSearchHighlight(result.PokemonHighlight)
+		result.UniqueIdHighlight = formatSearchHighlight(result.UniqueIdHighlight)
+	}
+	return results, nil
+}
+
+// buildPrefixTsQuery turns free text into a tsquery requiring every word as a
+// prefix, e.g. "mega venu" becomes "mega:* & venu:*"
+func buildPrefixTsQuery(text string) string {
+	terms := searchTermPattern.FindAllString(strings.ToLower(text), -1)
+	for i, term := range terms {
+		terms[i] = term + ":*"
+	}
+	return strings.Join(terms, " & ")
+}
+
+func formatSearchHighlight(highlight string) string {
+	return strings.NewReplacer(
+		searchHighlightStart, "<mark>",
+		searchHighlightStop, "</mark>",
+	).Replace(html.EscapeString(highlight))
+}
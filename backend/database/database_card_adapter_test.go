@@ -2,7 +2,9 @@ package database
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"backend.cs3219.comp.nus.edu.sg/model"
 	"backend.cs3219.comp.nus.edu.sg/util"
@@ -15,6 +17,8 @@ type CardAdapterTestSuite struct {
 	conn       *DatabaseConnection
 	ctx        context.Context
 	seedModels []*model.Card
+	owner      *model.ApiToken
+	otherOwner *model.ApiToken
 }
 
 func (suite *CardAdapterTestSuite) SetupSuite() {
@@ -31,26 +35,52 @@ func (suite *CardAdapterTestSuite) SetupSuite() {
 	suite.ctx = context.Background()
 	suite.conn = conn
 
+	migrator, err := NewMigrator(conn)
+	assert.Nil(suite.T(), err)
+	_, err = migrator.Up()
+	assert.Nil(suite.T(), err)
+
+	suite.owner = newSeedApiToken(0, "CARDOWNA", true)
+	suite.otherOwner = newSeedApiToken(0, "CARDOWNB", true)
+	_, _ = conn.Conn.NewDelete().Model(&model.ApiToken{}).Where("token_prefix IN (?, ?)", suite.owner.Prefix, suite.otherOwner.Prefix).Exec(suite.ctx)
+	for _, item := range []*model.ApiToken{suite.owner, suite.otherOwner} {
+		_, err = conn.Conn.NewInsert().Model(item).ExcludeColumn("token_id").Returning("token_id").Exec(suite.ctx)
+		assert.Nil(suite.T(), err)
+	}
+
 	suite.seedModels = []*model.Card{
 		{
 			Id:       1,
+			OwnerId:  suite.owner.Id,
 			UniqueId: "CARD-001",
 			Pokemon:  "AAA",
 			ImageUrl: "imageUrl1",
+			Version:  1,
 		},
 		{
 			Id:       2,
+			OwnerId:  suite.owner.Id,
 			UniqueId: "CARD-002",
 			Pokemon:  "BBB",
 			ImageUrl: "imageUrl2",
+			Version:  1,
 		},
 		{
-			Id:       3,
-			UniqueId: "CARD-003",
-			Pokemon:  "CCC",
-			ImageUrl: "imageUrl3",
+			Id:           3,
+			OwnerId:      suite.owner.Id,
+			UniqueId:     "CARD-003",
+			Pokemon:      "CCC",
+			ImageUrl:     "imageUrl3",
+			WantQuantity: 2,
+			HaveQuantity: 2,
+			Condition:    model.CardConditionLightlyPlayed,
+			Finish:       model.CardFinishReverse,
+			Version:      1,
 		},
 	}
+	for _, card := range suite.seedModels {
+		card.ApplyDefaults()
+	}
 
 	_, _ = conn.Conn.NewTruncateTable().Model(&model.Card{}).Cascade().Exec(suite.ctx)
 	_, err = conn.Conn.NewInsert().Model(suite.seedModels[0]).ExcludeColumn("card_id").Exec(suite.ctx)
@@ -71,7 +101,7 @@ func (suite *CardAdapterTestSuite) TestNewModel() {
 		Pokemon:  "DDD",
 		ImageUrl: "Image4",
 	}
-	createdModel, err := adapter.CreateCard(testModel)
+	createdModel, err := adapter.CreateCard(suite.owner.Id, testModel)
 	assert.Nil(suite.T(), err)
 
 	results := make([]*model.Card, 0)
@@ -80,9 +110,30 @@ func (suite *CardAdapterTestSuite) TestNewModel() {
 	assert.Contains(suite.T(), results, createdModel)
 }
 
+func (suite *CardAdapterTestSuite) TestCreateCardIfAbsent() {
+	adapter := NewDatabaseCardAdapter(suite.conn)
+	createdModel, err := adapter.CreateCardIfAbsent(suite.owner.Id, &model.Card{
+		UniqueId: "CARD-005",
+		Pokemon:  "EEE",
+		ImageUrl: "imageUrl5",
+	})
+	assert.Nil(suite.T(), err)
+	assert.NotNil(suite.T(), createdModel)
+	assert.Equal(suite.T(), 1, createdModel.Version)
+
+	// Case: Unique ID already taken
+	createdModel, err = adapter.CreateCardIfAbsent(suite.owner.Id, &model.Card{
+		UniqueId: "CARD-005",
+		Pokemon:  "Other",
+		ImageUrl: "imageUrl5",
+	})
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), createdModel)
+}
+
 func (suite *CardAdapterTestSuite) TestDeleteModel() {
 	adapter := NewDatabaseCardAdapter(suite.conn)
-	err := adapter.DeleteCard(2)
+	err := adapter.DeleteCard(suite.owner.Id, 2, 0)
 	assert.Nil(suite.T(), err)
 
 	results := make([]*model.Card, 0)
@@ -95,53 +146,411 @@ func (suite *CardAdapterTestSuite) TestDeleteModel() {
 	}
 }
 
+func (suite *CardAdapterTestSuite) TestForeignKeyError() {
+	adapter := NewDatabaseCardAdapter(suite.conn)
+	_, err := adapter.CreateCard(0, &model.Card{
+		UniqueId: "CARD-404",
+		Pokemon:  "XXX",
+		ImageUrl: "imageUrl404",
+	})
+	assert.IsType(suite.T(), &ForeignKeyError{}, err)
+	assert.True(suite.T(), IsConstraintError(err))
+}
+
+func (suite *CardAdapterTestSuite) TestCheckError() {
+	adapter := NewDatabaseCardAdapter(suite.conn)
+	_, err := adapter.CreateCard(suite.owner.Id, &model.Card{
+		UniqueId:     "CARD-400",
+		Pokemon:      "XXX",
+		ImageUrl:     "imageUrl400",
+		HaveQuantity: -1,
+	})
+	assert.IsType(suite.T(), &CheckError{}, err)
+}
+
+func (suite *CardAdapterTestSuite) TestVersionConflict() {
+	adapter := NewDatabaseCardAdapter(suite.conn)
+	staleModel := *suite.seedModels[2]
+	staleModel.Version = 99
+
+	err := adapter.EditCard(suite.owner.Id, &staleModel)
+	assert.IsType(suite.T(), &VersionConflictError{}, err)
+	err = adapter.DeleteCard(suite.owner.Id, staleModel.Id, staleModel.Version)
+	assert.IsType(suite.T(), &VersionConflictError{}, err)
+
+	// Case: A missing card is not found, whatever the version
+	missingModel := *suite.seedModels[2]
+	missingModel.Id = 404
+	err = adapter.EditCard(suite.owner.Id, &missingModel)
+	assert.IsType(suite.T(), &NotFoundError{}, err)
+	missingModel.Version = 0
+	err = adapter.EditCard(suite.owner.Id, &missingModel)
+	assert.IsType(suite.T(), &NotFoundError{}, err)
+	err = adapter.DeleteCard(suite.owner.Id, 404, 1)
+	assert.IsType(suite.T(), &NotFoundError{}, err)
+	err = adapter.DeleteCard(suite.owner.Id, 404, 0)
+	assert.IsType(suite.T(), &NotFoundError{}, err)
+
+	// Case: Matching version bumps it
+	currentModel := *suite.seedModels[2]
+	err = adapter.EditCard(suite.owner.Id, &currentModel)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 2, currentModel.Version)
+}
+
+func (suite *CardAdapterTestSuite) TestStreamCards() {
+	adapter := NewDatabaseCardAdapter(suite.conn)
+	totals, err := adapter.CountCards(suite.owner.Id)
+	assert.Nil(suite.T(), err)
+
+	streamedIds := make([]int, 0)
+	wantQuantity := 0
+	err = adapter.StreamCards(suite.owner.Id, func(card *model.Card) error {
+		assert.Equal(suite.T(), suite.owner.Id, card.OwnerId)
+		streamedIds = append(streamedIds, card.Id)
+		wantQuantity += card.WantQuantity
+		return nil
+	})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), totals.Cards, len(streamedIds))
+	assert.Equal(suite.T(), totals.WantQuantity, wantQuantity)
+	assert.IsIncreasing(suite.T(), streamedIds)
+
+	// Case: Handler errors stop the stream
+	handlerErr := errors.New("stop")
+	calls := 0
+	err = adapter.StreamCards(suite.owner.Id, func(card *model.Card) error {
+		calls++
+		return handlerErr
+	})
+	assert.Equal(suite.T(), handlerErr, err)
+	assert.Equal(suite.T(), 1, calls)
+}
+
 func (suite *CardAdapterTestSuite) TestUpdateModel() {
 	adapter := NewDatabaseCardAdapter(suite.conn)
 	changedModel := &model.Card{
-		Id:       1,
-		UniqueId: "CARD-111",
-		Pokemon:  "Another",
-		ImageUrl: "anotherUrl",
+		Id:           1,
+		OwnerId:      suite.owner.Id,
+		UniqueId:     "CARD-111",
+		Pokemon:      "Another",
+		ImageUrl:     "anotherUrl",
+		WantQuantity: 4,
+		HaveQuantity: 1,
+		Condition:    model.CardConditionDamaged,
+		Language:     "JA",
+		Finish:       model.CardFinishHolo,
+		Priority:     model.CardPriorityHigh,
+		TargetPrice:  2.5,
 	}
-	err := adapter.EditCard(changedModel)
+	err := adapter.EditCard(suite.owner.Id, changedModel)
 	assert.Nil(suite.T(), err)
 
 	results := make([]*model.Card, 0)
 	suite.conn.Conn.NewSelect().Model(&model.Card{}).Scan(suite.ctx, &results)
 
 	assert.Contains(suite.T(), results, changedModel)
+
+	// Case: Only the named fields are written
+	err = adapter.EditCard(suite.owner.Id, &model.Card{
+		Id:       1,
+		UniqueId: "CARD-IGNORED",
+		Pokemon:  "Patched",
+	}, model.CardFieldPokemon)
+	assert.Nil(suite.T(), err)
+	retrievedModel, err := adapter.GetCard(suite.owner.Id, 1)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "Patched", retrievedModel.Pokemon)
+	assert.Equal(suite.T(), changedModel.UniqueId, retrievedModel.UniqueId)
+	assert.Equal(suite.T(), changedModel.ImageUrl, retrievedModel.ImageUrl)
+
+	// Case: Catalogue details are written by a sync
+	syncedAt := time.Now().UTC()
+	syncedModel := *retrievedModel
+	syncedModel.ApplyCatalogue(&model.CatalogueCard{
+		Name:     "Synced",
+		ImageUrl: "syncedUrl",
+		SetName:  "XY",
+		Rarity:   "Rare Holo EX",
+		Types:    []string{"Grass", "Psychic"},
+		Number:   "1",
+	}, true, syncedAt)
+	err = adapter.EditCard(suite.owner.Id, &syncedModel, model.CardCatalogueFields...)
+	assert.Nil(suite.T(), err)
+	retrievedModel, err = adapter.GetCard(suite.owner.Id, 1)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "Synced", retrievedModel.Pokemon)
+	assert.Equal(suite.T(), "syncedUrl", retrievedModel.ImageUrl)
+	assert.Equal(suite.T(), "XY", retrievedModel.SetName)
+	assert.Equal(suite.T(), "Rare Holo EX", retrievedModel.Rarity)
+	assert.Equal(suite.T(), []string{"Grass", "Psychic"}, retrievedModel.Types)
+	assert.Equal(suite.T(), "1", retrievedModel.Number)
+	assert.WithinDuration(suite.T(), syncedAt, *retrievedModel.SyncedAt, time.Millisecond)
+	assert.Equal(suite.T(), changedModel.Condition, retrievedModel.Condition)
 }
 
 func (suite *CardAdapterTestSuite) TestGetCard() {
 	adapter := NewDatabaseCardAdapter(suite.conn)
-	retrievedModel, err := adapter.GetCard(3)
+	retrievedModel, err := adapter.GetCard(suite.owner.Id, 3)
 	assert.Nil(suite.T(), err)
 	assert.Equal(suite.T(), suite.seedModels[2], retrievedModel)
 
-	retrievedModel, err = adapter.GetCard(10)
+	retrievedModel, err = adapter.GetCard(suite.owner.Id, 10)
 	assert.Nil(suite.T(), err)
 	assert.Nil(suite.T(), retrievedModel)
 }
 
 func (suite *CardAdapterTestSuite) TestGetCardByUniqueId() {
 	adapter := NewDatabaseCardAdapter(suite.conn)
-	retrievedModel, err := adapter.GetCardByUniqueId("CARD-003")
+	retrievedModel, err := adapter.GetCardByUniqueId(suite.owner.Id, "CARD-003")
 	assert.Nil(suite.T(), err)
 	assert.Equal(suite.T(), suite.seedModels[2], retrievedModel)
 
-	retrievedModel, err = adapter.GetCardByUniqueId("ASDF")
+	retrievedModel, err = adapter.GetCardByUniqueId(suite.owner.Id, "ASDF")
 	assert.Nil(suite.T(), err)
 	assert.Nil(suite.T(), retrievedModel)
 }
 
 func (suite *CardAdapterTestSuite) TestGetAllCards() {
 	adapter := NewDatabaseCardAdapter(suite.conn)
-	retrievedModels, err := adapter.GetAllCards()
+	retrievedModels, err := adapter.GetAllCards(suite.owner.Id)
 	assert.Nil(suite.T(), err)
 
 	assert.Greater(suite.T(), len(retrievedModels), 1)
 }
 
+func (suite *CardAdapterTestSuite) TestListCards() {
+	adapter := NewDatabaseCardAdapter(suite.conn)
+
+	// Case: Pages follow the cursor without gaps or repeats
+	query := &model.CardQuery{
+		Limit:      1,
+		Sort:       model.CardSortPokemon,
+		Descending: true,
+	}
+	page, err := adapter.ListCards(suite.owner.Id, query)
+	assert.Nil(suite.T(), err)
+	assert.True(suite.T(), page.HasMore)
+	assert.Equal(suite.T(), 1, len(page.Items))
+	firstCard := page.Items[0]
+
+	query.After = &model.CardCursor{
+		SortValue: firstCard.Pokemon,
+		Id:        firstCard.Id,
+	}
+	page, err = adapter.ListCards(suite.owner.Id, query)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 1, len(page.Items))
+	assert.Less(suite.T(), page.Items[0].Pokemon, firstCard.Pokemon)
+
+	// Case: Cards without a name are paged through like any other
+	_, err = suite.conn.Conn.ExecContext(
+		suite.ctx,
+		"INSERT INTO cards (owner_id, card_unique_id, card_image) VALUES (?, 'CARD-NONAME', 'imageUrl')",
+		suite.owner.Id,
+	)
+	assert.Nil(suite.T(), err)
+	total, err := adapter.CountCards(suite.owner.Id)
+	assert.Nil(suite.T(), err)
+	seen := make(map[int]bool)
+	query = &model.CardQuery{
+		Limit: 1,
+		Sort:  model.CardSortPokemon,
+	}
+	for pages := 0; pages <= total.Cards; pages++ {
+		page, err = adapter.ListCards(suite.owner.Id, query)
+		assert.Nil(suite.T(), err)
+		for _, card := range page.Items {
+			assert.False(suite.T(), seen[card.Id], "card %d was listed twice", card.Id)
+			seen[card.Id] = true
+		}
+		if !page.HasMore {
+			break
+		}
+		lastCard := page.Items[len(page.Items)-1]
+		query.After = &model.CardCursor{
+			SortValue: lastCard.Pokemon,
+			Id:        lastCard.Id,
+		}
+	}
+	assert.Equal(suite.T(), total.Cards, len(seen))
+	_, err = suite.conn.Conn.ExecContext(suite.ctx, "DELETE FROM cards WHERE card_unique_id = 'CARD-NONAME'")
+	assert.Nil(suite.T(), err)
+
+	// Case: Filters narrow down both the items and the total
+	page, err = adapter.ListCards(suite.owner.Id, &model.CardQuery{
+		Limit:   10,
+		Sort:    model.CardSortId,
+		Pokemon: "cc",
+		Set:     "CARD",
+	})
+	assert.Nil(suite.T(), err)
+	assert.False(suite.T(), page.HasMore)
+	assert.Equal(suite.T(), 1, page.Total)
+	assert.Equal(suite.T(), "CCC", page.Items[0].Pokemon)
+
+	// Case: Only cards with copies still to collect
+	page, err = adapter.ListCards(suite.owner.Id, &model.CardQuery{
+		Limit:   10,
+		Sort:    model.CardSortId,
+		Missing: true,
+	})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), len(page.Items), page.Total)
+	assert.NotEmpty(suite.T(), page.Items)
+	for _, card := range page.Items {
+		assert.Less(suite.T(), card.HaveQuantity, card.WantQuantity)
+		assert.NotEqual(suite.T(), "CARD-003", card.UniqueId)
+	}
+
+	// Case: Wildcards in filters are matched literally
+	page, err = adapter.ListCards(suite.owner.Id, &model.CardQuery{
+		Limit:   10,
+		Sort:    model.CardSortId,
+		Pokemon: "%",
+	})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 0, page.Total)
+	assert.Empty(suite.T(), page.Items)
+}
+
+func (suite *CardAdapterTestSuite) TestOwnerIsolation() {
+	adapter := NewDatabaseCardAdapter(suite.conn)
+	retrievedModel, err := adapter.GetCard(suite.otherOwner.Id, 3)
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), retrievedModel)
+
+	retrievedModel, err = adapter.GetCardByUniqueId(suite.otherOwner.Id, "CARD-003")
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), retrievedModel)
+
+	err = adapter.DeleteCard(suite.otherOwner.Id, 3, 0)
+	assert.IsType(suite.T(), &NotFoundError{}, err)
+	retrievedModel, err = adapter.GetCard(suite.owner.Id, 3)
+	assert.Nil(suite.T(), err)
+	assert.NotNil(suite.T(), retrievedModel)
+
+	createdModel, err := adapter.CreateCard(suite.otherOwner.Id, &model.Card{
+		UniqueId: "CARD-003",
+		Pokemon:  "CCC",
+		ImageUrl: "imageUrl3",
+	})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), suite.otherOwner.Id, createdModel.OwnerId)
+
+	retrievedModels, err := adapter.GetAllCards(suite.otherOwner.Id)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []*model.Card{createdModel}, retrievedModels)
+}
+
+func (suite *CardAdapterTestSuite) TestRunInTx() {
+	adapter := NewDatabaseCardAdapter(suite.conn)
+	err := adapter.RunInTx(func(txAdapter DatabaseCardAdapter) error {
+		_, err := txAdapter.CreateCard(suite.owner.Id, &model.Card{
+			UniqueId: "CARD-006",
+			Pokemon:  "FFF",
+			ImageUrl: "imageUrl6",
+		})
+		assert.Nil(suite.T(), err)
+
+		_, err = txAdapter.CreateCard(suite.owner.Id, &model.Card{
+			UniqueId: "CARD-006",
+			Pokemon:  "Other",
+			ImageUrl: "imageUrl6",
+		})
+		return err
+	})
+	assert.IsType(suite.T(), &UniqueKeyError{}, err)
+
+	// Case: The first insert was rolled back with the transaction
+	result, err := adapter.GetCardByUniqueId(suite.owner.Id, "CARD-006")
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), result)
+}
+
+func (suite *CardAdapterTestSuite) TestRunInSnapshot() {
+	adapter := NewDatabaseCardAdapter(suite.conn)
+	err := adapter.RunInSnapshot(func(txAdapter DatabaseCardAdapter) error {
+		before, err := txAdapter.CountCards(suite.otherOwner.Id)
+		assert.Nil(suite.T(), err)
+
+		// Case: Cards created outside the snapshot are not seen in it
+		created, err := adapter.CreateCard(suite.otherOwner.Id, &model.Card{
+			UniqueId: "CARD-SNAPSHOT",
+			Pokemon:  "GGG",
+			ImageUrl: "imageUrl7",
+		})
+		assert.Nil(suite.T(), err)
+		defer adapter.DeleteCard(suite.otherOwner.Id, created.Id, 0)
+
+		after, err := txAdapter.CountCards(suite.otherOwner.Id)
+		assert.Nil(suite.T(), err)
+		assert.Equal(suite.T(), before, after)
+		streamed := 0
+		err = txAdapter.StreamCards(suite.otherOwner.Id, func(card *model.Card) error {
+			streamed++
+			return nil
+		})
+		assert.Nil(suite.T(), err)
+		assert.Equal(suite.T(), before.Cards, streamed)
+
+		// Case: Writes are refused
+		_, err = txAdapter.CreateCard(suite.otherOwner.Id, &model.Card{
+			UniqueId: "CARD-SNAPSHOT-2",
+			Pokemon:  "HHH",
+			ImageUrl: "imageUrl8",
+		})
+		return err
+	})
+	assert.NotNil(suite.T(), err)
+
+	// Case: Reads fail once the snapshot has been open too long
+	originalDuration := maxSnapshotDuration
+	maxSnapshotDuration = 50 * time.Millisecond
+	defer func() {
+		maxSnapshotDuration = originalDuration
+	}()
+	err = adapter.RunInSnapshot(func(txAdapter DatabaseCardAdapter) error {
+		time.Sleep(2 * maxSnapshotDuration)
+		_, err := txAdapter.CountCards(suite.owner.Id)
+		return err
+	})
+	assert.NotNil(suite.T(), err)
+}
+
+func (suite *CardAdapterTestSuite) TestSearchCards() {
+	adapter := NewDatabaseCardAdapter(suite.conn)
+
+	// Case: Word prefix match, highlighted
+	results, err := adapter.SearchCards(suite.owner.Id, "cc", 10)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 1, len(results))
+	assert.Equal(suite.T(), "CARD-003", results[0].UniqueId)
+	assert.Equal(suite.T(), "<mark>CCC</mark>", results[0].PokemonHighlight)
+	assert.Greater(suite.T(), results[0].Rank, 0.0)
+
+	// Case: Partial unique ID
+	results, err = adapter.SearchCards(suite.owner.Id, "D-003", 10)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 1, len(results))
+
+	// Case: Other owners' cards are never returned
+	results, err = adapter.SearchCards(suite.otherOwner.Id, "aaa", 10)
+	assert.Nil(suite.T(), err)
+	assert.Empty(suite.T(), results)
+}
+
+func TestBuildPrefixTsQuery(t *testing.T) {
+	assert.Equal(t, "mega:* & venu:*", buildPrefixTsQuery("Mega  Venu"))
+	assert.Equal(t, "xy1:* & 15:*", buildPrefixTsQuery("xy1-15"))
+	assert.Equal(t, "", buildPrefixTsQuery("'&|!"))
+}
+
+func TestFormatSearchHighlight(t *testing.T) {
+	highlight := "<b>" + searchHighlightStart + "Mew" + searchHighlightStop + "</b>"
+	assert.Equal(t, "&lt;b&gt;<mark>Mew</mark>&lt;/b&gt;", formatSearchHighlight(highlight))
+}
+
 func TestCardAdapterTestSuite(t *testing.T) {
 	suite.Run(t, new(CardAdapterTestSuite))
 }
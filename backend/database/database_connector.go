@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/uptrace/bun"
 	"github.com/uptrace/bun/dialect/pgdialect"
@@ -13,9 +14,24 @@ import (
 type DatabaseAdapter[M any] interface {
 	QuerySingle(query string, args ...interface{}) (*M, error)
 	QueryMany(query string, args ...interface{}) ([]*M, error)
+	QueryEach(handler func(row *M) error, query string, args ...interface{}) error
 	Execute(query string, args ...interface{}) (err error)
+	// RunInTx runs fn in a transaction, committing when it returns nil and
+	// rolling back otherwise. Calls made inside another transaction use a savepoint.
+	RunInTx(fn func(tx bun.IDB) error) error
+	// RunInSnapshot runs fn in a read only repeatable read transaction, so that
+	// every query it makes sees the database as it was at the first one. The
+	// transaction is rolled back once it has been open for maxSnapshotDuration.
+	RunInSnapshot(fn func(tx bun.IDB) error) error
+	// WithConn returns a copy of the adapter that queries through conn, such as
+	// the transaction handed to a RunInTx callback
+	WithConn(conn bun.IDB) DatabaseAdapter[M]
 }
 
+// maxSnapshotDuration bounds how long a snapshot stays open, so a client that
+// stops reading a streamed export cannot hold its transaction open for good
+var maxSnapshotDuration = 5 * time.Minute
+
 type DatabaseConnection struct {
 	Server   string
 	Username string
@@ -26,7 +42,8 @@ type DatabaseConnection struct {
 }
 
 type databaseAdapter[M any] struct {
-	conn *bun.DB
+	db   *bun.DB
+	conn bun.IDB
 }
 
 type userRow struct {
@@ -55,22 +72,48 @@ func ConnectDatabase(server string, username string, password string, dbName str
 
 func newDatabaseAdapter[M any](conn *DatabaseConnection) DatabaseAdapter[M] {
 	return &databaseAdapter[M]{
+		db:   conn.Conn,
 		conn: conn.Conn,
 	}
 }
 
 func (db *databaseAdapter[M]) GetConn() *bun.DB {
-	return db.conn
+	return db.db
+}
+
+func (db *databaseAdapter[M]) RunInTx(fn func(tx bun.IDB) error) error {
+	return db.conn.RunInTx(context.Background(), nil, func(ctx context.Context, tx bun.Tx) error {
+		return fn(tx)
+	})
+}
+
+func (db *databaseAdapter[M]) RunInSnapshot(fn func(tx bun.IDB) error) error {
+	options := &sql.TxOptions{
+		Isolation: sql.LevelRepeatableRead,
+		ReadOnly:  true,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), maxSnapshotDuration)
+	defer cancel()
+	return db.conn.RunInTx(ctx, options, func(ctx context.Context, tx bun.Tx) error {
+		return fn(tx)
+	})
+}
+
+func (db *databaseAdapter[M]) WithConn(conn bun.IDB) DatabaseAdapter[M] {
+	return &databaseAdapter[M]{
+		db:   db.db,
+		conn: conn,
+	}
 }
 
 func (db *databaseAdapter[M]) QuerySingle(query string, args ...interface{}) (*M, error) {
 	var container M
 	ctx := context.Background()
-	err := bun.NewRawQuery(db.conn, query, args...).Scan(ctx, &container)
+	err := db.conn.NewRaw(query, args...).Scan(ctx, &container)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	} else if err != nil {
-		return nil, err
+		return nil, mapDatabaseError(err)
 	}
 	return &container, nil
 }
@@ -78,17 +121,41 @@ func (db *databaseAdapter[M]) QuerySingle(query string, args ...interface{}) (*M
 func (db *databaseAdapter[M]) QueryMany(query string, args ...interface{}) ([]*M, error) {
 	results := make([]*M, 0)
 	ctx := context.Background()
-	err := bun.NewRawQuery(db.conn, query, args...).Scan(ctx, &results)
+	err := db.conn.NewRaw(query, args...).Scan(ctx, &results)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	} else if err != nil {
-		return nil, err
+		return nil, mapDatabaseError(err)
 	}
 
 	return results, err
 }
 
+// QueryEach hands rows to the handler one at a time as they are read, so large
+// results are never held in memory. An error from the handler stops the query.
+func (db *databaseAdapter[M]) QueryEach(handler func(row *M) error, query string, args ...interface{}) error {
+	ctx := context.Background()
+	rows, err := db.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return mapDatabaseError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var container M
+		err = db.db.ScanRow(ctx, rows, &container)
+		if err != nil {
+			return mapDatabaseError(err)
+		}
+		err = handler(&container)
+		if err != nil {
+			return err
+		}
+	}
+	return mapDatabaseError(rows.Err())
+}
+
 func (db *databaseAdapter[M]) Execute(query string, args ...interface{}) error {
-	_, err := db.conn.Exec(query, args...)
-	return err
+	_, err := db.conn.ExecContext(context.Background(), query, args...)
+	return mapDatabaseError(err)
 }
@@ -1,7 +1,132 @@
 package database
 
-type UniqueKeyError struct{}
+import (
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/uptrace/bun/driver/pgdriver"
+)
+
+type UniqueKeyError struct {
+	Constraint string
+}
 
 func (m *UniqueKeyError) Error() string {
 	return "Unique key violation"
 }
+
+type ForeignKeyError struct {
+	Constraint string
+}
+
+func (m *ForeignKeyError) Error() string {
+	return "Foreign key violation"
+}
+
+type NotNullError struct {
+	Column string
+}
+
+func (m *NotNullError) Error() string {
+	return "Not null violation"
+}
+
+type CheckError struct {
+	Constraint string
+}
+
+func (m *CheckError) Error() string {
+	return "Check constraint violation"
+}
+
+// SerializationError is returned when Postgres aborts a transaction that lost a
+// serialization race or deadlock; retrying the whole transaction may succeed
+type SerializationError struct{}
+
+func (m *SerializationError) Error() string {
+	return "Serialization failure"
+}
+
+// ConnectionError wraps failures to reach the database or to keep a connection
+// to it open
+type ConnectionError struct {
+	Err error
+}
+
+func (m *ConnectionError) Error() string {
+	return "Database connection failure: " + m.Err.Error()
+}
+
+func (m *ConnectionError) Unwrap() error {
+	return m.Err
+}
+
+type VersionConflictError struct{}
+
+func (m *VersionConflictError) Error() string {
+	return "Version conflict"
+}
+
+// NotFoundError is returned when the row a write targets does not exist
+type NotFoundError struct{}
+
+func (m *NotFoundError) Error() string {
+	return "Row not found"
+}
+
+// IsConstraintError reports whether err is a rejected write that the caller can
+// fix by changing the data it sent
+func IsConstraintError(err error) bool {
+	var foreignKeyErr *ForeignKeyError
+	var notNullErr *NotNullError
+	var checkErr *CheckError
+	return errors.As(err, &foreignKeyErr) || errors.As(err, &notNullErr) || errors.As(err, &checkErr)
+}
+
+// IsUnavailableError reports whether err is a transient failure that the caller
+// may retry later
+func IsUnavailableError(err error) bool {
+	var serializationErr *SerializationError
+	var connectionErr *ConnectionError
+	return errors.As(err, &serializationErr) || errors.As(err, &connectionErr)
+}
+
+// mapDatabaseError classifies driver errors into the typed errors above, passing
+// anything it does not recognise through unchanged
+func mapDatabaseError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var pgErr pgdriver.Error
+	if errors.As(err, &pgErr) {
+		code := pgErr.Field('C')
+		switch code {
+		case "23505":
+			return &UniqueKeyError{Constraint: pgErr.Field('n')}
+		case "23503":
+			return &ForeignKeyError{Constraint: pgErr.Field('n')}
+		case "23502":
+			return &NotNullError{Column: pgErr.Field('c')}
+		case "23514":
+			return &CheckError{Constraint: pgErr.Field('n')}
+		case "40001", "40P01":
+			return &SerializationError{}
+		}
+		// Class 08 covers connection exceptions, 57P0x the server shutting down
+		if strings.HasPrefix(code, "08") || strings.HasPrefix(code, "57P0") {
+			return &ConnectionError{Err: err}
+		}
+		return err
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return &ConnectionError{Err: err}
+	}
+	return err
+}
@@ -0,0 +1,31 @@
+package database
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMapDatabaseError(t *testing.T) {
+	assert.Nil(t, mapDatabaseError(nil))
+
+	otherErr := errors.New("Test Error")
+	assert.Equal(t, otherErr, mapDatabaseError(otherErr))
+	assert.False(t, IsUnavailableError(otherErr))
+	assert.False(t, IsConstraintError(otherErr))
+
+	for _, connErr := range []error{io.EOF, driver.ErrBadConn, fmt.Errorf("read: %w", io.ErrUnexpectedEOF)} {
+		err := mapDatabaseError(connErr)
+		assert.IsType(t, &ConnectionError{}, err)
+		assert.True(t, IsUnavailableError(err))
+		assert.ErrorIs(t, err, connErr)
+	}
+
+	assert.True(t, IsUnavailableError(&SerializationError{}))
+	assert.True(t, IsConstraintError(&NotNullError{Column: "card_pokemon"}))
+	assert.True(t, IsConstraintError(&CheckError{}))
+}
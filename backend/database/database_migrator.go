@@ -0,0 +1,270 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+//go:embed seed.sql
+var seedScript string
+
+// migrationLockKey identifies the advisory lock held while migrating, so that
+// replicas starting together apply each migration once
+const migrationLockKey = 3219001
+
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_([0-9a-z_]+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version int
+	Name    string
+	up      string
+	down    string
+}
+
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+type appliedMigrationRow struct {
+	Version   int       `bun:"version"`
+	AppliedAt time.Time `bun:"applied_at"`
+}
+
+// Migrator applies the SQL migrations embedded in the binary, recording them in
+// the schema_migrations table. Each migration runs in its own transaction.
+type Migrator struct {
+	db         *bun.DB
+	migrations []*Migration
+}
+
+func NewMigrator(connector *DatabaseConnection) (*Migrator, error) {
+	migrationsDir, err := fs.Sub(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := loadMigrations(migrationsDir)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{
+		db:         connector.Conn,
+		migrations: migrations,
+	}, nil
+}
+
+// loadMigrations reads every up/down pair in fsys, sorted by version
+func loadMigrations(fsys fs.FS) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		matches := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || matches == nil {
+			return nil, fmt.Errorf("unexpected migration file %s", entry.Name())
+		}
+		version, _ := strconv.Atoi(matches[1])
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: matches[2]}
+			byVersion[version] = migration
+		} else if migration.Name != matches[2] {
+			return nil, fmt.Errorf("migration %d has files named both %s and %s", version, migration.Name, matches[2])
+		}
+		if matches[3] == "up" {
+			migration.up = string(content)
+		} else {
+			migration.down = string(content)
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.up == "" || migration.down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down script", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// Up applies every pending migration in order, returning those it applied
+func (migrator *Migrator) Up() ([]*Migration, error) {
+	applied := make([]*Migration, 0)
+	err := migrator.withLock(func(ctx context.Context, conn bun.Conn) error {
+		appliedAt, err := migrator.appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range migrator.migrations {
+			if _, ok := appliedAt[migration.Version]; ok {
+				continue
+			}
+			err = conn.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+				_, err := tx.ExecContext(ctx, migration.up)
+				if err != nil {
+					return err
+				}
+				_, err = tx.ExecContext(ctx, "INSERT INTO schema_migrations (version, name) VALUES (?, ?)", migration.Version, migration.Name)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d_%s failed: %w", migration.Version, migration.Name, err)
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down reverts up to steps of the most recently applied migrations, newest first
+func (migrator *Migrator) Down(steps int) ([]*Migration, error) {
+	reverted := make([]*Migration, 0)
+	err := migrator.withLock(func(ctx context.Context, conn bun.Conn) error {
+		appliedAt, err := migrator.appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(migrator.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			migration := migrator.migrations[i]
+			if _, ok := appliedAt[migration.Version]; !ok {
+				continue
+			}
+			err = conn.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+				_, err := tx.ExecContext(ctx, migration.down)
+				if err != nil {
+					return err
+				}
+				_, err = tx.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = ?", migration.Version)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("reverting migration %d_%s failed: %w", migration.Version, migration.Name, err)
+			}
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status lists every known migration along with when it was applied, if ever.
+// It only reads, so it neither waits for the migration lock nor creates the
+// schema_migrations table, reporting whether that table exists instead.
+func (migrator *Migrator) Status() ([]*MigrationStatus, bool, error) {
+	ctx := context.Background()
+	var tableName sql.NullString
+	err := migrator.db.QueryRowContext(ctx, "SELECT to_regclass('schema_migrations')::TEXT").Scan(&tableName)
+	if err != nil {
+		return nil, false, mapDatabaseError(err)
+	}
+
+	appliedAt := make(map[int]time.Time)
+	if tableName.Valid {
+		conn, err := migrator.db.Conn(ctx)
+		if err != nil {
+			return nil, true, mapDatabaseError(err)
+		}
+		defer conn.Close()
+		appliedAt, err = migrator.appliedVersions(ctx, conn)
+		if err != nil {
+			return nil, true, err
+		}
+	}
+
+	statuses := make([]*MigrationStatus, 0, len(migrator.migrations))
+	for _, migration := range migrator.migrations {
+		status := &MigrationStatus{Migration: *migration}
+		if timestamp, ok := appliedAt[migration.Version]; ok {
+			status.AppliedAt = &timestamp
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, tableName.Valid, nil
+}
+
+// Seed loads the sample development data, but only into a database without any
+// tokens. It reports whether the data was loaded.
+func (migrator *Migrator) Seed() (bool, error) {
+	seeded := false
+	err := migrator.withLock(func(ctx context.Context, conn bun.Conn) error {
+		return conn.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+			var tokenCount int
+			err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM api_tokens").Scan(&tokenCount)
+			if err != nil || tokenCount > 0 {
+				return err
+			}
+			_, err = tx.ExecContext(ctx, seedScript)
+			seeded = err == nil
+			return err
+		})
+	})
+	return seeded, err
+}
+
+// withLock runs fn on a dedicated connection holding the migration advisory
+// lock, creating the schema_migrations table first if needed
+func (migrator *Migrator) withLock(fn func(ctx context.Context, conn bun.Conn) error) error {
+	ctx := context.Background()
+	conn, err := migrator.db.Conn(ctx)
+	if err != nil {
+		return mapDatabaseError(err)
+	}
+	defer conn.Close()
+
+	_, err = conn.ExecContext(ctx, "SELECT pg_advisory_lock(?)", migrationLockKey)
+	if err != nil {
+		return mapDatabaseError(err)
+	}
+	defer conn.ExecContext(ctx, "SELECT pg_advisory_unlock(?)", migrationLockKey)
+
+	_, err = conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TIMESTAMP NOT NULL DEFAULT NOW()
+	)`)
+	if err != nil {
+		return mapDatabaseError(err)
+	}
+	return fn(ctx, conn)
+}
+
+func (migrator *Migrator) appliedVersions(ctx context.Context, conn bun.Conn) (map[int]time.Time, error) {
+	rows := make([]*appliedMigrationRow, 0)
+	err := conn.NewRaw("SELECT version, applied_at FROM schema_migrations").Scan(ctx, &rows)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, mapDatabaseError(err)
+	}
+
+	appliedAt := make(map[int]time.Time, len(rows))
+	for _, row := range rows {
+		appliedAt[row.Version] = row.AppliedAt
+	}
+	return appliedAt, nil
+}
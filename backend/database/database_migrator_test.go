@@ -0,0 +1,44 @@
+package database
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := loadMigrations(fstest.MapFS{
+		"0002_add_column.up.sql":     {Data: []byte("ALTER TABLE b ADD COLUMN c INTEGER;")},
+		"0002_add_column.down.sql":   {Data: []byte("ALTER TABLE b DROP COLUMN c;")},
+		"0001_create_table.up.sql":   {Data: []byte("CREATE TABLE b ();")},
+		"0001_create_table.down.sql": {Data: []byte("DROP TABLE b;")},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(migrations))
+	assert.Equal(t, 1, migrations[0].Version)
+	assert.Equal(t, "create_table", migrations[0].Name)
+	assert.Equal(t, "DROP TABLE b;", migrations[0].down)
+	assert.Equal(t, 2, migrations[1].Version)
+
+	// Case: Missing down script
+	_, err = loadMigrations(fstest.MapFS{
+		"0001_create_table.up.sql": {Data: []byte("CREATE TABLE b ();")},
+	})
+	assert.NotNil(t, err)
+
+	// Case: Unrecognised file name
+	_, err = loadMigrations(fstest.MapFS{
+		"create_table.sql": {Data: []byte("CREATE TABLE b ();")},
+	})
+	assert.NotNil(t, err)
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	migrator, err := NewMigrator(&DatabaseConnection{})
+	assert.Nil(t, err)
+	assert.NotEmpty(t, migrator.migrations)
+	for i, migration := range migrator.migrations {
+		assert.Equal(t, i+1, migration.Version, "migration versions must be consecutive")
+	}
+}
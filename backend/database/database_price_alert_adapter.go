@@ -0,0 +1,89 @@
+package database
+
+import (
+	"backend.cs3219.comp.nus.edu.sg/model"
+	"github.com/uptrace/bun"
+)
+
+// priceAlertLockKey identifies the per card advisory locks held while an alert
+// is recorded, so that replicas checking the same card record one alert
+const priceAlertLockKey = 3219002
+
+//go:generate mockgen -destination=../mocks/mock_database_price_alert_adapter.go -build_flags=-mod=mod -package=mocks backend.cs3219.comp.nus.edu.sg/database DatabasePriceAlertAdapter
+type DatabasePriceAlertAdapter interface {
+	// GetCardsWithTargetPrice returns the cards of every owner that have a
+	// target price, most important first
+	GetCardsWithTargetPrice() ([]*model.Card, error)
+	// CreatePriceAlert records the alert unless the card already has one at the
+	// same target price and an equal or lower market price, in which case it
+	// returns nil without an error. Calls for the same card take turns, even
+	// from different replicas.
+	CreatePriceAlert(alert *model.PriceAlert) (*model.PriceAlert, error)
+	ListPriceAlerts(ownerId int, query *model.PriceAlertQuery) ([]*model.PriceAlert, error)
+}
+
+type databasePriceAlertAdapter struct {
+	cardAdapter  DatabaseAdapter[model.Card]
+	alertAdapter DatabaseAdapter[model.PriceAlert]
+}
+
+func NewDatabasePriceAlertAdapter(connector *DatabaseConnection) DatabasePriceAlertAdapter {
+	return &databasePriceAlertAdapter{
+		cardAdapter:  newDatabaseAdapter[model.Card](connector),
+		alertAdapter: newDatabaseAdapter[model.PriceAlert](connector),
+	}
+}
+
+func (adapter *databasePriceAlertAdapter) GetCardsWithTargetPrice() ([]*model.Card, error) {
+	return adapter.cardAdapter.QueryMany(
+		"SELECT * FROM cards WHERE card_target_price IS NOT NULL " +
+			"ORDER BY CASE card_priority WHEN 'high' THEN 0 WHEN 'medium' THEN 1 ELSE 2 END, card_id",
+	)
+}
+
+func (adapter *databasePriceAlertAdapter) CreatePriceAlert(alert *model.PriceAlert) (*model.PriceAlert, error) {
+	var createdAlert *model.PriceAlert
+	err := adapter.alertAdapter.RunInTx(func(tx bun.IDB) error {
+		alertAdapter := adapter.alertAdapter.WithConn(tx)
+
+		// The lock is taken before the insert reads the card's alerts, so that
+		// it sees any alert recorded by a replica that held the lock before it
+		err := alertAdapter.Execute("SELECT pg_advisory_xact_lock(?, ?)", priceAlertLockKey, alert.CardId)
+		if err != nil {
+			return err
+		}
+
+		// Prices are compared once rounded to cents, as they are stored
+		createdAlert, err = alertAdapter.QuerySingle(
+			"INSERT INTO price_alerts (card_id, alert_variant, alert_market_price, alert_target_price) "+
+				"SELECT ?, ?, ROUND(?::NUMERIC, 2), ROUND(?::NUMERIC, 2) WHERE NOT EXISTS ("+
+				"SELECT 1 FROM price_alerts WHERE card_id = ? AND alert_target_price = ROUND(?::NUMERIC, 2) "+
+				"AND alert_market_price <= ROUND(?::NUMERIC, 2)) RETURNING *",
+			alert.CardId,
+			alert.Variant,
+			alert.MarketPrice,
+			alert.TargetPrice,
+			alert.CardId,
+			alert.TargetPrice,
+			alert.MarketPrice,
+		)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return createdAlert, nil
+}
+
+func (adapter *databasePriceAlertAdapter) ListPriceAlerts(ownerId int, query *model.PriceAlertQuery) ([]*model.PriceAlert, error) {
+	return adapter.alertAdapter.QueryMany(
+		"SELECT a.*, c.card_unique_id, c.card_pokemon, c.card_priority "+
+			"FROM price_alerts a JOIN cards c ON c.card_id = a.card_id "+
+			"WHERE c.owner_id = ? AND (?=0 OR a.card_id = ?) "+
+			"ORDER BY a.created_at DESC, a.alert_id DESC LIMIT ?",
+		ownerId,
+		query.CardId,
+		query.CardId,
+		query.Limit,
+	)
+}
@@ -0,0 +1,167 @@
+package database
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"backend.cs3219.comp.nus.edu.sg/model"
+	"backend.cs3219.comp.nus.edu.sg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type PriceAlertAdapterTestSuite struct {
+	suite.Suite
+	conn       *DatabaseConnection
+	ctx        context.Context
+	owner      *model.ApiToken
+	otherOwner *model.ApiToken
+	cards      []*model.Card
+}
+
+func (suite *PriceAlertAdapterTestSuite) SetupSuite() {
+	config := util.LoadEnvVariables()
+	conn, err := ConnectDatabase(
+		config.DbUrl,
+		config.DbUsername,
+		config.DbPassword,
+		config.DbName,
+	)
+	if err != nil || conn == nil {
+		suite.T().Fatal("Failed to read db config")
+	}
+	suite.ctx = context.Background()
+	suite.conn = conn
+
+	migrator, err := NewMigrator(conn)
+	assert.Nil(suite.T(), err)
+	_, err = migrator.Up()
+	assert.Nil(suite.T(), err)
+
+	// Deleting the owners also deletes their cards and alerts
+	suite.owner = newSeedApiToken(0, "ALERTOWNA", true)
+	suite.otherOwner = newSeedApiToken(0, "ALERTOWNB", true)
+	_, _ = conn.Conn.NewDelete().Model(&model.ApiToken{}).Where("token_prefix IN (?, ?)", suite.owner.Prefix, suite.otherOwner.Prefix).Exec(suite.ctx)
+	for _, item := range []*model.ApiToken{suite.owner, suite.otherOwner} {
+		_, err = conn.Conn.NewInsert().Model(item).ExcludeColumn("token_id").Returning("token_id").Exec(suite.ctx)
+		assert.Nil(suite.T(), err)
+	}
+
+	cardAdapter := NewDatabaseCardAdapter(conn)
+	suite.cards = make([]*model.Card, 0)
+	for _, card := range []*model.Card{
+		{OwnerId: suite.owner.Id, UniqueId: "ALERT-1", Pokemon: "AAA", ImageUrl: "imageUrl1", TargetPrice: 10.5, Priority: model.CardPriorityLow},
+		{OwnerId: suite.owner.Id, UniqueId: "ALERT-2", Pokemon: "BBB", ImageUrl: "imageUrl2"},
+		{OwnerId: suite.otherOwner.Id, UniqueId: "ALERT-1", Pokemon: "AAA", ImageUrl: "imageUrl1", TargetPrice: 4, Priority: model.CardPriorityHigh},
+	} {
+		createdCard, err := cardAdapter.CreateCard(card.OwnerId, card)
+		assert.Nil(suite.T(), err)
+		suite.cards = append(suite.cards, createdCard)
+	}
+}
+
+func (suite *PriceAlertAdapterTestSuite) TestGetCardsWithTargetPrice() {
+	adapter := NewDatabasePriceAlertAdapter(suite.conn)
+	cards, err := adapter.GetCardsWithTargetPrice()
+	assert.Nil(suite.T(), err)
+
+	// Other suites may have left cards with target prices behind
+	ownCards := make([]*model.Card, 0)
+	for _, card := range cards {
+		if card.OwnerId == suite.owner.Id || card.OwnerId == suite.otherOwner.Id {
+			ownCards = append(ownCards, card)
+		}
+	}
+	assert.Equal(suite.T(), []*model.Card{suite.cards[2], suite.cards[0]}, ownCards)
+}
+
+func (suite *PriceAlertAdapterTestSuite) TestPriceAlerts() {
+	adapter := NewDatabasePriceAlertAdapter(suite.conn)
+	card := suite.cards[0]
+
+	firstAlert, err := adapter.CreatePriceAlert(&model.PriceAlert{
+		CardId:      card.Id,
+		Variant:     model.PriceVariantNormal,
+		MarketPrice: 9.999,
+		TargetPrice: card.TargetPrice,
+	})
+	assert.Nil(suite.T(), err)
+	assert.NotNil(suite.T(), firstAlert)
+	assert.Equal(suite.T(), 10.0, firstAlert.MarketPrice)
+
+	// Case: The price has not dropped any further
+	alert, err := adapter.CreatePriceAlert(&model.PriceAlert{
+		CardId:      card.Id,
+		Variant:     model.PriceVariantNormal,
+		MarketPrice: 10.001,
+		TargetPrice: card.TargetPrice,
+	})
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), alert)
+
+	// Case: The price has dropped further
+	secondAlert, err := adapter.CreatePriceAlert(&model.PriceAlert{
+		CardId:      card.Id,
+		Variant:     model.PriceVariantNormal,
+		MarketPrice: 9,
+		TargetPrice: card.TargetPrice,
+	})
+	assert.Nil(suite.T(), err)
+	assert.NotNil(suite.T(), secondAlert)
+
+	// Case: Listed newest first, with the card's details
+	alerts, err := adapter.ListPriceAlerts(suite.owner.Id, &model.PriceAlertQuery{Limit: 10})
+	assert.Nil(suite.T(), err)
+	assert.Len(suite.T(), alerts, 2)
+	assert.Equal(suite.T(), secondAlert.Id, alerts[0].Id)
+	assert.Equal(suite.T(), firstAlert.Id, alerts[1].Id)
+	assert.Equal(suite.T(), card.UniqueId, alerts[0].CardUniqueId)
+	assert.Equal(suite.T(), card.Pokemon, alerts[0].CardPokemon)
+	assert.Equal(suite.T(), model.CardPriorityLow, alerts[0].CardPriority)
+
+	// Case: Filtered by card, and limited
+	alerts, err = adapter.ListPriceAlerts(suite.owner.Id, &model.PriceAlertQuery{CardId: suite.cards[1].Id, Limit: 10})
+	assert.Nil(suite.T(), err)
+	assert.Empty(suite.T(), alerts)
+	alerts, err = adapter.ListPriceAlerts(suite.owner.Id, &model.PriceAlertQuery{CardId: card.Id, Limit: 1})
+	assert.Nil(suite.T(), err)
+	assert.Len(suite.T(), alerts, 1)
+
+	// Case: Other owners cannot see the alerts
+	alerts, err = adapter.ListPriceAlerts(suite.otherOwner.Id, &model.PriceAlertQuery{Limit: 10})
+	assert.Nil(suite.T(), err)
+	assert.Empty(suite.T(), alerts)
+
+	// Case: Replicas alerting on the same card at once record one alert
+	otherCard := suite.cards[2]
+	var wg sync.WaitGroup
+	created := make(chan *model.PriceAlert, 4)
+	for i := 0; i < cap(created); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			alert, err := adapter.CreatePriceAlert(&model.PriceAlert{
+				CardId:      otherCard.Id,
+				Variant:     model.PriceVariantNormal,
+				MarketPrice: 3,
+				TargetPrice: otherCard.TargetPrice,
+			})
+			assert.Nil(suite.T(), err)
+			created <- alert
+		}()
+	}
+	wg.Wait()
+	close(created)
+	createdCount := 0
+	for alert := range created {
+		if alert != nil {
+			createdCount++
+		}
+	}
+	assert.Equal(suite.T(), 1, createdCount)
+}
+
+func TestPriceAlertAdapterTestSuite(t *testing.T) {
+	suite.Run(t, new(PriceAlertAdapterTestSuite))
+}
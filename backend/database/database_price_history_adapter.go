@@ -0,0 +1,120 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"backend.cs3219.comp.nus.edu.sg/model"
+)
+
+//go:generate mockgen -destination=../mocks/mock_database_price_history_adapter.go -build_flags=-mod=mod -package=mocks backend.cs3219.comp.nus.edu.sg/database DatabasePriceHistoryAdapter
+type DatabasePriceHistoryAdapter interface {
+	// GetWantedUniqueIds returns every unique ID on anyone's wishlist
+	GetWantedUniqueIds() ([]string, error)
+	// RecordPrices skips the variants already recorded for the card at the
+	// same time, such as by another replica running the same snapshot
+	RecordPrices(uniqueId string, prices *model.CardPrices, recordedAt time.Time) error
+	// GetPriceHistory returns the snapshots taken from from up to but excluding
+	// to, ordered by variant and then by time
+	GetPriceHistory(uniqueId string, from time.Time, to time.Time) ([]*model.PricePoint, error)
+	// GetPriceStats summarises the same snapshots as GetPriceHistory per variant
+	GetPriceStats(uniqueId string, from time.Time, to time.Time) ([]*model.PriceStats, error)
+}
+
+type databasePriceHistoryAdapter struct {
+	uniqueIdAdapter DatabaseAdapter[uniqueIdRow]
+	pointAdapter    DatabaseAdapter[model.PricePoint]
+	statsAdapter    DatabaseAdapter[model.PriceStats]
+}
+
+type uniqueIdRow struct {
+	UniqueId string `bun:"card_unique_id"`
+}
+
+func NewDatabasePriceHistoryAdapter(connector *DatabaseConnection) DatabasePriceHistoryAdapter {
+	return &databasePriceHistoryAdapter{
+		uniqueIdAdapter: newDatabaseAdapter[uniqueIdRow](connector),
+		pointAdapter:    newDatabaseAdapter[model.PricePoint](connector),
+		statsAdapter:    newDatabaseAdapter[model.PriceStats](connector),
+	}
+}
+
+func (adapter *databasePriceHistoryAdapter) GetWantedUniqueIds() ([]string, error) {
+	rows, err := adapter.uniqueIdAdapter.QueryMany("SELECT DISTINCT card_unique_id FROM cards ORDER BY card_unique_id")
+	if err != nil {
+		return nil, err
+	}
+
+	uniqueIds := make([]string, 0, len(rows))
+	for _, row := range rows {
+		uniqueIds = append(uniqueIds, row.UniqueId)
+	}
+	return uniqueIds, nil
+}
+
+func (adapter *databasePriceHistoryAdapter) RecordPrices(uniqueId string, prices *model.CardPrices, recordedAt time.Time) error {
+	if prices == nil || len(prices.Prices) == 0 {
+		return nil
+	}
+
+	// Sorted so that the statement is the same for the same prices
+	variants := make([]string, 0, len(prices.Prices))
+	for variant, priceRange := range prices.Prices {
+		if priceRange != nil {
+			variants = append(variants, variant)
+		}
+	}
+	sort.Strings(variants)
+
+	valueRows := make([]string, 0, len(variants))
+	args := make([]interface{}, 0, len(variants)*7)
+	for _, variant := range variants {
+		priceRange := prices.Prices[variant]
+		valueRows = append(valueRows, "(?, ?, ?, ?, ?, ?, ?)")
+		args = append(
+			args,
+			uniqueId,
+			variant,
+			nullablePrice(priceRange.LowPrice),
+			nullablePrice(priceRange.MidPrice),
+			nullablePrice(priceRange.HighPrice),
+			nullablePrice(priceRange.MarketPrice),
+			recordedAt.UTC(),
+		)
+	}
+	if len(valueRows) == 0 {
+		return nil
+	}
+
+	return adapter.pointAdapter.Execute(
+		fmt.Sprintf(
+			"INSERT INTO price_history (card_unique_id, price_variant, price_low, price_mid, price_high, price_market, recorded_at) VALUES %s "+
+				"ON CONFLICT (card_unique_id, price_variant, recorded_at) DO NOTHING",
+			strings.Join(valueRows, ", "),
+		),
+		args...,
+	)
+}
+
+func (adapter *databasePriceHistoryAdapter) GetPriceHistory(uniqueId string, from time.Time, to time.Time) ([]*model.PricePoint, error) {
+	return adapter.pointAdapter.QueryMany(
+		"SELECT price_variant, recorded_at, price_low, price_mid, price_high, price_market FROM price_history "+
+			"WHERE card_unique_id = ? AND recorded_at >= ? AND recorded_at < ? ORDER BY price_variant, recorded_at",
+		uniqueId,
+		from.UTC(),
+		to.UTC(),
+	)
+}
+
+func (adapter *databasePriceHistoryAdapter) GetPriceStats(uniqueId string, from time.Time, to time.Time) ([]*model.PriceStats, error) {
+	return adapter.statsAdapter.QueryMany(
+		"SELECT price_variant, COUNT(price_market) AS samples, MIN(price_market) AS min_price, "+
+			"MAX(price_market) AS max_price, ROUND(AVG(price_market), 2) AS avg_price FROM price_history "+
+			"WHERE card_unique_id = ? AND recorded_at >= ? AND recorded_at < ? GROUP BY price_variant ORDER BY price_variant",
+		uniqueId,
+		from.UTC(),
+		to.UTC(),
+	)
+}
@@ -0,0 +1,115 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"backend.cs3219.comp.nus.edu.sg/model"
+	"backend.cs3219.comp.nus.edu.sg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type PriceHistoryAdapterTestSuite struct {
+	suite.Suite
+	conn  *DatabaseConnection
+	ctx   context.Context
+	owner *model.ApiToken
+	start time.Time
+}
+
+const historyUniqueId = "HISTORY-1"
+
+func (suite *PriceHistoryAdapterTestSuite) SetupSuite() {
+	config := util.LoadEnvVariables()
+	conn, err := ConnectDatabase(
+		config.DbUrl,
+		config.DbUsername,
+		config.DbPassword,
+		config.DbName,
+	)
+	if err != nil || conn == nil {
+		suite.T().Fatal("Failed to read db config")
+	}
+	suite.ctx = context.Background()
+	suite.conn = conn
+
+	migrator, err := NewMigrator(conn)
+	assert.Nil(suite.T(), err)
+	_, err = migrator.Up()
+	assert.Nil(suite.T(), err)
+
+	suite.owner = newSeedApiToken(0, "HISTOWNA", true)
+	_, _ = conn.Conn.NewDelete().Model(&model.ApiToken{}).Where("token_prefix = ?", suite.owner.Prefix).Exec(suite.ctx)
+	_, err = conn.Conn.NewInsert().Model(suite.owner).ExcludeColumn("token_id").Returning("token_id").Exec(suite.ctx)
+	assert.Nil(suite.T(), err)
+	_, err = NewDatabaseCardAdapter(conn).CreateCard(suite.owner.Id, &model.Card{
+		UniqueId: historyUniqueId,
+		Pokemon:  "AAA",
+		ImageUrl: "imageUrl1",
+	})
+	assert.Nil(suite.T(), err)
+
+	_, err = conn.Conn.ExecContext(suite.ctx, "DELETE FROM price_history WHERE card_unique_id = ?", historyUniqueId)
+	assert.Nil(suite.T(), err)
+	suite.start = time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func (suite *PriceHistoryAdapterTestSuite) TestGetWantedUniqueIds() {
+	adapter := NewDatabasePriceHistoryAdapter(suite.conn)
+	uniqueIds, err := adapter.GetWantedUniqueIds()
+	assert.Nil(suite.T(), err)
+	assert.Contains(suite.T(), uniqueIds, historyUniqueId)
+}
+
+func (suite *PriceHistoryAdapterTestSuite) TestPriceHistory() {
+	adapter := NewDatabasePriceHistoryAdapter(suite.conn)
+	for day, marketPrice := range []float64{4, 6, 11} {
+		err := adapter.RecordPrices(historyUniqueId, &model.CardPrices{
+			Prices: map[string]*model.PriceRange{
+				model.PriceVariantHolofoil: {LowPrice: 2, MidPrice: 5, HighPrice: 20, MarketPrice: marketPrice},
+				// No market price is known for this variant
+				model.PriceVariantNormal: {LowPrice: 1},
+			},
+		}, suite.start.AddDate(0, 0, day))
+		assert.Nil(suite.T(), err)
+	}
+
+	// Case: The same snapshot recorded again, as by another replica, is skipped
+	err := adapter.RecordPrices(historyUniqueId, &model.CardPrices{
+		Prices: map[string]*model.PriceRange{
+			model.PriceVariantHolofoil: {MarketPrice: 5},
+		},
+	}, suite.start)
+	assert.Nil(suite.T(), err)
+
+	// Case: The last snapshot falls outside the range
+	to := suite.start.AddDate(0, 0, 2)
+	points, err := adapter.GetPriceHistory(historyUniqueId, suite.start, to)
+	assert.Nil(suite.T(), err)
+	assert.Len(suite.T(), points, 4)
+	assert.Equal(suite.T(), model.PriceVariantHolofoil, points[0].Variant)
+	assert.True(suite.T(), suite.start.Equal(points[0].RecordedAt))
+	assert.Equal(suite.T(), 4.0, points[0].MarketPrice)
+	assert.Equal(suite.T(), 20.0, points[0].HighPrice)
+	assert.Equal(suite.T(), 6.0, points[1].MarketPrice)
+	assert.Equal(suite.T(), model.PriceVariantNormal, points[2].Variant)
+	assert.Equal(suite.T(), 0.0, points[2].MarketPrice)
+
+	stats, err := adapter.GetPriceStats(historyUniqueId, suite.start, to)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []*model.PriceStats{
+		{Variant: model.PriceVariantHolofoil, Samples: 2, MinPrice: 4, MaxPrice: 6, AvgPrice: 5},
+		{Variant: model.PriceVariantNormal},
+	}, stats)
+
+	// Case: Nothing recorded in the range
+	points, err = adapter.GetPriceHistory(historyUniqueId, suite.start.AddDate(-1, 0, 0), suite.start)
+	assert.Nil(suite.T(), err)
+	assert.Empty(suite.T(), points)
+}
+
+func TestPriceHistoryAdapterTestSuite(t *testing.T) {
+	suite.Run(t, new(PriceHistoryAdapterTestSuite))
+}
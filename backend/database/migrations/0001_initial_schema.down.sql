@@ -0,0 +1,4 @@
+-- The pg_trgm extension is left installed as other database objects may use it.
+
+DROP TABLE IF EXISTS cards;
+DROP TABLE IF EXISTS api_tokens;
@@ -0,0 +1,101 @@
+-- Creates the schema as it stood when migrations moved into the backend.
+-- Databases set up from the old dbstruct.sql or dbseed.sql are upgraded in
+-- place, whichever of the old upgrade scripts had already been applied to them,
+-- so every statement is guarded to only make the changes still missing.
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE TABLE IF NOT EXISTS api_tokens (
+    token_id SERIAL PRIMARY KEY,
+    token_prefix VARCHAR(16) NOT NULL,
+    token_salt VARCHAR(64) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    scopes TEXT[] NOT NULL DEFAULT '{read,write}',
+    is_enabled BOOLEAN,
+    created_at TIMESTAMP,
+    expires_at TIMESTAMP,
+    last_used_at TIMESTAMP
+);
+
+-- Legacy tokens were stored in plaintext. They are replaced with salted SHA-256
+-- hashes, so the prefix length must match util.TokenPrefixLength and the hash
+-- must match util.HashToken.
+ALTER TABLE api_tokens
+    ADD COLUMN IF NOT EXISTS token_prefix VARCHAR(16),
+    ADD COLUMN IF NOT EXISTS token_salt VARCHAR(64),
+    ADD COLUMN IF NOT EXISTS token_hash VARCHAR(64),
+    ADD COLUMN IF NOT EXISTS scopes TEXT[] NOT NULL DEFAULT '{read,write}',
+    ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS last_used_at TIMESTAMP;
+
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = current_schema() AND table_name = 'api_tokens' AND column_name = 'token'
+    ) THEN
+        EXECUTE 'UPDATE api_tokens SET
+            token_prefix = LEFT(token, 8),
+            token_salt = MD5(RANDOM()::TEXT || CLOCK_TIMESTAMP()::TEXT || token_id::TEXT)
+        WHERE token_hash IS NULL';
+        EXECUTE 'UPDATE api_tokens SET
+            token_hash = ENCODE(SHA256(CONVERT_TO(token_salt || token, ''UTF8'')), ''hex'')
+        WHERE token_hash IS NULL';
+        ALTER TABLE api_tokens DROP COLUMN token;
+    END IF;
+END $$;
+
+ALTER TABLE api_tokens
+    ALTER COLUMN token_prefix SET NOT NULL,
+    ALTER COLUMN token_salt SET NOT NULL,
+    ALTER COLUMN token_hash SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS api_tokens_token_prefix_idx ON api_tokens (token_prefix);
+
+CREATE TABLE IF NOT EXISTS cards (
+    card_id SERIAL PRIMARY KEY,
+    owner_id INTEGER NOT NULL REFERENCES api_tokens(token_id) ON DELETE CASCADE,
+    card_unique_id VARCHAR(255),
+    card_pokemon TEXT,
+    card_image TEXT,
+    card_version INTEGER NOT NULL DEFAULT 1,
+    UNIQUE (owner_id, card_unique_id)
+);
+
+-- Legacy cards had no owner, so they are handed to the oldest token rather
+-- than lost, and their unique IDs become unique per owner
+ALTER TABLE cards
+    ADD COLUMN IF NOT EXISTS owner_id INTEGER REFERENCES api_tokens(token_id) ON DELETE CASCADE,
+    ADD COLUMN IF NOT EXISTS card_version INTEGER NOT NULL DEFAULT 1;
+
+-- Without any token there is nobody to hand them to, so the migration stops
+-- rather than failing on the NOT NULL below with a less helpful error
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM cards WHERE owner_id IS NULL)
+        AND NOT EXISTS (SELECT 1 FROM api_tokens)
+    THEN
+        RAISE EXCEPTION 'cards have no owner and there is no API token to hand them to: '
+            'insert the token that should own them into api_tokens, then run the migrations again';
+    END IF;
+END $$;
+
+UPDATE cards SET owner_id = (SELECT MIN(token_id) FROM api_tokens) WHERE owner_id IS NULL;
+
+ALTER TABLE cards ALTER COLUMN owner_id SET NOT NULL;
+ALTER TABLE cards DROP CONSTRAINT IF EXISTS cards_card_unique_id_key;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_constraint WHERE conname = 'cards_owner_id_card_unique_id_key'
+    ) THEN
+        ALTER TABLE cards ADD CONSTRAINT cards_owner_id_card_unique_id_key UNIQUE (owner_id, card_unique_id);
+    END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS cards_owner_pokemon_idx ON cards (owner_id, card_pokemon, card_id);
+CREATE INDEX IF NOT EXISTS cards_search_idx ON cards
+    USING GIN (to_tsvector('simple', coalesce(card_pokemon, '') || ' ' || coalesce(card_unique_id, '')));
+CREATE INDEX IF NOT EXISTS cards_pokemon_trgm_idx ON cards USING GIN (card_pokemon gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS cards_unique_id_trgm_idx ON cards USING GIN (card_unique_id gin_trgm_ops);
@@ -0,0 +1,6 @@
+ALTER TABLE cards
+    DROP COLUMN card_want_quantity,
+    DROP COLUMN card_have_quantity,
+    DROP COLUMN card_condition,
+    DROP COLUMN card_language,
+    DROP COLUMN card_finish;
@@ -0,0 +1,14 @@
+-- Tracks how many copies of each card are wanted and owned, and which printing.
+-- The allowed values must match model.CardConditions, model.CardLanguages and model.CardFinishes.
+
+ALTER TABLE cards
+    ADD COLUMN card_want_quantity INTEGER NOT NULL DEFAULT 1
+        CONSTRAINT cards_want_quantity_check CHECK (card_want_quantity >= 1),
+    ADD COLUMN card_have_quantity INTEGER NOT NULL DEFAULT 0
+        CONSTRAINT cards_have_quantity_check CHECK (card_have_quantity >= 0),
+    ADD COLUMN card_condition VARCHAR(3) NOT NULL DEFAULT 'NM'
+        CONSTRAINT cards_condition_check CHECK (card_condition IN ('NM', 'LP', 'MP', 'HP', 'DMG')),
+    ADD COLUMN card_language VARCHAR(2) NOT NULL DEFAULT 'EN'
+        CONSTRAINT cards_language_check CHECK (card_language IN ('EN', 'JA', 'KO', 'ZH', 'FR', 'DE', 'IT', 'ES', 'PT', 'NL', 'RU', 'PL')),
+    ADD COLUMN card_finish VARCHAR(16) NOT NULL DEFAULT 'normal'
+        CONSTRAINT cards_finish_check CHECK (card_finish IN ('normal', 'holo', 'reverse'));
@@ -0,0 +1,5 @@
+DROP TABLE price_alerts;
+
+ALTER TABLE cards
+    DROP COLUMN card_priority,
+    DROP COLUMN card_target_price;
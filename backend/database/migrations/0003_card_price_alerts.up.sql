@@ -0,0 +1,22 @@
+-- Adds a priority and an optional target price to each card, and the alerts
+-- raised when a card's market price falls below its target.
+-- The allowed priorities must match model.CardPriorities.
+
+ALTER TABLE cards
+    ADD COLUMN card_priority VARCHAR(8) NOT NULL DEFAULT 'medium'
+        CONSTRAINT cards_priority_check CHECK (card_priority IN ('low', 'medium', 'high')),
+    ADD COLUMN card_target_price NUMERIC(10, 2)
+        CONSTRAINT cards_target_price_check CHECK (card_target_price > 0);
+
+CREATE INDEX cards_target_price_idx ON cards (card_id) WHERE card_target_price IS NOT NULL;
+
+CREATE TABLE price_alerts (
+    alert_id SERIAL PRIMARY KEY,
+    card_id INTEGER NOT NULL REFERENCES cards(card_id) ON DELETE CASCADE,
+    alert_variant VARCHAR(64) NOT NULL,
+    alert_market_price NUMERIC(10, 2) NOT NULL,
+    alert_target_price NUMERIC(10, 2) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX price_alerts_card_idx ON price_alerts (card_id, created_at);
@@ -0,0 +1,15 @@
+-- Keeps snapshots of catalogue prices. Prices belong to the card printing rather
+-- than to anyone's wishlist, so they are keyed by unique ID and shared by owners.
+
+CREATE TABLE price_history (
+    history_id BIGSERIAL PRIMARY KEY,
+    card_unique_id VARCHAR(255) NOT NULL,
+    price_variant VARCHAR(64) NOT NULL,
+    price_low NUMERIC(10, 2),
+    price_mid NUMERIC(10, 2),
+    price_high NUMERIC(10, 2),
+    price_market NUMERIC(10, 2),
+    recorded_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX price_history_unique_id_idx ON price_history (card_unique_id, recorded_at);
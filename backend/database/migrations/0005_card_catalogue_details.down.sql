@@ -0,0 +1,6 @@
+ALTER TABLE cards
+    DROP COLUMN card_set_name,
+    DROP COLUMN card_rarity,
+    DROP COLUMN card_types,
+    DROP COLUMN card_number,
+    DROP COLUMN card_synced_at;
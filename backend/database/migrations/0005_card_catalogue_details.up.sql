@@ -0,0 +1,9 @@
+-- Adds the details copied from the TCG catalogue when a card is created or
+-- synced. They stay NULL for cards that have never been synced.
+
+ALTER TABLE cards
+    ADD COLUMN card_set_name VARCHAR(255),
+    ADD COLUMN card_rarity VARCHAR(64),
+    ADD COLUMN card_types TEXT[],
+    ADD COLUMN card_number VARCHAR(32),
+    ADD COLUMN card_synced_at TIMESTAMP;
@@ -0,0 +1 @@
+DROP INDEX price_history_snapshot_idx;
@@ -0,0 +1,12 @@
+-- Stops replicas that run the price snapshot job at the same time from
+-- recording the same prices twice. A snapshot is stamped with the start of the
+-- interval it was taken in, so a second one in that interval conflicts and is
+-- skipped.
+
+DELETE FROM price_history duplicate USING price_history kept
+WHERE duplicate.card_unique_id = kept.card_unique_id
+    AND duplicate.price_variant = kept.price_variant
+    AND duplicate.recorded_at = kept.recorded_at
+    AND duplicate.history_id > kept.history_id;
+
+CREATE UNIQUE INDEX price_history_snapshot_idx ON price_history (card_unique_id, price_variant, recorded_at);
@@ -0,0 +1,2 @@
+DROP INDEX cards_owner_pokemon_idx;
+CREATE INDEX cards_owner_pokemon_idx ON cards (owner_id, card_pokemon, card_id);
@@ -0,0 +1,5 @@
+-- Cards are sorted by name with missing names treated as empty, which the
+-- index on the bare column cannot serve.
+
+DROP INDEX IF EXISTS cards_owner_pokemon_idx;
+CREATE INDEX cards_owner_pokemon_idx ON cards (owner_id, COALESCE(card_pokemon, ''), card_id);
@@ -0,0 +1,30 @@
+-- Sample tokens and wishlist for local development, applied by `backend migrate seed`
+-- or SEED_DATABASE=true only while the database has no tokens.
+-- The prefix length must match util.TokenPrefixLength and the hash must match util.HashToken.
+
+INSERT INTO api_tokens (token_prefix, token_salt, token_hash, is_enabled, created_at)
+SELECT LEFT(seed.token, 8), seed.salt, ENCODE(SHA256(CONVERT_TO(seed.salt || seed.token, 'UTF8')), 'hex'), TRUE, NOW()
+FROM (
+    SELECT token, MD5(RANDOM()::TEXT || token) AS salt
+    FROM (VALUES
+        (1, 'cs3219tokena'),
+        (2, 'cs3219tokenb'),
+        (3, 'cs3219tokenc'),
+        (4, 'cs3219tokend')
+    ) AS plain(seq, token)
+    ORDER BY seq
+) AS seed;
+
+INSERT INTO cards (owner_id, card_unique_id, card_pokemon, card_image)
+SELECT (SELECT MIN(token_id) FROM api_tokens), card.unique_id, card.pokemon, card.image
+FROM (VALUES
+    ('xy1-1', 'Venusaur-EX', 'https://images.pokemontcg.io/xy1/1_hires.png'),
+    ('xy1-2', 'Mega Venusaur-EX', 'https://images.pokemontcg.io/xy1/2_hires.png'),
+    ('xy1-3', 'Weedle', 'https://images.pokemontcg.io/xy1/3_hires.png'),
+    ('xy1-15', 'Scatterbug', 'https://images.pokemontcg.io/xy1/15_hires.png'),
+    ('xy1-16', 'Spewpa', 'https://images.pokemontcg.io/xy1/16_hires.png'),
+    ('xy1-17', 'Vivillion', 'https://images.pokemontcg.io/xy1/17_hires.png'),
+    ('xy1-18', 'Skiddo', 'https://images.pokemontcg.io/xy1/18_hires.png'),
+    ('xy1-19', 'Gogoat', 'https://images.pokemontcg.io/xy1/19_hires.png'),
+    ('xy1-20', 'Slugma', 'https://images.pokemontcg.io/xy1/20_hires.png')
+) AS card(unique_id, pokemon, image);
@@ -8,19 +8,28 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 
 	"backend.cs3219.comp.nus.edu.sg/auth"
+	"backend.cs3219.comp.nus.edu.sg/catalogue"
 	"backend.cs3219.comp.nus.edu.sg/controller"
 	"backend.cs3219.comp.nus.edu.sg/database"
 	"backend.cs3219.comp.nus.edu.sg/model"
+	"backend.cs3219.comp.nus.edu.sg/pricing"
 	"backend.cs3219.comp.nus.edu.sg/server"
 	"backend.cs3219.comp.nus.edu.sg/util"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 )
 
+type cardListPage struct {
+	Items      []*model.Card `json:"items"`
+	NextCursor *string       `json:"nextCursor"`
+	Total      int           `json:"total"`
+}
+
 type E2ESuite struct {
 	suite.Suite
 	ctx        context.Context
@@ -29,10 +38,18 @@ type E2ESuite struct {
 	server     *http.Server
 	baseUrl    string
 
-	unauthHeader map[string][]string
-	authHeader   map[string][]string
+	priceProvider     *pricing.FakePriceProvider
+	catalogueProvider *catalogue.FakeCatalogueProvider
+
+	unauthHeader    map[string][]string
+	authHeader      map[string][]string
+	otherAuthHeader map[string][]string
+	readOnlyHeader  map[string][]string
+	adminHeader     map[string][]string
 }
 
+const E2E_ADMIN_TOKEN = "E2E-ADMIN"
+
 func (suite *E2ESuite) SetupSuite() {
 	suite.ctx = context.Background()
 	suite.unauthHeader = map[string][]string{
@@ -45,6 +62,21 @@ func (suite *E2ESuite) SetupSuite() {
 			fmt.Sprintf("Bearer BBB"),
 		},
 	}
+	suite.otherAuthHeader = map[string][]string{
+		"Authorization": {
+			fmt.Sprintf("Bearer CCC"),
+		},
+	}
+	suite.readOnlyHeader = map[string][]string{
+		"Authorization": {
+			fmt.Sprintf("Bearer RRR"),
+		},
+	}
+	suite.adminHeader = map[string][]string{
+		"Authorization": {
+			fmt.Sprintf("Bearer %s", E2E_ADMIN_TOKEN),
+		},
+	}
 	appConfig := util.LoadEnvVariables()
 	dbConn, err := database.ConnectDatabase(
 		appConfig.DbUrl,
@@ -57,10 +89,30 @@ func (suite *E2ESuite) SetupSuite() {
 		return
 	}
 
-	tokenAuthenticator := auth.NewTokenAuthenticator(dbConn)
+	migrator, err := database.NewMigrator(dbConn)
+	if err == nil {
+		_, err = migrator.Up()
+	}
+	if err != nil {
+		suite.T().Fatal("Failed to migrate database: ", err)
+		return
+	}
+
+	tokenAuthenticator := auth.NewCachedTokenAuthenticator(
+		auth.NewTokenAuthenticator(dbConn),
+		time.Minute,
+		time.Minute,
+		100,
+	)
 	server := server.CreateHTTPServer(uint16(appConfig.Port))
-	controller := controller.NewCardController(dbConn, tokenAuthenticator)
-	controller.Attach(server)
+	suite.priceProvider = pricing.NewFakePriceProvider()
+	suite.catalogueProvider = catalogue.NewFakeCatalogueProvider()
+	cardController := controller.NewCardController(dbConn, tokenAuthenticator, suite.priceProvider, suite.catalogueProvider)
+	cardController.Attach(server)
+	tokenController := controller.NewTokenController(dbConn, tokenAuthenticator, E2E_ADMIN_TOKEN)
+	tokenController.Attach(server)
+	priceAlertController := controller.NewPriceAlertController(dbConn, tokenAuthenticator)
+	priceAlertController.Attach(server)
 	suite.server = &http.Server{
 		Handler: server.GetRouter(),
 		Addr:    fmt.Sprintf(":%d", appConfig.Port),
@@ -71,19 +123,17 @@ func (suite *E2ESuite) SetupSuite() {
 		suite.server.ListenAndServe()
 	}()
 
-	suite.seedTokens = []*model.ApiToken{
-		{
-			Id:        1,
-			Token:     "BBB",
-			IsEnabled: true,
-			CreatedAt: time.Now(),
-		},
-		{
-			Id:        2,
-			Token:     "CCC",
-			IsEnabled: true,
-			CreatedAt: time.Now(),
-		},
+	suite.seedTokens = make([]*model.ApiToken, 0)
+	seedScopes := map[string][]string{
+		"BBB": auth.DefaultScopes,
+		"CCC": auth.DefaultScopes,
+		"RRR": {auth.ScopeRead},
+	}
+	for _, secret := range []string{"BBB", "CCC", "RRR"} {
+		token, err := auth.NewHashedApiToken(secret, seedScopes[secret])
+		assert.Nil(suite.T(), err)
+		token.CreatedAt = time.Now()
+		suite.seedTokens = append(suite.seedTokens, token)
 	}
 
 	suite.seedCards = []*model.Card{
@@ -116,10 +166,12 @@ func (suite *E2ESuite) SetupSuite() {
 	_, _ = dbConn.Conn.NewTruncateTable().Model(&model.ApiToken{}).Cascade().Exec(suite.ctx)
 	_, _ = dbConn.Conn.NewTruncateTable().Model(&model.Card{}).Cascade().Exec(suite.ctx)
 	for _, item := range suite.seedTokens {
-		_, err = dbConn.Conn.NewInsert().Model(item).ExcludeColumn("token_id").Exec(suite.ctx)
+		_, err = dbConn.Conn.NewInsert().Model(item).ExcludeColumn("token_id").Returning("token_id").Exec(suite.ctx)
 		assert.Nil(suite.T(), err)
 	}
 	for _, item := range suite.seedCards {
+		item.OwnerId = suite.seedTokens[0].Id
+		item.ApplyDefaults()
 		_, err = dbConn.Conn.NewInsert().Model(item).ExcludeColumn("card_id").Exec(suite.ctx)
 		assert.Nil(suite.T(), err)
 	}
@@ -139,8 +191,7 @@ func (suite *E2ESuite) Test_A_Get() {
 		suite.newRequest(http.MethodGet, "/api/card", nil, suite.authHeader),
 		200,
 	)
-	var cards []*model.Card
-	assert.Nil(suite.T(), suite.parseJSONResponse(resp, &cards))
+	cards := suite.parseCardList(resp)
 	assert.Equal(suite.T(), 4, len(cards))
 }
 
@@ -161,7 +212,10 @@ func (suite *E2ESuite) Test_B_GetSpecific() {
 	)
 	var card *model.Card
 	assert.Nil(suite.T(), suite.parseJSONResponse(resp, &card))
-	assert.Equal(suite.T(), suite.seedCards[0], card)
+	expectedCard := *suite.seedCards[0]
+	expectedCard.OwnerId = 0
+	expectedCard.Version = 1
+	assert.Equal(suite.T(), &expectedCard, card)
 }
 
 func (suite *E2ESuite) Test_C_Create() {
@@ -206,9 +260,25 @@ func (suite *E2ESuite) Test_C_Create() {
 	)
 
 	copy = *refCard
+	copy.UniqueId = "A1"
+	copy.HaveQuantity = -1
 	suite.launchRequest(
 		suite.newRequest(http.MethodPost, "/api/card", &copy, suite.authHeader),
-		403,
+		400,
+	)
+
+	copy = *refCard
+	copy.UniqueId = "A1"
+	copy.Condition = "Mint"
+	suite.launchRequest(
+		suite.newRequest(http.MethodPost, "/api/card", &copy, suite.authHeader),
+		400,
+	)
+
+	copy = *refCard
+	suite.launchRequest(
+		suite.newRequest(http.MethodPost, "/api/card", &copy, suite.authHeader),
+		409,
 	)
 
 	copy = *refCard
@@ -222,14 +292,18 @@ func (suite *E2ESuite) Test_C_Create() {
 	assert.Equal(suite.T(), copy.UniqueId, card.UniqueId)
 	assert.Equal(suite.T(), copy.Pokemon, card.Pokemon)
 	assert.Equal(suite.T(), copy.ImageUrl, card.ImageUrl)
+	assert.Equal(suite.T(), 1, card.WantQuantity)
+	assert.Equal(suite.T(), 0, card.HaveQuantity)
+	assert.Equal(suite.T(), model.CardConditionNearMint, card.Condition)
+	assert.Equal(suite.T(), model.CardLanguageEnglish, card.Language)
+	assert.Equal(suite.T(), model.CardFinishNormal, card.Finish)
 
 	// Check Records
 	resp = suite.launchRequest(
 		suite.newRequest(http.MethodGet, "/api/card", nil, suite.authHeader),
 		200,
 	)
-	var cards []*model.Card
-	assert.Nil(suite.T(), suite.parseJSONResponse(resp, &cards))
+	cards := suite.parseCardList(resp)
 	assert.Equal(suite.T(), 5, len(cards))
 }
 
@@ -285,7 +359,7 @@ func (suite *E2ESuite) Test_D_Edit() {
 	copy.UniqueId = "C2"
 	suite.launchRequest(
 		suite.newRequest(http.MethodPut, "/api/card/1", &copy, suite.authHeader),
-		403,
+		409,
 	)
 
 	copy = *refCard
@@ -340,11 +414,589 @@ func (suite *E2ESuite) Test_E_Delete() {
 		suite.newRequest(http.MethodGet, "/api/card", nil, suite.authHeader),
 		200,
 	)
-	var cards []*model.Card
-	assert.Nil(suite.T(), suite.parseJSONResponse(resp, &cards))
+	cards := suite.parseCardList(resp)
+	assert.Equal(suite.T(), 4, len(cards))
+}
+
+func (suite *E2ESuite) Test_F_OwnerIsolation() {
+	resp := suite.launchRequest(
+		suite.newRequest(http.MethodGet, "/api/card", nil, suite.otherAuthHeader),
+		200,
+	)
+	cards := suite.parseCardList(resp)
+	assert.Equal(suite.T(), 0, len(cards))
+
+	suite.launchRequest(
+		suite.newRequest(http.MethodGet, "/api/card/2", nil, suite.otherAuthHeader),
+		404,
+	)
+
+	otherCard := &model.Card{
+		Id:       2,
+		UniqueId: "C2",
+		Pokemon:  "Other",
+		ImageUrl: "http://example.com/other",
+	}
+	suite.launchRequest(
+		suite.newRequest(http.MethodPut, "/api/card/2", otherCard, suite.otherAuthHeader),
+		404,
+	)
+
+	suite.launchRequest(
+		suite.newRequest(http.MethodDelete, "/api/card/2", nil, suite.otherAuthHeader),
+		404,
+	)
+
+	// The same unique ID may live in both wishlists
+	otherCard.Id = 0
+	resp = suite.launchRequest(
+		suite.newRequest(http.MethodPost, "/api/card", otherCard, suite.otherAuthHeader),
+		200,
+	)
+	var card *model.Card
+	assert.Nil(suite.T(), suite.parseJSONResponse(resp, &card))
+	assert.Equal(suite.T(), "C2", card.UniqueId)
+
+	resp = suite.launchRequest(
+		suite.newRequest(http.MethodGet, "/api/card", nil, suite.otherAuthHeader),
+		200,
+	)
+	cards = suite.parseCardList(resp)
+	assert.Equal(suite.T(), 1, len(cards))
+	assert.Equal(suite.T(), "Other", cards[0].Pokemon)
+
+	// The original owner's card is untouched
+	resp = suite.launchRequest(
+		suite.newRequest(http.MethodGet, "/api/card/2", nil, suite.authHeader),
+		200,
+	)
+	assert.Nil(suite.T(), suite.parseJSONResponse(resp, &card))
+	assert.Equal(suite.T(), "BBB", card.Pokemon)
+
+	resp = suite.launchRequest(
+		suite.newRequest(http.MethodGet, "/api/card", nil, suite.authHeader),
+		200,
+	)
+	cards = suite.parseCardList(resp)
 	assert.Equal(suite.T(), 4, len(cards))
 }
 
+func (suite *E2ESuite) Test_G_TokenLifecycle() {
+	suite.launchRequest(
+		suite.newRequest(http.MethodPost, "/api/token", nil, suite.authHeader),
+		401,
+	)
+
+	resp := suite.launchRequest(
+		suite.newRequest(http.MethodPost, "/api/token", nil, suite.adminHeader),
+		200,
+	)
+	var minted struct {
+		Id    int    `json:"id"`
+		Token string `json:"token"`
+	}
+	assert.Nil(suite.T(), suite.parseJSONResponse(resp, &minted))
+	assert.NotEmpty(suite.T(), minted.Token)
+	mintedHeader := map[string][]string{
+		"Authorization": {
+			fmt.Sprintf("Bearer %s", minted.Token),
+		},
+	}
+	tokenEndpoint := fmt.Sprintf("/api/token/%d", minted.Id)
+
+	resp = suite.launchRequest(
+		suite.newRequest(http.MethodGet, "/api/card", nil, mintedHeader),
+		200,
+	)
+	cards := suite.parseCardList(resp)
+	assert.Equal(suite.T(), 0, len(cards))
+
+	resp = suite.launchRequest(
+		suite.newRequest(http.MethodGet, "/api/token", nil, suite.adminHeader),
+		200,
+	)
+	var tokens []*model.ApiToken
+	assert.Nil(suite.T(), suite.parseJSONResponse(resp, &tokens))
+	assert.Equal(suite.T(), len(suite.seedTokens)+1, len(tokens))
+
+	suite.launchRequest(
+		suite.newRequest(http.MethodPut, tokenEndpoint, map[string]bool{"isEnabled": false}, suite.adminHeader),
+		200,
+	)
+	suite.launchRequest(
+		suite.newRequest(http.MethodGet, "/api/card", nil, mintedHeader),
+		401,
+	)
+
+	suite.launchRequest(
+		suite.newRequest(http.MethodPut, tokenEndpoint, map[string]bool{"isEnabled": true}, suite.adminHeader),
+		200,
+	)
+	suite.launchRequest(
+		suite.newRequest(http.MethodGet, "/api/card", nil, mintedHeader),
+		200,
+	)
+
+	suite.launchRequest(
+		suite.newRequest(http.MethodDelete, tokenEndpoint, nil, suite.adminHeader),
+		200,
+	)
+	suite.launchRequest(
+		suite.newRequest(http.MethodGet, "/api/card", nil, mintedHeader),
+		401,
+	)
+	suite.launchRequest(
+		suite.newRequest(http.MethodDelete, tokenEndpoint, nil, suite.adminHeader),
+		404,
+	)
+}
+
+func (suite *E2ESuite) Test_H_ReadOnlyScope() {
+	suite.launchRequest(
+		suite.newRequest(http.MethodGet, "/api/card", nil, suite.readOnlyHeader),
+		200,
+	)
+
+	card := &model.Card{
+		UniqueId: "R1",
+		Pokemon:  "AAA",
+		ImageUrl: "http://example.com/r",
+	}
+	suite.launchRequest(
+		suite.newRequest(http.MethodPost, "/api/card", card, suite.readOnlyHeader),
+		403,
+	)
+	suite.launchRequest(
+		suite.newRequest(http.MethodDelete, "/api/card/1", nil, suite.readOnlyHeader),
+		403,
+	)
+	suite.launchRequest(
+		suite.newRequest(http.MethodGet, "/api/token", nil, suite.authHeader),
+		403,
+	)
+
+	resp := suite.launchRequest(
+		suite.newRequest(http.MethodPost, "/api/token", map[string][]string{"scopes": {auth.ScopeRead}}, suite.adminHeader),
+		200,
+	)
+	var minted struct {
+		Token  string   `json:"token"`
+		Scopes []string `json:"scopes"`
+	}
+	assert.Nil(suite.T(), suite.parseJSONResponse(resp, &minted))
+	assert.Equal(suite.T(), []string{auth.ScopeRead}, minted.Scopes)
+	mintedHeader := map[string][]string{
+		"Authorization": {
+			fmt.Sprintf("Bearer %s", minted.Token),
+		},
+	}
+	suite.launchRequest(
+		suite.newRequest(http.MethodPost, "/api/card", card, mintedHeader),
+		403,
+	)
+}
+
+func (suite *E2ESuite) Test_I_Pagination() {
+	suite.launchRequest(
+		suite.newRequest(http.MethodGet, "/api/card?limit=0", nil, suite.authHeader),
+		400,
+	)
+
+	seenIds := make(map[int]bool)
+	var page cardListPage
+	route := "/api/card?limit=2&sort=pokemon"
+	for {
+		resp := suite.launchRequest(
+			suite.newRequest(http.MethodGet, route, nil, suite.authHeader),
+			200,
+		)
+		page.NextCursor = nil
+		assert.Nil(suite.T(), suite.parseJSONResponse(resp, &page))
+		assert.LessOrEqual(suite.T(), len(page.Items), 2)
+		for _, card := range page.Items {
+			assert.False(suite.T(), seenIds[card.Id])
+			seenIds[card.Id] = true
+		}
+		if page.NextCursor == nil {
+			break
+		}
+		route = "/api/card?limit=2&sort=pokemon&cursor=" + *page.NextCursor
+	}
+	// Every card shows up exactly once across the pages
+	assert.Equal(suite.T(), page.Total, len(seenIds))
+
+	resp := suite.launchRequest(
+		suite.newRequest(http.MethodGet, "/api/card?pokemon=bb", nil, suite.authHeader),
+		200,
+	)
+	assert.Nil(suite.T(), suite.parseJSONResponse(resp, &page))
+	assert.Equal(suite.T(), len(page.Items), page.Total)
+	for _, card := range page.Items {
+		assert.Contains(suite.T(), strings.ToLower(card.Pokemon), "bb")
+	}
+}
+
+func (suite *E2ESuite) Test_J_Search() {
+	suite.launchRequest(
+		suite.newRequest(http.MethodGet, "/api/card/search?q=ddd", nil, suite.unauthHeader),
+		401,
+	)
+	suite.launchRequest(
+		suite.newRequest(http.MethodGet, "/api/card/search", nil, suite.authHeader),
+		400,
+	)
+
+	var result struct {
+		Items []*model.CardSearchResult `json:"items"`
+	}
+	resp := suite.launchRequest(
+		suite.newRequest(http.MethodGet, "/api/card/search?q=ddd", nil, suite.authHeader),
+		200,
+	)
+	assert.Nil(suite.T(), suite.parseJSONResponse(resp, &result))
+	assert.Equal(suite.T(), 1, len(result.Items))
+	assert.Equal(suite.T(), "<mark>DDD</mark>", result.Items[0].PokemonHighlight)
+
+	resp = suite.launchRequest(
+		suite.newRequest(http.MethodGet, "/api/card/search?q=ddd", nil, suite.otherAuthHeader),
+		200,
+	)
+	assert.Nil(suite.T(), suite.parseJSONResponse(resp, &result))
+	assert.Empty(suite.T(), result.Items)
+}
+
+func (suite *E2ESuite) Test_K_Patch() {
+	suite.launchRequest(
+		suite.newRequest(http.MethodPatch, "/api/card/4", map[string]string{"pokemon": "EEE"}, suite.readOnlyHeader),
+		403,
+	)
+
+	resp := suite.launchRequest(
+		suite.newRequest(http.MethodPatch, "/api/card/4", map[string]string{"imageUrl": "http://example.com/d2"}, suite.authHeader),
+		200,
+	)
+	var card *model.Card
+	assert.Nil(suite.T(), suite.parseJSONResponse(resp, &card))
+	assert.Equal(suite.T(), "DDD", card.Pokemon)
+	assert.Equal(suite.T(), "http://example.com/d2", card.ImageUrl)
+
+	jsonPatchHeader := map[string][]string{
+		"Authorization": suite.authHeader["Authorization"],
+		"Content-Type":  {"application/json-patch+json"},
+	}
+	suite.launchRequest(
+		suite.newRequest(http.MethodPatch, "/api/card/4", []map[string]string{
+			{"op": "test", "path": "/pokemon", "value": "EEE"},
+			{"op": "replace", "path": "/pokemon", "value": "FFF"},
+		}, jsonPatchHeader),
+		409,
+	)
+
+	resp = suite.launchRequest(
+		suite.newRequest(http.MethodGet, "/api/card/4", nil, suite.authHeader),
+		200,
+	)
+	assert.Nil(suite.T(), suite.parseJSONResponse(resp, &card))
+	assert.Equal(suite.T(), "DDD", card.Pokemon)
+	assert.Equal(suite.T(), "http://example.com/d2", card.ImageUrl)
+}
+
+func (suite *E2ESuite) Test_L_ConditionalRequests() {
+	resp := suite.launchRequest(
+		suite.newRequest(http.MethodGet, "/api/card/4", nil, suite.authHeader),
+		200,
+	)
+	etag := resp.Header.Get("ETag")
+	assert.NotEmpty(suite.T(), etag)
+
+	conditionalHeader := func(name string, value string) map[string][]string {
+		return map[string][]string{
+			"Authorization": suite.authHeader["Authorization"],
+			name:            {value},
+		}
+	}
+	suite.launchRequest(
+		suite.newRequest(http.MethodGet, "/api/card/4", nil, conditionalHeader("If-None-Match", etag)),
+		304,
+	)
+
+	resp = suite.launchRequest(
+		suite.newRequest(http.MethodPatch, "/api/card/4", map[string]string{"pokemon": "EEE"}, conditionalHeader("If-Match", etag)),
+		200,
+	)
+	newEtag := resp.Header.Get("ETag")
+	assert.NotEqual(suite.T(), etag, newEtag)
+
+	// The first writer moved the card on, so the old ETag is stale
+	suite.launchRequest(
+		suite.newRequest(http.MethodPatch, "/api/card/4", map[string]string{"pokemon": "FFF"}, conditionalHeader("If-Match", etag)),
+		412,
+	)
+	suite.launchRequest(
+		suite.newRequest(http.MethodDelete, "/api/card/4", nil, conditionalHeader("If-Match", etag)),
+		412,
+	)
+	suite.launchRequest(
+		suite.newRequest(http.MethodGet, "/api/card/4", nil, conditionalHeader("If-None-Match", etag)),
+		200,
+	)
+
+	resp = suite.launchRequest(
+		suite.newRequest(http.MethodGet, "/api/card", nil, suite.authHeader),
+		200,
+	)
+	suite.launchRequest(
+		suite.newRequest(http.MethodGet, "/api/card", nil, conditionalHeader("If-None-Match", resp.Header.Get("ETag"))),
+		304,
+	)
+}
+
+func (suite *E2ESuite) Test_M_Import() {
+	var result struct {
+		Created int `json:"created"`
+		Failed  int `json:"failed"`
+		Results []struct {
+			Status string `json:"status"`
+		} `json:"results"`
+	}
+	resp := suite.launchRequest(
+		suite.newRequest(http.MethodPost, "/api/card/import", []*model.Card{
+			{UniqueId: "M1", Pokemon: "Import A", ImageUrl: "http://example.com/m1"},
+			{UniqueId: "M1", Pokemon: "Import B", ImageUrl: "http://example.com/m1"},
+			{UniqueId: "M2", Pokemon: "Import C", ImageUrl: "notaurl"},
+		}, suite.authHeader),
+		200,
+	)
+	assert.Nil(suite.T(), suite.parseJSONResponse(resp, &result))
+	assert.Equal(suite.T(), 1, result.Created)
+	assert.Equal(suite.T(), 2, result.Failed)
+	assert.Equal(suite.T(), "conflict", result.Results[1].Status)
+	assert.Equal(suite.T(), "invalid", result.Results[2].Status)
+
+	csvRequest, err := http.NewRequest(
+		http.MethodPost,
+		suite.baseUrl+"/api/card/import",
+		strings.NewReader("uniqueId,pokemon,imageUrl\nM3,Import D,http://example.com/m3\n"),
+	)
+	assert.Nil(suite.T(), err)
+	csvRequest.Header = map[string][]string{
+		"Authorization": suite.authHeader["Authorization"],
+		"Content-Type":  {"text/csv"},
+	}
+	resp = suite.launchRequest(csvRequest, 200)
+	assert.Nil(suite.T(), suite.parseJSONResponse(resp, &result))
+	assert.Equal(suite.T(), 1, result.Created)
+
+	resp = suite.launchRequest(
+		suite.newRequest(http.MethodGet, "/api/card/search?q=import", nil, suite.authHeader),
+		200,
+	)
+	var searchResult struct {
+		Items []*model.CardSearchResult `json:"items"`
+	}
+	assert.Nil(suite.T(), suite.parseJSONResponse(resp, &searchResult))
+	assert.Equal(suite.T(), 2, len(searchResult.Items))
+}
+
+func (suite *E2ESuite) Test_N_Export() {
+	suite.launchRequest(
+		suite.newRequest(http.MethodGet, "/api/card/export?format=xml", nil, suite.authHeader),
+		400,
+	)
+
+	resp := suite.launchRequest(
+		suite.newRequest(http.MethodGet, "/api/card", nil, suite.authHeader),
+		200,
+	)
+	var page cardListPage
+	assert.Nil(suite.T(), suite.parseJSONResponse(resp, &page))
+
+	for format, expectedLines := range map[string]int{
+		"csv":   page.Total + 1,
+		"jsonl": page.Total,
+		"ptcgo": page.Total + 3,
+	} {
+		resp = suite.launchRequest(
+			suite.newRequest(http.MethodGet, "/api/card/export?format="+format, nil, suite.authHeader),
+			200,
+		)
+		body, err := io.ReadAll(resp.Body)
+		assert.Nil(suite.T(), err)
+		lines := strings.Split(strings.TrimSuffix(string(body), "\n"), "\n")
+		assert.Equal(suite.T(), expectedLines, len(lines), format)
+	}
+}
+
+func (suite *E2ESuite) Test_O_PriceAlerts() {
+	suite.launchRequest(
+		suite.newRequest(http.MethodGet, "/api/alerts", nil, suite.unauthHeader),
+		401,
+	)
+	suite.launchRequest(
+		suite.newRequest(http.MethodGet, "/api/alerts?cardId=abc", nil, suite.authHeader),
+		400,
+	)
+
+	// Target prices are kept, but no alert is raised without the price job
+	resp := suite.launchRequest(
+		suite.newRequest(http.MethodGet, "/api/card/1", nil, suite.authHeader),
+		200,
+	)
+	var card *model.Card
+	assert.Nil(suite.T(), suite.parseJSONResponse(resp, &card))
+	card.Priority = model.CardPriorityHigh
+	card.TargetPrice = 12.5
+	resp = suite.launchRequest(
+		suite.newRequest(http.MethodPut, "/api/card/1", card, suite.authHeader),
+		200,
+	)
+	var editedCard *model.Card
+	assert.Nil(suite.T(), suite.parseJSONResponse(resp, &editedCard))
+	assert.Equal(suite.T(), model.CardPriorityHigh, editedCard.Priority)
+	assert.Equal(suite.T(), 12.5, editedCard.TargetPrice)
+
+	resp = suite.launchRequest(
+		suite.newRequest(http.MethodGet, "/api/alerts", nil, suite.authHeader),
+		200,
+	)
+	var alerts struct {
+		Items []*model.PriceAlert `json:"items"`
+	}
+	assert.Nil(suite.T(), suite.parseJSONResponse(resp, &alerts))
+	assert.NotNil(suite.T(), alerts.Items)
+	assert.Empty(suite.T(), alerts.Items)
+}
+
+func (suite *E2ESuite) Test_P_PriceHistory() {
+	suite.launchRequest(
+		suite.newRequest(http.MethodGet, "/api/card/1/prices", nil, suite.unauthHeader),
+		401,
+	)
+	suite.launchRequest(
+		suite.newRequest(http.MethodGet, "/api/card/1/prices?from=2022-10-02&to=2022-10-01", nil, suite.authHeader),
+		400,
+	)
+	suite.launchRequest(
+		suite.newRequest(http.MethodGet, "/api/card/100/prices", nil, suite.authHeader),
+		404,
+	)
+
+	// Nothing is recorded without the price jobs
+	resp := suite.launchRequest(
+		suite.newRequest(http.MethodGet, "/api/card/1/prices?from=2022-10-01&to=2022-10-31", nil, suite.authHeader),
+		200,
+	)
+	var history struct {
+		UniqueId string        `json:"uniqueId"`
+		Variants []interface{} `json:"variants"`
+	}
+	assert.Nil(suite.T(), suite.parseJSONResponse(resp, &history))
+	assert.Equal(suite.T(), "C1", history.UniqueId)
+	assert.NotNil(suite.T(), history.Variants)
+	assert.Empty(suite.T(), history.Variants)
+}
+
+func (suite *E2ESuite) Test_Q_Price() {
+	suite.launchRequest(
+		suite.newRequest(http.MethodGet, "/api/card/1/price", nil, suite.unauthHeader),
+		401,
+	)
+	suite.launchRequest(
+		suite.newRequest(http.MethodGet, "/api/card/100/price", nil, suite.authHeader),
+		404,
+	)
+
+	suite.priceProvider.SetPrices("C1", &model.CardPrices{
+		UpdatedAt: "2022/10/01",
+		Prices: map[string]*model.PriceRange{
+			model.PriceVariantNormal: {LowPrice: 1, MidPrice: 2, HighPrice: 3, MarketPrice: 2.5},
+		},
+	})
+	resp := suite.launchRequest(
+		suite.newRequest(http.MethodGet, "/api/card/1/price", nil, suite.authHeader),
+		200,
+	)
+	var price struct {
+		UniqueId string                       `json:"uniqueId"`
+		Variant  *string                      `json:"variant"`
+		Prices   map[string]*model.PriceRange `json:"prices"`
+	}
+	assert.Nil(suite.T(), suite.parseJSONResponse(resp, &price))
+	assert.Equal(suite.T(), "C1", price.UniqueId)
+	assert.Equal(suite.T(), model.PriceVariantNormal, *price.Variant)
+	assert.Equal(suite.T(), 2.5, price.Prices[model.PriceVariantNormal].MarketPrice)
+
+	// Other owners cannot look up prices through the card
+	suite.launchRequest(
+		suite.newRequest(http.MethodGet, "/api/card/1/price", nil, suite.otherAuthHeader),
+		404,
+	)
+}
+
+func (suite *E2ESuite) Test_R_Catalogue() {
+	suite.catalogueProvider.SetCard(&model.CatalogueCard{
+		UniqueId: "xy1-1",
+		Name:     "Venusaur-EX",
+		ImageUrl: "https://images.pokemontcg.io/xy1/1_hires.png",
+		SetName:  "XY",
+		Rarity:   "Rare Holo EX",
+		Types:    []string{"Grass"},
+		Number:   "1",
+	})
+
+	// Cards missing from the catalogue still need a name and image
+	suite.launchRequest(
+		suite.newRequest(http.MethodPost, "/api/card", &model.Card{UniqueId: "xy1-999"}, suite.authHeader),
+		400,
+	)
+
+	resp := suite.launchRequest(
+		suite.newRequest(http.MethodPost, "/api/card", &model.Card{UniqueId: "xy1-1"}, suite.authHeader),
+		200,
+	)
+	var card *model.Card
+	assert.Nil(suite.T(), suite.parseJSONResponse(resp, &card))
+	assert.Equal(suite.T(), "Venusaur-EX", card.Pokemon)
+	assert.Equal(suite.T(), "https://images.pokemontcg.io/xy1/1_hires.png", card.ImageUrl)
+	assert.NotNil(suite.T(), card.SyncedAt)
+
+	// The catalogue details are stored with the card
+	resp = suite.launchRequest(
+		suite.newRequest(http.MethodGet, fmt.Sprintf("/api/card/%d", card.Id), nil, suite.authHeader),
+		200,
+	)
+	var storedCard *model.Card
+	assert.Nil(suite.T(), suite.parseJSONResponse(resp, &storedCard))
+	assert.Equal(suite.T(), "XY", storedCard.SetName)
+	assert.Equal(suite.T(), "Rare Holo EX", storedCard.Rarity)
+	assert.Equal(suite.T(), []string{"Grass"}, storedCard.Types)
+	assert.Equal(suite.T(), "1", storedCard.Number)
+
+	syncRoute := fmt.Sprintf("/api/card/%d/sync", card.Id)
+	suite.launchRequest(suite.newRequest(http.MethodPost, syncRoute, nil, suite.readOnlyHeader), 403)
+	suite.launchRequest(suite.newRequest(http.MethodPost, syncRoute, nil, suite.otherAuthHeader), 404)
+
+	suite.catalogueProvider.SetError(fmt.Errorf("unavailable"))
+	suite.launchRequest(suite.newRequest(http.MethodPost, syncRoute, nil, suite.authHeader), 502)
+	suite.catalogueProvider.SetError(nil)
+
+	suite.catalogueProvider.SetCard(&model.CatalogueCard{
+		UniqueId: "xy1-1",
+		Name:     "Venusaur EX",
+		ImageUrl: "https://images.pokemontcg.io/xy1/1_hires.png",
+		SetName:  "XY",
+		Rarity:   "Rare Holo EX",
+		Types:    []string{"Grass", "Psychic"},
+		Number:   "1",
+	})
+	resp = suite.launchRequest(suite.newRequest(http.MethodPost, syncRoute, nil, suite.authHeader), 200)
+	var syncedCard *model.Card
+	assert.Nil(suite.T(), suite.parseJSONResponse(resp, &syncedCard))
+	assert.Equal(suite.T(), "Venusaur EX", syncedCard.Pokemon)
+	assert.Equal(suite.T(), []string{"Grass", "Psychic"}, syncedCard.Types)
+	assert.Equal(suite.T(), storedCard.Version+1, syncedCard.Version)
+}
+
 func (suite *E2ESuite) launchRequest(req *http.Request, expectedStatus int) *http.Response {
 	httpClient := &http.Client{
 		Timeout: 5 * time.Second,
@@ -373,6 +1025,12 @@ func (suite *E2ESuite) assertResponseStatus(resp *http.Response, status int) {
 	assert.Equal(suite.T(), status, resp.StatusCode)
 }
 
+func (suite *E2ESuite) parseCardList(resp *http.Response) []*model.Card {
+	var page cardListPage
+	assert.Nil(suite.T(), suite.parseJSONResponse(resp, &page))
+	return page.Items
+}
+
 func (suite *E2ESuite) parseJSONResponse(resp *http.Response, container interface{}) error {
 	bodyData, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
@@ -2,14 +2,25 @@ package main
 
 import (
 	"log"
+	"os"
+	"time"
 
 	"backend.cs3219.comp.nus.edu.sg/auth"
+	"backend.cs3219.comp.nus.edu.sg/catalogue"
 	"backend.cs3219.comp.nus.edu.sg/controller"
 	"backend.cs3219.comp.nus.edu.sg/database"
+	"backend.cs3219.comp.nus.edu.sg/pricing"
 	"backend.cs3219.comp.nus.edu.sg/server"
 	"backend.cs3219.comp.nus.edu.sg/util"
 )
 
+const (
+	tokenUsageFlushInterval = 30 * time.Second
+	tokenCacheTtl           = 30 * time.Second
+	tokenCacheNegativeTtl   = 10 * time.Second
+	tokenCacheCapacity      = 1024
+)
+
 func main() {
 	appConfig := util.LoadEnvVariables()
 
@@ -23,11 +34,34 @@ func main() {
 		log.Fatalln("Failed to connect to database")
 	}
 
-	tokenAuthenticator := auth.NewTokenAuthenticator(dbConn)
+	migrator, err := database.NewMigrator(dbConn)
+	if err != nil {
+		log.Fatalln("Failed to load migrations:", err)
+	}
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrateCommand(migrator, os.Args[2:])
+		return
+	}
+	migrateDatabase(migrator, appConfig.SeedDatabase)
+
+	tokenAuthenticator := auth.NewUsageTrackingAuthenticator(
+		auth.NewCachedTokenAuthenticator(
+			auth.NewTokenAuthenticator(dbConn),
+			tokenCacheTtl,
+			tokenCacheNegativeTtl,
+			tokenCacheCapacity,
+		),
+		dbConn,
+		tokenUsageFlushInterval,
+	)
 
 	log.Println("Starting server")
 	server := server.CreateHTTPServer(uint16(appConfig.Port))
-	attachCardController(server, dbConn, tokenAuthenticator)
+	priceProvider := newPriceProvider(appConfig)
+	attachCardController(server, dbConn, tokenAuthenticator, priceProvider, newCatalogueProvider(appConfig))
+	attachTokenController(server, dbConn, tokenAuthenticator, appConfig.AdminToken)
+	attachPriceAlertController(server, dbConn, tokenAuthenticator)
+	startPriceJobs(dbConn, priceProvider, appConfig)
 	server.AddAssetRoute("/static/*filepath", "./static/static")
 	server.AddStaticRoute("/", "./static/index.html")
 	server.AddStaticRoute("/favicon.ico", "./static/favicon.ico")
@@ -40,7 +74,64 @@ func attachCardController(
 	server server.HTTPServer,
 	dbConnection *database.DatabaseConnection,
 	tokenAuthenticator auth.TokenAuthenticator,
+	priceProvider pricing.PriceProvider,
+	catalogueProvider catalogue.CatalogueProvider,
 ) {
-	controller := controller.NewCardController(dbConnection, tokenAuthenticator)
+	controller := controller.NewCardController(dbConnection, tokenAuthenticator, priceProvider, catalogueProvider)
 	controller.Attach(server)
 }
+
+func attachTokenController(
+	server server.HTTPServer,
+	dbConnection *database.DatabaseConnection,
+	tokenAuthenticator auth.TokenAuthenticator,
+	adminToken string,
+) {
+	if adminToken == "" {
+		log.Println("ADMIN_TOKEN is not set, token administration requires an admin scoped token")
+	}
+	controller := controller.NewTokenController(dbConnection, tokenAuthenticator, adminToken)
+	controller.Attach(server)
+}
+
+func attachPriceAlertController(
+	server server.HTTPServer,
+	dbConnection *database.DatabaseConnection,
+	tokenAuthenticator auth.TokenAuthenticator,
+) {
+	controller := controller.NewPriceAlertController(dbConnection, tokenAuthenticator)
+	controller.Attach(server)
+}
+
+func newPriceProvider(appConfig util.AppConfig) pricing.PriceProvider {
+	switch appConfig.PriceProvider {
+	case util.PriceProviderTcg:
+		return pricing.NewTcgPriceProvider(appConfig.TcgApiKey)
+	case util.PriceProviderCloudFunction:
+		return pricing.NewCloudFunctionPriceProvider(appConfig.PriceServiceUrl)
+	}
+	return nil
+}
+
+func newCatalogueProvider(appConfig util.AppConfig) catalogue.CatalogueProvider {
+	switch appConfig.CatalogueProvider {
+	case util.CatalogueProviderTcg:
+		return catalogue.NewTcgCatalogueProvider(appConfig.TcgApiKey)
+	case util.CatalogueProviderFake:
+		return catalogue.NewSampleCatalogueProvider()
+	}
+	return nil
+}
+
+func startPriceJobs(
+	dbConnection *database.DatabaseConnection,
+	priceProvider pricing.PriceProvider,
+	appConfig util.AppConfig,
+) {
+	if priceProvider == nil {
+		log.Println("No price provider is configured, prices will not be tracked or checked for alerts")
+		return
+	}
+	pricing.StartPriceAlertJob(dbConnection, priceProvider, appConfig.PriceAlertInterval)
+	pricing.StartPriceSnapshotJob(dbConnection, priceProvider, appConfig.PriceSnapshotInterval)
+}
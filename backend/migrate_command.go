@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"backend.cs3219.comp.nus.edu.sg/database"
+)
+
+const migrateUsage = "Usage: backend migrate [up | down [steps] | status | seed]"
+
+// migrateDatabase brings the schema up to date before the server starts
+func migrateDatabase(migrator *database.Migrator, seed bool) {
+	applied, err := migrator.Up()
+	if err != nil {
+		log.Fatalln("Failed to migrate database:", err)
+	}
+	for _, migration := range applied {
+		log.Printf("Applied migration %d_%s\n", migration.Version, migration.Name)
+	}
+
+	if seed {
+		seeded, err := migrator.Seed()
+		if err != nil {
+			log.Fatalln("Failed to seed database:", err)
+		}
+		if seeded {
+			log.Println("Loaded sample data into the empty database")
+		}
+	}
+}
+
+func runMigrateCommand(migrator *database.Migrator, args []string) {
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	switch command {
+	case "up":
+		migrateDatabase(migrator, false)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			parsedSteps, err := strconv.Atoi(args[1])
+			if err != nil || parsedSteps < 1 {
+				log.Fatalln(migrateUsage)
+			}
+			steps = parsedSteps
+		}
+		reverted, err := migrator.Down(steps)
+		if err != nil {
+			log.Fatalln("Failed to revert migrations:", err)
+		}
+		for _, migration := range reverted {
+			log.Printf("Reverted migration %d_%s\n", migration.Version, migration.Name)
+		}
+	case "status":
+		statuses, tableExists, err := migrator.Status()
+		if err != nil {
+			log.Fatalln("Failed to read migration status:", err)
+		}
+		if !tableExists {
+			fmt.Fprintln(os.Stdout, "No migrations table, so no migration has been applied")
+		}
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(os.Stdout, "%04d_%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+	case "seed":
+		migrateDatabase(migrator, true)
+	default:
+		log.Fatalln(migrateUsage)
+	}
+}
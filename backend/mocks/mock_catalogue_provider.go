@@ -0,0 +1,50 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: backend.cs3219.comp.nus.edu.sg/catalogue (interfaces: CatalogueProvider)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	model "backend.cs3219.comp.nus.edu.sg/model"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockCatalogueProvider is a mock of CatalogueProvider interface.
+type MockCatalogueProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockCatalogueProviderMockRecorder
+}
+
+// MockCatalogueProviderMockRecorder is the mock recorder for MockCatalogueProvider.
+type MockCatalogueProviderMockRecorder struct {
+	mock *MockCatalogueProvider
+}
+
+// NewMockCatalogueProvider creates a new mock instance.
+func NewMockCatalogueProvider(ctrl *gomock.Controller) *MockCatalogueProvider {
+	mock := &MockCatalogueProvider{ctrl: ctrl}
+	mock.recorder = &MockCatalogueProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCatalogueProvider) EXPECT() *MockCatalogueProviderMockRecorder {
+	return m.recorder
+}
+
+// GetCard mocks base method.
+func (m *MockCatalogueProvider) GetCard(arg0 string) (*model.CatalogueCard, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCard", arg0)
+	ret0, _ := ret[0].(*model.CatalogueCard)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCard indicates an expected call of GetCard.
+func (mr *MockCatalogueProviderMockRecorder) GetCard(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCard", reflect.TypeOf((*MockCatalogueProvider)(nil).GetCard), arg0)
+}
@@ -6,6 +6,7 @@ package mocks
 
 import (
 	reflect "reflect"
+	time "time"
 
 	model "backend.cs3219.comp.nus.edu.sg/model"
 	gomock "github.com/golang/mock/gomock"
@@ -35,11 +36,12 @@ func (m *MockDatabaseApiTokenAdapter) EXPECT() *MockDatabaseApiTokenAdapterMockR
 }
 
 // CreateApiToken mocks base method.
-func (m *MockDatabaseApiTokenAdapter) CreateApiToken(arg0 string) error {
+func (m *MockDatabaseApiTokenAdapter) CreateApiToken(arg0 *model.ApiToken) (*model.ApiToken, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreateApiToken", arg0)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(*model.ApiToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // CreateApiToken indicates an expected call of CreateApiToken.
@@ -77,19 +79,34 @@ func (mr *MockDatabaseApiTokenAdapterMockRecorder) GetAllApiTokens() *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllApiTokens", reflect.TypeOf((*MockDatabaseApiTokenAdapter)(nil).GetAllApiTokens))
 }
 
-// IsValidToken mocks base method.
-func (m *MockDatabaseApiTokenAdapter) IsValidToken(arg0 string) (bool, error) {
+// GetApiToken mocks base method.
+func (m *MockDatabaseApiTokenAdapter) GetApiToken(arg0 int) (*model.ApiToken, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "IsValidToken", arg0)
-	ret0, _ := ret[0].(bool)
+	ret := m.ctrl.Call(m, "GetApiToken", arg0)
+	ret0, _ := ret[0].(*model.ApiToken)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// IsValidToken indicates an expected call of IsValidToken.
-func (mr *MockDatabaseApiTokenAdapterMockRecorder) IsValidToken(arg0 interface{}) *gomock.Call {
+// GetApiToken indicates an expected call of GetApiToken.
+func (mr *MockDatabaseApiTokenAdapterMockRecorder) GetApiToken(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsValidToken", reflect.TypeOf((*MockDatabaseApiTokenAdapter)(nil).IsValidToken), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApiToken", reflect.TypeOf((*MockDatabaseApiTokenAdapter)(nil).GetApiToken), arg0)
+}
+
+// GetEnabledApiTokensByPrefix mocks base method.
+func (m *MockDatabaseApiTokenAdapter) GetEnabledApiTokensByPrefix(arg0 string) ([]*model.ApiToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEnabledApiTokensByPrefix", arg0)
+	ret0, _ := ret[0].([]*model.ApiToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEnabledApiTokensByPrefix indicates an expected call of GetEnabledApiTokensByPrefix.
+func (mr *MockDatabaseApiTokenAdapterMockRecorder) GetEnabledApiTokensByPrefix(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEnabledApiTokensByPrefix", reflect.TypeOf((*MockDatabaseApiTokenAdapter)(nil).GetEnabledApiTokensByPrefix), arg0)
 }
 
 // SetApiTokenState mocks base method.
@@ -105,3 +122,17 @@ func (mr *MockDatabaseApiTokenAdapterMockRecorder) SetApiTokenState(arg0, arg1 i
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetApiTokenState", reflect.TypeOf((*MockDatabaseApiTokenAdapter)(nil).SetApiTokenState), arg0, arg1)
 }
+
+// UpdateApiTokensLastUsed mocks base method.
+func (m *MockDatabaseApiTokenAdapter) UpdateApiTokensLastUsed(arg0 map[int]time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateApiTokensLastUsed", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateApiTokensLastUsed indicates an expected call of UpdateApiTokensLastUsed.
+func (mr *MockDatabaseApiTokenAdapterMockRecorder) UpdateApiTokensLastUsed(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateApiTokensLastUsed", reflect.TypeOf((*MockDatabaseApiTokenAdapter)(nil).UpdateApiTokensLastUsed), arg0)
+}
@@ -7,6 +7,7 @@ package mocks
 import (
 	reflect "reflect"
 
+	database "backend.cs3219.comp.nus.edu.sg/database"
 	model "backend.cs3219.comp.nus.edu.sg/model"
 	gomock "github.com/golang/mock/gomock"
 )
@@ -34,90 +35,197 @@ func (m *MockDatabaseCardAdapter) EXPECT() *MockDatabaseCardAdapterMockRecorder
 	return m.recorder
 }
 
+// CountCards mocks base method.
+func (m *MockDatabaseCardAdapter) CountCards(arg0 int) (*model.CardTotals, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountCards", arg0)
+	ret0, _ := ret[0].(*model.CardTotals)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountCards indicates an expected call of CountCards.
+func (mr *MockDatabaseCardAdapterMockRecorder) CountCards(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountCards", reflect.TypeOf((*MockDatabaseCardAdapter)(nil).CountCards), arg0)
+}
+
 // CreateCard mocks base method.
-func (m *MockDatabaseCardAdapter) CreateCard(arg0 *model.Card) (*model.Card, error) {
+func (m *MockDatabaseCardAdapter) CreateCard(arg0 int, arg1 *model.Card) (*model.Card, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateCard", arg0)
+	ret := m.ctrl.Call(m, "CreateCard", arg0, arg1)
 	ret0, _ := ret[0].(*model.Card)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateCard indicates an expected call of CreateCard.
-func (mr *MockDatabaseCardAdapterMockRecorder) CreateCard(arg0 interface{}) *gomock.Call {
+func (mr *MockDatabaseCardAdapterMockRecorder) CreateCard(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCard", reflect.TypeOf((*MockDatabaseCardAdapter)(nil).CreateCard), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCard", reflect.TypeOf((*MockDatabaseCardAdapter)(nil).CreateCard), arg0, arg1)
+}
+
+// CreateCardIfAbsent mocks base method.
+func (m *MockDatabaseCardAdapter) CreateCardIfAbsent(arg0 int, arg1 *model.Card) (*model.Card, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCardIfAbsent", arg0, arg1)
+	ret0, _ := ret[0].(*model.Card)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCardIfAbsent indicates an expected call of CreateCardIfAbsent.
+func (mr *MockDatabaseCardAdapterMockRecorder) CreateCardIfAbsent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCardIfAbsent", reflect.TypeOf((*MockDatabaseCardAdapter)(nil).CreateCardIfAbsent), arg0, arg1)
 }
 
 // DeleteCard mocks base method.
-func (m *MockDatabaseCardAdapter) DeleteCard(arg0 int) error {
+func (m *MockDatabaseCardAdapter) DeleteCard(arg0, arg1, arg2 int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteCard", arg0)
+	ret := m.ctrl.Call(m, "DeleteCard", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteCard indicates an expected call of DeleteCard.
-func (mr *MockDatabaseCardAdapterMockRecorder) DeleteCard(arg0 interface{}) *gomock.Call {
+func (mr *MockDatabaseCardAdapterMockRecorder) DeleteCard(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCard", reflect.TypeOf((*MockDatabaseCardAdapter)(nil).DeleteCard), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCard", reflect.TypeOf((*MockDatabaseCardAdapter)(nil).DeleteCard), arg0, arg1, arg2)
 }
 
 // EditCard mocks base method.
-func (m *MockDatabaseCardAdapter) EditCard(arg0 *model.Card) error {
+func (m *MockDatabaseCardAdapter) EditCard(arg0 int, arg1 *model.Card, arg2 ...string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "EditCard", arg0)
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "EditCard", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // EditCard indicates an expected call of EditCard.
-func (mr *MockDatabaseCardAdapterMockRecorder) EditCard(arg0 interface{}) *gomock.Call {
+func (mr *MockDatabaseCardAdapterMockRecorder) EditCard(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EditCard", reflect.TypeOf((*MockDatabaseCardAdapter)(nil).EditCard), arg0)
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EditCard", reflect.TypeOf((*MockDatabaseCardAdapter)(nil).EditCard), varargs...)
 }
 
 // GetAllCards mocks base method.
-func (m *MockDatabaseCardAdapter) GetAllCards() ([]*model.Card, error) {
+func (m *MockDatabaseCardAdapter) GetAllCards(arg0 int) ([]*model.Card, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllCards")
+	ret := m.ctrl.Call(m, "GetAllCards", arg0)
 	ret0, _ := ret[0].([]*model.Card)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAllCards indicates an expected call of GetAllCards.
-func (mr *MockDatabaseCardAdapterMockRecorder) GetAllCards() *gomock.Call {
+func (mr *MockDatabaseCardAdapterMockRecorder) GetAllCards(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllCards", reflect.TypeOf((*MockDatabaseCardAdapter)(nil).GetAllCards))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllCards", reflect.TypeOf((*MockDatabaseCardAdapter)(nil).GetAllCards), arg0)
 }
 
 // GetCard mocks base method.
-func (m *MockDatabaseCardAdapter) GetCard(arg0 int) (*model.Card, error) {
+func (m *MockDatabaseCardAdapter) GetCard(arg0, arg1 int) (*model.Card, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetCard", arg0)
+	ret := m.ctrl.Call(m, "GetCard", arg0, arg1)
 	ret0, _ := ret[0].(*model.Card)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetCard indicates an expected call of GetCard.
-func (mr *MockDatabaseCardAdapterMockRecorder) GetCard(arg0 interface{}) *gomock.Call {
+func (mr *MockDatabaseCardAdapterMockRecorder) GetCard(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCard", reflect.TypeOf((*MockDatabaseCardAdapter)(nil).GetCard), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCard", reflect.TypeOf((*MockDatabaseCardAdapter)(nil).GetCard), arg0, arg1)
 }
 
 // GetCardByUniqueId mocks base method.
-func (m *MockDatabaseCardAdapter) GetCardByUniqueId(arg0 string) (*model.Card, error) {
+func (m *MockDatabaseCardAdapter) GetCardByUniqueId(arg0 int, arg1 string) (*model.Card, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetCardByUniqueId", arg0)
+	ret := m.ctrl.Call(m, "GetCardByUniqueId", arg0, arg1)
 	ret0, _ := ret[0].(*model.Card)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetCardByUniqueId indicates an expected call of GetCardByUniqueId.
-func (mr *MockDatabaseCardAdapterMockRecorder) GetCardByUniqueId(arg0 interface{}) *gomock.Call {
+func (mr *MockDatabaseCardAdapterMockRecorder) GetCardByUniqueId(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCardByUniqueId", reflect.TypeOf((*MockDatabaseCardAdapter)(nil).GetCardByUniqueId), arg0, arg1)
+}
+
+// ListCards mocks base method.
+func (m *MockDatabaseCardAdapter) ListCards(arg0 int, arg1 *model.CardQuery) (*model.CardPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCards", arg0, arg1)
+	ret0, _ := ret[0].(*model.CardPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCards indicates an expected call of ListCards.
+func (mr *MockDatabaseCardAdapterMockRecorder) ListCards(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCards", reflect.TypeOf((*MockDatabaseCardAdapter)(nil).ListCards), arg0, arg1)
+}
+
+// RunInSnapshot mocks base method.
+func (m *MockDatabaseCardAdapter) RunInSnapshot(arg0 func(database.DatabaseCardAdapter) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunInSnapshot", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RunInSnapshot indicates an expected call of RunInSnapshot.
+func (mr *MockDatabaseCardAdapterMockRecorder) RunInSnapshot(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunInSnapshot", reflect.TypeOf((*MockDatabaseCardAdapter)(nil).RunInSnapshot), arg0)
+}
+
+// RunInTx mocks base method.
+func (m *MockDatabaseCardAdapter) RunInTx(arg0 func(database.DatabaseCardAdapter) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunInTx", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RunInTx indicates an expected call of RunInTx.
+func (mr *MockDatabaseCardAdapterMockRecorder) RunInTx(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunInTx", reflect.TypeOf((*MockDatabaseCardAdapter)(nil).RunInTx), arg0)
+}
+
+// SearchCards mocks base method.
+func (m *MockDatabaseCardAdapter) SearchCards(arg0 int, arg1 string, arg2 int) ([]*model.CardSearchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchCards", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*model.CardSearchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchCards indicates an expected call of SearchCards.
+func (mr *MockDatabaseCardAdapterMockRecorder) SearchCards(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchCards", reflect.TypeOf((*MockDatabaseCardAdapter)(nil).SearchCards), arg0, arg1, arg2)
+}
+
+// StreamCards mocks base method.
+func (m *MockDatabaseCardAdapter) StreamCards(arg0 int, arg1 func(*model.Card) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamCards", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamCards indicates an expected call of StreamCards.
+func (mr *MockDatabaseCardAdapterMockRecorder) StreamCards(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCardByUniqueId", reflect.TypeOf((*MockDatabaseCardAdapter)(nil).GetCardByUniqueId), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamCards", reflect.TypeOf((*MockDatabaseCardAdapter)(nil).StreamCards), arg0, arg1)
 }
@@ -0,0 +1,80 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: backend.cs3219.comp.nus.edu.sg/database (interfaces: DatabasePriceAlertAdapter)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	model "backend.cs3219.comp.nus.edu.sg/model"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockDatabasePriceAlertAdapter is a mock of DatabasePriceAlertAdapter interface.
+type MockDatabasePriceAlertAdapter struct {
+	ctrl     *gomock.Controller
+	recorder *MockDatabasePriceAlertAdapterMockRecorder
+}
+
+// MockDatabasePriceAlertAdapterMockRecorder is the mock recorder for MockDatabasePriceAlertAdapter.
+type MockDatabasePriceAlertAdapterMockRecorder struct {
+	mock *MockDatabasePriceAlertAdapter
+}
+
+// NewMockDatabasePriceAlertAdapter creates a new mock instance.
+func NewMockDatabasePriceAlertAdapter(ctrl *gomock.Controller) *MockDatabasePriceAlertAdapter {
+	mock := &MockDatabasePriceAlertAdapter{ctrl: ctrl}
+	mock.recorder = &MockDatabasePriceAlertAdapterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDatabasePriceAlertAdapter) EXPECT() *MockDatabasePriceAlertAdapterMockRecorder {
+	return m.recorder
+}
+
+// CreatePriceAlert mocks base method.
+func (m *MockDatabasePriceAlertAdapter) CreatePriceAlert(arg0 *model.PriceAlert) (*model.PriceAlert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePriceAlert", arg0)
+	ret0, _ := ret[0].(*model.PriceAlert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePriceAlert indicates an expected call of CreatePriceAlert.
+func (mr *MockDatabasePriceAlertAdapterMockRecorder) CreatePriceAlert(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePriceAlert", reflect.TypeOf((*MockDatabasePriceAlertAdapter)(nil).CreatePriceAlert), arg0)
+}
+
+// GetCardsWithTargetPrice mocks base method.
+func (m *MockDatabasePriceAlertAdapter) GetCardsWithTargetPrice() ([]*model.Card, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCardsWithTargetPrice")
+	ret0, _ := ret[0].([]*model.Card)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCardsWithTargetPrice indicates an expected call of GetCardsWithTargetPrice.
+func (mr *MockDatabasePriceAlertAdapterMockRecorder) GetCardsWithTargetPrice() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCardsWithTargetPrice", reflect.TypeOf((*MockDatabasePriceAlertAdapter)(nil).GetCardsWithTargetPrice))
+}
+
+// ListPriceAlerts mocks base method.
+func (m *MockDatabasePriceAlertAdapter) ListPriceAlerts(arg0 int, arg1 *model.PriceAlertQuery) ([]*model.PriceAlert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPriceAlerts", arg0, arg1)
+	ret0, _ := ret[0].([]*model.PriceAlert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPriceAlerts indicates an expected call of ListPriceAlerts.
+func (mr *MockDatabasePriceAlertAdapterMockRecorder) ListPriceAlerts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPriceAlerts", reflect.TypeOf((*MockDatabasePriceAlertAdapter)(nil).ListPriceAlerts), arg0, arg1)
+}
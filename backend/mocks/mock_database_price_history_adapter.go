@@ -0,0 +1,95 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: backend.cs3219.comp.nus.edu.sg/database (interfaces: DatabasePriceHistoryAdapter)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+	time "time"
+
+	model "backend.cs3219.comp.nus.edu.sg/model"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockDatabasePriceHistoryAdapter is a mock of DatabasePriceHistoryAdapter interface.
+type MockDatabasePriceHistoryAdapter struct {
+	ctrl     *gomock.Controller
+	recorder *MockDatabasePriceHistoryAdapterMockRecorder
+}
+
+// MockDatabasePriceHistoryAdapterMockRecorder is the mock recorder for MockDatabasePriceHistoryAdapter.
+type MockDatabasePriceHistoryAdapterMockRecorder struct {
+	mock *MockDatabasePriceHistoryAdapter
+}
+
+// NewMockDatabasePriceHistoryAdapter creates a new mock instance.
+func NewMockDatabasePriceHistoryAdapter(ctrl *gomock.Controller) *MockDatabasePriceHistoryAdapter {
+	mock := &MockDatabasePriceHistoryAdapter{ctrl: ctrl}
+	mock.recorder = &MockDatabasePriceHistoryAdapterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDatabasePriceHistoryAdapter) EXPECT() *MockDatabasePriceHistoryAdapterMockRecorder {
+	return m.recorder
+}
+
+// GetPriceHistory mocks base method.
+func (m *MockDatabasePriceHistoryAdapter) GetPriceHistory(arg0 string, arg1, arg2 time.Time) ([]*model.PricePoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPriceHistory", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*model.PricePoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPriceHistory indicates an expected call of GetPriceHistory.
+func (mr *MockDatabasePriceHistoryAdapterMockRecorder) GetPriceHistory(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPriceHistory", reflect.TypeOf((*MockDatabasePriceHistoryAdapter)(nil).GetPriceHistory), arg0, arg1, arg2)
+}
+
+// GetPriceStats mocks base method.
+func (m *MockDatabasePriceHistoryAdapter) GetPriceStats(arg0 string, arg1, arg2 time.Time) ([]*model.PriceStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPriceStats", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*model.PriceStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPriceStats indicates an expected call of GetPriceStats.
+func (mr *MockDatabasePriceHistoryAdapterMockRecorder) GetPriceStats(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPriceStats", reflect.TypeOf((*MockDatabasePriceHistoryAdapter)(nil).GetPriceStats), arg0, arg1, arg2)
+}
+
+// GetWantedUniqueIds mocks base method.
+func (m *MockDatabasePriceHistoryAdapter) GetWantedUniqueIds() ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWantedUniqueIds")
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWantedUniqueIds indicates an expected call of GetWantedUniqueIds.
+func (mr *MockDatabasePriceHistoryAdapterMockRecorder) GetWantedUniqueIds() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWantedUniqueIds", reflect.TypeOf((*MockDatabasePriceHistoryAdapter)(nil).GetWantedUniqueIds))
+}
+
+// RecordPrices mocks base method.
+func (m *MockDatabasePriceHistoryAdapter) RecordPrices(arg0 string, arg1 *model.CardPrices, arg2 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordPrices", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordPrices indicates an expected call of RecordPrices.
+func (mr *MockDatabasePriceHistoryAdapterMockRecorder) RecordPrices(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordPrices", reflect.TypeOf((*MockDatabasePriceHistoryAdapter)(nil).RecordPrices), arg0, arg1, arg2)
+}
@@ -0,0 +1,50 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: backend.cs3219.comp.nus.edu.sg/pricing (interfaces: PriceProvider)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	model "backend.cs3219.comp.nus.edu.sg/model"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockPriceProvider is a mock of PriceProvider interface.
+type MockPriceProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockPriceProviderMockRecorder
+}
+
+// MockPriceProviderMockRecorder is the mock recorder for MockPriceProvider.
+type MockPriceProviderMockRecorder struct {
+	mock *MockPriceProvider
+}
+
+// NewMockPriceProvider creates a new mock instance.
+func NewMockPriceProvider(ctrl *gomock.Controller) *MockPriceProvider {
+	mock := &MockPriceProvider{ctrl: ctrl}
+	mock.recorder = &MockPriceProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPriceProvider) EXPECT() *MockPriceProviderMockRecorder {
+	return m.recorder
+}
+
+// GetPrices mocks base method.
+func (m *MockPriceProvider) GetPrices(arg0 string) (*model.CardPrices, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPrices", arg0)
+	ret0, _ := ret[0].(*model.CardPrices)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPrices indicates an expected call of GetPrices.
+func (mr *MockPriceProviderMockRecorder) GetPrices(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPrices", reflect.TypeOf((*MockPriceProvider)(nil).GetPrices), arg0)
+}
@@ -7,6 +7,7 @@ package mocks
 import (
 	reflect "reflect"
 
+	model "backend.cs3219.comp.nus.edu.sg/model"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -33,16 +34,28 @@ func (m *MockTokenAuthenticator) EXPECT() *MockTokenAuthenticatorMockRecorder {
 	return m.recorder
 }
 
-// IsValidToken mocks base method.
-func (m *MockTokenAuthenticator) IsValidToken(arg0 string) bool {
+// Authenticate mocks base method.
+func (m *MockTokenAuthenticator) Authenticate(arg0 string) *model.Principal {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "IsValidToken", arg0)
-	ret0, _ := ret[0].(bool)
+	ret := m.ctrl.Call(m, "Authenticate", arg0)
+	ret0, _ := ret[0].(*model.Principal)
 	return ret0
 }
 
-// IsValidToken indicates an expected call of IsValidToken.
-func (mr *MockTokenAuthenticatorMockRecorder) IsValidToken(arg0 interface{}) *gomock.Call {
+// Authenticate indicates an expected call of Authenticate.
+func (mr *MockTokenAuthenticatorMockRecorder) Authenticate(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsValidToken", reflect.TypeOf((*MockTokenAuthenticator)(nil).IsValidToken), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockTokenAuthenticator)(nil).Authenticate), arg0)
+}
+
+// InvalidateToken mocks base method.
+func (m *MockTokenAuthenticator) InvalidateToken(arg0 int) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "InvalidateToken", arg0)
+}
+
+// InvalidateToken indicates an expected call of InvalidateToken.
+func (mr *MockTokenAuthenticatorMockRecorder) InvalidateToken(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidateToken", reflect.TypeOf((*MockTokenAuthenticator)(nil).InvalidateToken), arg0)
 }
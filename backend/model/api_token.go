@@ -3,8 +3,13 @@ package model
 import "time"
 
 type ApiToken struct {
-	Id        int       `bun:"token_id"`
-	Token     string    `bun:"token"`
-	IsEnabled bool      `bun:"is_enabled"`
-	CreatedAt time.Time `bun:"created_at"`
+	Id         int        `bun:"token_id" json:"id"`
+	Prefix     string     `bun:"token_prefix" json:"prefix"`
+	Salt       string     `bun:"token_salt" json:"-"`
+	Hash       string     `bun:"token_hash" json:"-"`
+	Scopes     []string   `bun:"scopes,array" json:"scopes"`
+	IsEnabled  bool       `bun:"is_enabled" json:"isEnabled"`
+	CreatedAt  time.Time  `bun:"created_at" json:"createdAt"`
+	ExpiresAt  *time.Time `bun:"expires_at" json:"expiresAt"`
+	LastUsedAt *time.Time `bun:"last_used_at" json:"lastUsedAt"`
 }
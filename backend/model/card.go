@@ -1,8 +1,188 @@
 package model
 
+import "time"
+
+// Card is a wishlist entry. TargetPrice is the most its owner will pay in USD,
+// with 0 meaning no target is set. The set, rarity, types and number are read
+// only, copied from the catalogue when the card was last synced at SyncedAt.
 type Card struct {
-	Id       int    `bun:"card_id" json:"id"`
-	UniqueId string `bun:"card_unique_id" json:"uniqueId"`
-	Pokemon  string `bun:"card_pokemon" json:"pokemon"`
-	ImageUrl string `bun:"card_image" json:"imageUrl"`
+	Id           int        `bun:"card_id" json:"id"`
+	OwnerId      int        `bun:"owner_id" json:"-"`
+	UniqueId     string     `bun:"card_unique_id" json:"uniqueId"`
+	Pokemon      string     `bun:"card_pokemon" json:"pokemon"`
+	ImageUrl     string     `bun:"card_image" json:"imageUrl"`
+	WantQuantity int        `bun:"card_want_quantity,nullzero" json:"wantQuantity"`
+	HaveQuantity int        `bun:"card_have_quantity" json:"haveQuantity"`
+	Condition    string     `bun:"card_condition,nullzero" json:"condition"`
+	Language     string     `bun:"card_language,nullzero" json:"language"`
+	Finish       string     `bun:"card_finish,nullzero" json:"finish"`
+	Priority     string     `bun:"card_priority,nullzero" json:"priority"`
+	TargetPrice  float64    `bun:"card_target_price,nullzero" json:"targetPrice"`
+	SetName      string     `bun:"card_set_name,nullzero" json:"setName"`
+	Rarity       string     `bun:"card_rarity,nullzero" json:"rarity"`
+	Types        []string   `bun:"card_types,array" json:"types"`
+	Number       string     `bun:"card_number,nullzero" json:"number"`
+	SyncedAt     *time.Time `bun:"card_synced_at" json:"syncedAt"`
+	Version      int        `bun:"card_version,nullzero" json:"version"`
+}
+
+// Editable card fields, named after their JSON keys
+const (
+	CardFieldUniqueId     = "uniqueId"
+	CardFieldPokemon      = "pokemon"
+	CardFieldImageUrl     = "imageUrl"
+	CardFieldWantQuantity = "wantQuantity"
+	CardFieldHaveQuantity = "haveQuantity"
+	CardFieldCondition    = "condition"
+	CardFieldLanguage     = "language"
+	CardFieldFinish       = "finish"
+	CardFieldPriority     = "priority"
+	CardFieldTargetPrice  = "targetPrice"
+)
+
+// CardEditableFields lists every editable field, in the order they are exported
+var CardEditableFields = []string{
+	CardFieldUniqueId,
+	CardFieldPokemon,
+	CardFieldImageUrl,
+	CardFieldWantQuantity,
+	CardFieldHaveQuantity,
+	CardFieldCondition,
+	CardFieldLanguage,
+	CardFieldFinish,
+	CardFieldPriority,
+	CardFieldTargetPrice,
+}
+
+// Card fields copied from the catalogue, which only a sync writes
+const (
+	CardFieldSetName  = "setName"
+	CardFieldRarity   = "rarity"
+	CardFieldTypes    = "types"
+	CardFieldNumber   = "number"
+	CardFieldSyncedAt = "syncedAt"
+)
+
+// CardCatalogueDetailFields lists the read only fields copied from the catalogue
+var CardCatalogueDetailFields = []string{
+	CardFieldSetName,
+	CardFieldRarity,
+	CardFieldTypes,
+	CardFieldNumber,
+	CardFieldSyncedAt,
+}
+
+// CardCatalogueFields lists every field a sync with the catalogue writes
+var CardCatalogueFields = []string{
+	CardFieldPokemon,
+	CardFieldImageUrl,
+	CardFieldSetName,
+	CardFieldRarity,
+	CardFieldTypes,
+	CardFieldNumber,
+	CardFieldSyncedAt,
+}
+
+// Card conditions, from best to worst
+const (
+	CardConditionNearMint         = "NM"
+	CardConditionLightlyPlayed    = "LP"
+	CardConditionModeratelyPlayed = "MP"
+	CardConditionHeavilyPlayed    = "HP"
+	CardConditionDamaged          = "DMG"
+)
+
+var CardConditions = []string{
+	CardConditionNearMint,
+	CardConditionLightlyPlayed,
+	CardConditionModeratelyPlayed,
+	CardConditionHeavilyPlayed,
+	CardConditionDamaged,
+}
+
+const (
+	CardFinishNormal  = "normal"
+	CardFinishHolo    = "holo"
+	CardFinishReverse = "reverse"
+)
+
+var CardFinishes = []string{
+	CardFinishNormal,
+	CardFinishHolo,
+	CardFinishReverse,
+}
+
+const (
+	CardPriorityLow    = "low"
+	CardPriorityMedium = "medium"
+	CardPriorityHigh   = "high"
+)
+
+var CardPriorities = []string{
+	CardPriorityLow,
+	CardPriorityMedium,
+	CardPriorityHigh,
+}
+
+const CardLanguageEnglish = "EN"
+
+// CardLanguages lists the languages cards are printed in
+var CardLanguages = []string{
+	CardLanguageEnglish, "JA", "KO", "ZH", "FR", "DE", "IT", "ES", "PT", "NL", "RU", "PL",
+}
+
+// ApplyDefaults fills in the quantity, printing and priority details left
+// unset, so a card given only its unique ID, name and image is one near mint
+// English copy of medium priority
+func (card *Card) ApplyDefaults() {
+	if card.WantQuantity == 0 {
+		card.WantQuantity = 1
+	}
+	if card.Condition == "" {
+		card.Condition = CardConditionNearMint
+	}
+	if card.Language == "" {
+		card.Language = CardLanguageEnglish
+	}
+	if card.Finish == "" {
+		card.Finish = CardFinishNormal
+	}
+	if card.Priority == "" {
+		card.Priority = CardPriorityMedium
+	}
+}
+
+// ApplyCatalogue copies the details of the card's catalogue entry. The name and
+// image are kept unless they are blank or overwrite is set.
+func (card *Card) ApplyCatalogue(entry *CatalogueCard, overwrite bool, syncedAt time.Time) {
+	if overwrite || card.Pokemon == "" {
+		card.Pokemon = entry.Name
+	}
+	if overwrite || card.ImageUrl == "" {
+		card.ImageUrl = entry.ImageUrl
+	}
+	card.SetName = entry.SetName
+	card.Rarity = entry.Rarity
+	card.Types = entry.Types
+	card.Number = entry.Number
+	card.SyncedAt = &syncedAt
+}
+
+// CopyCatalogueDetails copies the read only catalogue details of another card
+func (card *Card) CopyCatalogueDetails(from *Card) {
+	card.SetName = from.SetName
+	card.Rarity = from.Rarity
+	card.Types = from.Types
+	card.Number = from.Number
+	card.SyncedAt = from.SyncedAt
+}
+
+// ClearCatalogueDetails drops the read only catalogue details, which describe
+// a different card once the unique ID has changed
+func (card *Card) ClearCatalogueDetails() {
+	card.SetName = ""
+	card.Rarity = ""
+	card.Types = nil
+	card.Number = ""
+	card.SyncedAt = nil
 }
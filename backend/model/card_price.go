@@ -0,0 +1,64 @@
+package model
+
+import "time"
+
+// Price variants reported by TCGplayer, one per printing of a card
+const (
+	PriceVariantNormal          = "normal"
+	PriceVariantHolofoil        = "holofoil"
+	PriceVariantReverseHolofoil = "reverseHolofoil"
+)
+
+// CardPrices holds the current TCGplayer prices of a card in USD, keyed by
+// price variant
+type CardPrices struct {
+	UpdatedAt string                 `json:"updatedAt"`
+	Prices    map[string]*PriceRange `json:"prices"`
+}
+
+type PriceRange struct {
+	LowPrice    float64 `json:"low"`
+	MidPrice    float64 `json:"mid"`
+	HighPrice   float64 `json:"high"`
+	MarketPrice float64 `json:"market"`
+}
+
+// PriceAlert records a card's market price falling below its target price. The
+// card details are read along with the alert and are not stored on it.
+type PriceAlert struct {
+	Id          int       `bun:"alert_id" json:"id"`
+	CardId      int       `bun:"card_id" json:"cardId"`
+	Variant     string    `bun:"alert_variant" json:"variant"`
+	MarketPrice float64   `bun:"alert_market_price" json:"marketPrice"`
+	TargetPrice float64   `bun:"alert_target_price" json:"targetPrice"`
+	CreatedAt   time.Time `bun:"created_at" json:"createdAt"`
+
+	CardUniqueId string `bun:"card_unique_id" json:"cardUniqueId"`
+	CardPokemon  string `bun:"card_pokemon" json:"cardPokemon"`
+	CardPriority string `bun:"card_priority" json:"cardPriority"`
+}
+
+type PriceAlertQuery struct {
+	// CardId keeps only the alerts of one card when non-zero
+	CardId int
+	Limit  int
+}
+
+// PricePoint is one snapshot of a price variant
+type PricePoint struct {
+	Variant     string    `bun:"price_variant" json:"-"`
+	RecordedAt  time.Time `bun:"recorded_at" json:"recordedAt"`
+	LowPrice    float64   `bun:"price_low" json:"low"`
+	MidPrice    float64   `bun:"price_mid" json:"mid"`
+	HighPrice   float64   `bun:"price_high" json:"high"`
+	MarketPrice float64   `bun:"price_market" json:"market"`
+}
+
+// PriceStats summarises the market prices recorded for a price variant
+type PriceStats struct {
+	Variant  string  `bun:"price_variant" json:"variant"`
+	Samples  int     `bun:"samples" json:"samples"`
+	MinPrice float64 `bun:"min_price" json:"min"`
+	MaxPrice float64 `bun:"max_price" json:"max"`
+	AvgPrice float64 `bun:"avg_price" json:"avg"`
+}
@@ -0,0 +1,40 @@
+package model
+
+const (
+	CardSortId       = "id"
+	CardSortPokemon  = "pokemon"
+	CardSortUniqueId = "uniqueId"
+)
+
+// CardCursor marks the last card of a page, so the next page can resume
+// right after it in the requested sort order.
+type CardCursor struct {
+	SortValue string `json:"v"`
+	Id        int    `json:"id"`
+}
+
+type CardQuery struct {
+	Limit      int
+	Sort       string
+	Descending bool
+	After      *CardCursor
+
+	// Pokemon matches any card whose name contains it, ignoring case.
+	Pokemon string
+	// Set matches cards whose unique ID starts with "<Set>-".
+	Set string
+	// Missing keeps only cards with fewer copies owned than wanted.
+	Missing bool
+}
+
+type CardPage struct {
+	Items   []*Card
+	Total   int
+	HasMore bool
+}
+
+// CardTotals counts an owner's cards and the copies of them wanted in all
+type CardTotals struct {
+	Cards        int
+	WantQuantity int
+}
@@ -0,0 +1,9 @@
+package model
+
+type CardSearchResult struct {
+	Card
+	Rank float64 `bun:"rank" json:"rank"`
+	// Highlights are HTML-escaped, with matched terms wrapped in <mark> tags
+	PokemonHighlight  string `bun:"pokemon_highlight" json:"pokemonHighlight"`
+	UniqueIdHighlight string `bun:"unique_id_highlight" json:"uniqueIdHighlight"`
+}
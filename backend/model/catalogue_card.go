@@ -0,0 +1,12 @@
+package model
+
+// CatalogueCard is a card as listed in the TCG catalogue
+type CatalogueCard struct {
+	UniqueId string   `json:"uniqueId"`
+	Name     string   `json:"name"`
+	ImageUrl string   `json:"imageUrl"`
+	SetName  string   `json:"setName"`
+	Rarity   string   `json:"rarity"`
+	Types    []string `json:"types"`
+	Number   string   `json:"number"`
+}
@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+type Principal struct {
+	TokenId   int
+	Scopes    []string
+	CreatedAt time.Time
+	ExpiresAt *time.Time
+}
+
+func (principal *Principal) HasScope(scope string) bool {
+	for _, grantedScope := range principal.Scopes {
+		if grantedScope == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package pricing
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"backend.cs3219.comp.nus.edu.sg/model"
+)
+
+const cloudFunctionTimeout = 10 * time.Second
+
+type cloudFunctionRequest struct {
+	CardUniqueId string `json:"cardUniqueId"`
+}
+
+type cloudFunctionResponse struct {
+	ErrorMessage string            `json:"errorMessage"`
+	Data         *model.CardPrices `json:"data"`
+}
+
+// cloudFunctionPriceProvider reads prices through the GetPrice function in
+// serverless/, so the backend shares its API key and upstream handling
+type cloudFunctionPriceProvider struct {
+	url    string
+	client *http.Client
+}
+
+func NewCloudFunctionPriceProvider(url string) PriceProvider {
+	return &cloudFunctionPriceProvider{
+		url: url,
+		client: &http.Client{
+			Timeout: cloudFunctionTimeout,
+		},
+	}
+}
+
+func (provider *cloudFunctionPriceProvider) GetPrices(uniqueId string) (*model.CardPrices, error) {
+	body, err := json.Marshal(cloudFunctionRequest{CardUniqueId: uniqueId})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := provider.client.Post(provider.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+
+	var response cloudFunctionResponse
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	if err != nil {
+		return nil, fmt.Errorf("unreadable price response with status %d: %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("price lookup failed with status %d: %s", resp.StatusCode, response.ErrorMessage)
+	}
+	return response.Data, nil
+}
@@ -0,0 +1,69 @@
+package pricing
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"backend.cs3219.comp.nus.edu.sg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCloudFunctionPriceProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request cloudFunctionRequest
+		if r.Method != http.MethodPost || json.NewDecoder(r.Body).Decode(&request) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"errorMessage": "Bad Request Body", "data": null}`))
+			return
+		}
+
+		switch request.CardUniqueId {
+		case "xy1-1":
+			w.Write([]byte(`{"errorMessage": "", "data": {"updatedAt": "2022/10/01", "prices": {"holofoil": {"low": 5, "mid": 7.5, "high": 12, "market": 6.25}}}}`))
+		case "unknown":
+			w.Write([]byte(`{"errorMessage": "", "data": null}`))
+		case "missing":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errorMessage": "Card was not found", "data": null, "status": 404}`))
+		case "broken":
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte("<html></html>"))
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"errorMessage": "Downstream server could not process the request", "data": null}`))
+		}
+	}))
+	defer server.Close()
+
+	provider := NewCloudFunctionPriceProvider(server.URL)
+
+	// Case: Card with prices
+	prices, err := provider.GetPrices("xy1-1")
+	assert.Nil(t, err)
+	assert.Equal(t, &model.CardPrices{
+		UpdatedAt: "2022/10/01",
+		Prices: map[string]*model.PriceRange{
+			model.PriceVariantHolofoil: {LowPrice: 5, MidPrice: 7.5, HighPrice: 12, MarketPrice: 6.25},
+		},
+	}, prices)
+
+	// Case: Card without prices
+	prices, err = provider.GetPrices("unknown")
+	assert.Nil(t, err)
+	assert.Nil(t, prices)
+
+	// Case: Card unknown upstream
+	prices, err = provider.GetPrices("missing")
+	assert.Nil(t, err)
+	assert.Nil(t, prices)
+
+	// Case: Function reports an error
+	_, err = provider.GetPrices("xy1-2")
+	assert.ErrorContains(t, err, "Downstream server could not process the request")
+
+	// Case: Unreadable response
+	_, err = provider.GetPrices("broken")
+	assert.ErrorContains(t, err, "502")
+}
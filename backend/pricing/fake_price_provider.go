@@ -0,0 +1,43 @@
+package pricing
+
+import (
+	"sync"
+
+	"backend.cs3219.comp.nus.edu.sg/model"
+)
+
+// FakePriceProvider serves the prices set on it instead of asking a real
+// catalogue, for tests. Cards without prices set have none.
+type FakePriceProvider struct {
+	mutex  sync.RWMutex
+	prices map[string]*model.CardPrices
+	err    error
+}
+
+func NewFakePriceProvider() *FakePriceProvider {
+	return &FakePriceProvider{
+		prices: make(map[string]*model.CardPrices),
+	}
+}
+
+func (provider *FakePriceProvider) SetPrices(uniqueId string, prices *model.CardPrices) {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+	provider.prices[uniqueId] = prices
+}
+
+// SetError makes every lookup fail with err until it is set back to nil
+func (provider *FakePriceProvider) SetError(err error) {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+	provider.err = err
+}
+
+func (provider *FakePriceProvider) GetPrices(uniqueId string) (*model.CardPrices, error) {
+	provider.mutex.RLock()
+	defer provider.mutex.RUnlock()
+	if provider.err != nil {
+		return nil, provider.err
+	}
+	return provider.prices[uniqueId], nil
+}
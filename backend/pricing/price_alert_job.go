@@ -0,0 +1,85 @@
+package pricing
+
+import (
+	"log"
+	"time"
+
+	"backend.cs3219.comp.nus.edu.sg/database"
+	"backend.cs3219.comp.nus.edu.sg/model"
+)
+
+// priceAlertJob periodically prices every card with a target price, and
+// records an alert for each one whose market price has dropped below target
+type priceAlertJob struct {
+	db       database.DatabasePriceAlertAdapter
+	provider PriceProvider
+}
+
+func StartPriceAlertJob(
+	db *database.DatabaseConnection,
+	provider PriceProvider,
+	interval time.Duration,
+) {
+	job := newPriceAlertJob(database.NewDatabasePriceAlertAdapter(db), provider)
+	go job.checkPeriodically(interval)
+}
+
+func newPriceAlertJob(db database.DatabasePriceAlertAdapter, provider PriceProvider) *priceAlertJob {
+	return &priceAlertJob{
+		db:       db,
+		provider: provider,
+	}
+}
+
+func (job *priceAlertJob) checkPeriodically(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		job.check()
+	}
+}
+
+// check returns the alerts it recorded
+func (job *priceAlertJob) check() []*model.PriceAlert {
+	cards, err := job.db.GetCardsWithTargetPrice()
+	if err != nil {
+		log.Println("Failed to load cards for price alerts:", err)
+		return nil
+	}
+
+	// Owners may want the same card, which only needs pricing once
+	pricesByUniqueId := make(map[string]*model.CardPrices)
+	alerts := make([]*model.PriceAlert, 0)
+	for _, card := range cards {
+		prices, ok := pricesByUniqueId[card.UniqueId]
+		if !ok {
+			prices, err = job.provider.GetPrices(card.UniqueId)
+			if err != nil {
+				log.Printf("Failed to get prices for %s: %v", card.UniqueId, err)
+				continue
+			}
+			pricesByUniqueId[card.UniqueId] = prices
+		}
+
+		variant, priceRange := SelectPrice(card, prices)
+		if priceRange == nil || priceRange.MarketPrice <= 0 || priceRange.MarketPrice >= card.TargetPrice {
+			continue
+		}
+
+		alert, err := job.db.CreatePriceAlert(&model.PriceAlert{
+			CardId:      card.Id,
+			Variant:     variant,
+			MarketPrice: priceRange.MarketPrice,
+			TargetPrice: card.TargetPrice,
+		})
+		if err != nil {
+			log.Printf("Failed to record price alert for card %d: %v", card.Id, err)
+			continue
+		}
+		if alert != nil {
+			alerts = append(alerts, alert)
+		}
+	}
+	return alerts
+}
@@ -0,0 +1,97 @@
+package pricing
+
+import (
+	"errors"
+	"testing"
+
+	"backend.cs3219.comp.nus.edu.sg/mocks"
+	"backend.cs3219.comp.nus.edu.sg/model"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriceAlertJob(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	cards := []*model.Card{
+		{Id: 1, UniqueId: "xy1-1", Finish: model.CardFinishHolo, TargetPrice: 10},
+		{Id: 2, UniqueId: "xy1-1", Finish: model.CardFinishHolo, TargetPrice: 5},
+		{Id: 3, UniqueId: "xy1-2", Finish: model.CardFinishNormal, TargetPrice: 10},
+		{Id: 4, UniqueId: "xy1-3", Finish: model.CardFinishReverse, TargetPrice: 10},
+		{Id: 5, UniqueId: "xy1-4", Finish: model.CardFinishNormal, TargetPrice: 10},
+	}
+	alertAdapter := mocks.NewMockDatabasePriceAlertAdapter(mockCtrl)
+	gomock.InOrder(
+		alertAdapter.EXPECT().GetCardsWithTargetPrice().Return(nil, errors.New("Test error")),
+		alertAdapter.EXPECT().GetCardsWithTargetPrice().Return(cards, nil),
+	)
+
+	provider := mocks.NewMockPriceProvider(mockCtrl)
+	// xy1-1 is wanted twice but only priced once
+	provider.EXPECT().GetPrices("xy1-1").Return(&model.CardPrices{
+		Prices: map[string]*model.PriceRange{
+			model.PriceVariantNormal:   {MarketPrice: 1},
+			model.PriceVariantHolofoil: {MarketPrice: 8},
+		},
+	}, nil)
+	provider.EXPECT().GetPrices("xy1-2").Return(nil, errors.New("Test error"))
+	// Not priced for the wanted finish
+	provider.EXPECT().GetPrices("xy1-3").Return(&model.CardPrices{
+		Prices: map[string]*model.PriceRange{
+			model.PriceVariantNormal:   {MarketPrice: 1},
+			model.PriceVariantHolofoil: {MarketPrice: 2},
+		},
+	}, nil)
+	// Priced under a single variant, and already alerted at this price
+	provider.EXPECT().GetPrices("xy1-4").Return(&model.CardPrices{
+		Prices: map[string]*model.PriceRange{
+			"unlimitedHolofoil": {MarketPrice: 9},
+		},
+	}, nil)
+
+	firstAlert := &model.PriceAlert{CardId: 1, Variant: model.PriceVariantHolofoil, MarketPrice: 8, TargetPrice: 10}
+	gomock.InOrder(
+		alertAdapter.EXPECT().CreatePriceAlert(firstAlert).DoAndReturn(func(alert *model.PriceAlert) (*model.PriceAlert, error) {
+			created := *alert
+			created.Id = 11
+			return &created, nil
+		}),
+		alertAdapter.EXPECT().CreatePriceAlert(&model.PriceAlert{
+			CardId:      5,
+			Variant:     "unlimitedHolofoil",
+			MarketPrice: 9,
+			TargetPrice: 10,
+		}).Return(nil, nil),
+	)
+
+	job := newPriceAlertJob(alertAdapter, provider)
+
+	// Case: Cards could not be loaded
+	assert.Empty(t, job.check())
+
+	// Case: Only the first card is below its target for the first time
+	alerts := job.check()
+	assert.Len(t, alerts, 1)
+	assert.Equal(t, 11, alerts[0].Id)
+	assert.Equal(t, 1, alerts[0].CardId)
+}
+
+func TestSelectPrice(t *testing.T) {
+	prices := &model.CardPrices{
+		Prices: map[string]*model.PriceRange{
+			model.PriceVariantNormal:          {MarketPrice: 1},
+			model.PriceVariantReverseHolofoil: {MarketPrice: 3},
+		},
+	}
+
+	variant, priceRange := SelectPrice(&model.Card{Finish: model.CardFinishReverse}, prices)
+	assert.Equal(t, model.PriceVariantReverseHolofoil, variant)
+	assert.Equal(t, 3.0, priceRange.MarketPrice)
+
+	_, priceRange = SelectPrice(&model.Card{Finish: model.CardFinishHolo}, prices)
+	assert.Nil(t, priceRange)
+
+	_, priceRange = SelectPrice(&model.Card{Finish: model.CardFinishNormal}, nil)
+	assert.Nil(t, priceRange)
+}
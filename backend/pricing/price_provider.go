@@ -0,0 +1,39 @@
+package pricing
+
+import "backend.cs3219.comp.nus.edu.sg/model"
+
+//go:generate mockgen -destination=../mocks/mock_price_provider.go -build_flags=-mod=mod -package=mocks backend.cs3219.comp.nus.edu.sg/pricing PriceProvider
+type PriceProvider interface {
+	// GetPrices returns the current prices of the card with the given unique
+	// ID, or nil if no prices are known for it
+	GetPrices(uniqueId string) (*model.CardPrices, error)
+}
+
+// finishPriceVariants maps each card finish to the price variant it is sold as
+var finishPriceVariants = map[string]string{
+	model.CardFinishNormal:  model.PriceVariantNormal,
+	model.CardFinishHolo:    model.PriceVariantHolofoil,
+	model.CardFinishReverse: model.PriceVariantReverseHolofoil,
+}
+
+// SelectPrice picks the price of the variant matching the card's finish. Cards
+// printed only one way are priced under a single variant whatever it is named,
+// so that variant is used when it is the only one. It returns nil if neither
+// applies.
+func SelectPrice(card *model.Card, prices *model.CardPrices) (string, *model.PriceRange) {
+	if prices == nil {
+		return "", nil
+	}
+	variant := finishPriceVariants[card.Finish]
+	if priceRange := prices.Prices[variant]; priceRange != nil {
+		return variant, priceRange
+	}
+	if len(prices.Prices) == 1 {
+		for variant, priceRange := range prices.Prices {
+			if priceRange != nil {
+				return variant, priceRange
+			}
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,75 @@
+package pricing
+
+import (
+	"log"
+	"time"
+
+	"backend.cs3219.comp.nus.edu.sg/database"
+)
+
+// priceSnapshotJob periodically records the prices of every wanted card into
+// the price history
+type priceSnapshotJob struct {
+	db       database.DatabasePriceHistoryAdapter
+	provider PriceProvider
+}
+
+func StartPriceSnapshotJob(
+	db *database.DatabaseConnection,
+	provider PriceProvider,
+	interval time.Duration,
+) {
+	job := newPriceSnapshotJob(database.NewDatabasePriceHistoryAdapter(db), provider)
+	go job.snapshotPeriodically(interval)
+}
+
+func newPriceSnapshotJob(db database.DatabasePriceHistoryAdapter, provider PriceProvider) *priceSnapshotJob {
+	return &priceSnapshotJob{
+		db:       db,
+		provider: provider,
+	}
+}
+
+func (job *priceSnapshotJob) snapshotPeriodically(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		job.snapshot(snapshotTime(now, interval))
+	}
+}
+
+// snapshotTime stamps a snapshot with the start of the interval it is taken in,
+// so that replicas taking it at slightly different times record it only once
+func snapshotTime(now time.Time, interval time.Duration) time.Time {
+	return now.UTC().Truncate(interval)
+}
+
+// snapshot returns the number of cards whose prices it recorded
+func (job *priceSnapshotJob) snapshot(recordedAt time.Time) int {
+	uniqueIds, err := job.db.GetWantedUniqueIds()
+	if err != nil {
+		log.Println("Failed to load cards for price snapshots:", err)
+		return 0
+	}
+
+	recorded := 0
+	for _, uniqueId := range uniqueIds {
+		prices, err := job.provider.GetPrices(uniqueId)
+		if err != nil {
+			log.Printf("Failed to get prices for %s: %v", uniqueId, err)
+			continue
+		}
+		if prices == nil || len(prices.Prices) == 0 {
+			continue
+		}
+
+		err = job.db.RecordPrices(uniqueId, prices, recordedAt)
+		if err != nil {
+			log.Printf("Failed to record prices for %s: %v", uniqueId, err)
+			continue
+		}
+		recorded++
+	}
+	return recorded
+}
@@ -0,0 +1,62 @@
+package pricing
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"backend.cs3219.comp.nus.edu.sg/mocks"
+	"backend.cs3219.comp.nus.edu.sg/model"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriceSnapshotJob(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	recordedAt := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	historyAdapter := mocks.NewMockDatabasePriceHistoryAdapter(mockCtrl)
+	gomock.InOrder(
+		historyAdapter.EXPECT().GetWantedUniqueIds().Return(nil, errors.New("Test error")),
+		historyAdapter.EXPECT().GetWantedUniqueIds().Return([]string{"xy1-1", "xy1-2", "xy1-3", "xy1-4"}, nil),
+	)
+
+	xy1Prices := &model.CardPrices{
+		Prices: map[string]*model.PriceRange{
+			model.PriceVariantHolofoil: {MarketPrice: 8},
+		},
+	}
+	xy4Prices := &model.CardPrices{
+		Prices: map[string]*model.PriceRange{
+			model.PriceVariantNormal: {MarketPrice: 1},
+		},
+	}
+	provider := mocks.NewMockPriceProvider(mockCtrl)
+	provider.EXPECT().GetPrices("xy1-1").Return(xy1Prices, nil)
+	provider.EXPECT().GetPrices("xy1-2").Return(nil, errors.New("Test error"))
+	// Cards without prices are skipped
+	provider.EXPECT().GetPrices("xy1-3").Return(nil, nil)
+	provider.EXPECT().GetPrices("xy1-4").Return(xy4Prices, nil)
+
+	historyAdapter.EXPECT().RecordPrices("xy1-1", xy1Prices, recordedAt).Return(nil)
+	historyAdapter.EXPECT().RecordPrices("xy1-4", xy4Prices, recordedAt).Return(errors.New("Test error"))
+
+	job := newPriceSnapshotJob(historyAdapter, provider)
+
+	// Case: Cards could not be loaded
+	assert.Equal(t, 0, job.snapshot(recordedAt))
+
+	// Case: Only the first card is recorded
+	assert.Equal(t, 1, job.snapshot(recordedAt))
+}
+
+func TestSnapshotTime(t *testing.T) {
+	midnight := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	// Replicas snapshotting in the same interval record the same time
+	assert.Equal(t, midnight, snapshotTime(midnight.Add(5*time.Second), 24*time.Hour))
+	assert.Equal(t, midnight, snapshotTime(midnight.Add(23*time.Hour), 24*time.Hour))
+	assert.Equal(t, midnight.Add(time.Hour), snapshotTime(midnight.Add(90*time.Minute), time.Hour))
+	assert.Equal(t, midnight, snapshotTime(midnight.In(time.FixedZone("SGT", 8*60*60)), 24*time.Hour))
+}
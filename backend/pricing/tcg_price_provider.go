@@ -0,0 +1,85 @@
+package pricing
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"backend.cs3219.comp.nus.edu.sg/model"
+)
+
+const (
+	tcgApiUrl     = "https://api.pokemontcg.io/v2"
+	tcgApiTimeout = 5 * time.Second
+)
+
+type tcgApiResponse struct {
+	Data struct {
+		TcgPlayer struct {
+			UpdatedAt string                       `json:"updatedAt"`
+			Prices    map[string]*model.PriceRange `json:"prices"`
+		} `json:"tcgplayer"`
+	} `json:"data"`
+}
+
+// tcgPriceProvider reads TCGplayer prices from the pokemontcg.io catalogue
+type tcgPriceProvider struct {
+	baseUrl string
+	apiKey  string
+	client  *http.Client
+}
+
+// NewTcgPriceProvider creates a provider for pokemontcg.io. The API key is
+// optional, but requests without one are rate limited more heavily.
+func NewTcgPriceProvider(apiKey string) PriceProvider {
+	return newTcgPriceProvider(tcgApiUrl, apiKey)
+}
+
+func newTcgPriceProvider(baseUrl string, apiKey string) *tcgPriceProvider {
+	return &tcgPriceProvider{
+		baseUrl: baseUrl,
+		apiKey:  apiKey,
+		client: &http.Client{
+			Timeout: tcgApiTimeout,
+		},
+	}
+}
+
+func (provider *tcgPriceProvider) GetPrices(uniqueId string) (*model.CardPrices, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/cards/%s", provider.baseUrl, url.PathEscape(uniqueId)), nil)
+	if err != nil {
+		return nil, err
+	}
+	if provider.apiKey != "" {
+		req.Header.Set("X-Api-Key", provider.apiKey)
+	}
+
+	resp, err := provider.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Cards missing from the catalogue simply have no prices
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("pokemontcg.io responded with status %d", resp.StatusCode)
+	}
+
+	var response tcgApiResponse
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	if err != nil {
+		return nil, fmt.Errorf("unreadable pokemontcg.io response: %w", err)
+	}
+	if len(response.Data.TcgPlayer.Prices) == 0 {
+		return nil, nil
+	}
+	return &model.CardPrices{
+		UpdatedAt: response.Data.TcgPlayer.UpdatedAt,
+		Prices:    response.Data.TcgPlayer.Prices,
+	}, nil
+}
@@ -0,0 +1,62 @@
+package pricing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"backend.cs3219.comp.nus.edu.sg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTcgPriceProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != "KEY" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/cards/xy1-1":
+			w.Write([]byte(`{"data": {"id": "xy1-1", "tcgplayer": {"updatedAt": "2022/10/01", "prices": {"holofoil": {"low": 5, "mid": 7.5, "high": 12, "market": 6.25}}}}}`))
+		case "/cards/basep-1":
+			w.Write([]byte(`{"data": {"id": "basep-1"}}`))
+		case "/cards/broken":
+			w.Write([]byte("<html></html>"))
+		case "/cards/limited":
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	provider := newTcgPriceProvider(server.URL, "KEY")
+
+	// Case: Card with prices
+	prices, err := provider.GetPrices("xy1-1")
+	assert.Nil(t, err)
+	assert.Equal(t, &model.CardPrices{
+		UpdatedAt: "2022/10/01",
+		Prices: map[string]*model.PriceRange{
+			model.PriceVariantHolofoil: {LowPrice: 5, MidPrice: 7.5, HighPrice: 12, MarketPrice: 6.25},
+		},
+	}, prices)
+
+	// Case: Card without prices, or not in the catalogue
+	for _, uniqueId := range []string{"basep-1", "xy1-999"} {
+		prices, err = provider.GetPrices(uniqueId)
+		assert.Nil(t, err, uniqueId)
+		assert.Nil(t, prices, uniqueId)
+	}
+
+	// Case: Upstream failures
+	for _, uniqueId := range []string{"broken", "limited"} {
+		_, err = provider.GetPrices(uniqueId)
+		assert.NotNil(t, err, uniqueId)
+	}
+
+	// Case: Rejected API key
+	_, err = newTcgPriceProvider(server.URL, "WRONG").GetPrices("xy1-1")
+	assert.ErrorContains(t, err, "403")
+}
@@ -17,6 +17,7 @@ type HTTPServer interface {
 	Get(route string, handler HTTPHandler)
 	Post(route string, handler HTTPHandler)
 	Put(route string, handler HTTPHandler)
+	Patch(route string, handler HTTPHandler)
 	Delete(route string, handler HTTPHandler)
 }
 
@@ -66,6 +67,10 @@ func (server *httpServer) Put(route string, handler HTTPHandler) {
 	server.router.PUT(route, httprouter.Handle(handler))
 }
 
+func (server *httpServer) Patch(route string, handler HTTPHandler) {
+	server.router.PATCH(route, httprouter.Handle(handler))
+}
+
 func (server *httpServer) Delete(route string, handler HTTPHandler) {
 	server.router.DELETE(route, httprouter.Handle(handler))
 }
@@ -4,6 +4,24 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"time"
+)
+
+const (
+	PriceProviderTcg           = "pokemontcg"
+	PriceProviderCloudFunction = "cloudfunction"
+	PriceProviderNone          = "none"
+)
+
+const (
+	CatalogueProviderTcg  = "pokemontcg"
+	CatalogueProviderFake = "fake"
+	CatalogueProviderNone = "none"
+)
+
+const (
+	defaultPriceAlertInterval    = time.Hour
+	defaultPriceSnapshotInterval = 24 * time.Hour
 )
 
 type AppConfig struct {
@@ -13,10 +31,86 @@ type AppConfig struct {
 	DbName     string
 
 	Port int
+
+	AdminToken string
+
+	// SeedDatabase loads sample development data into an empty database at startup
+	SeedDatabase bool
+
+	// PriceProvider names where prices come from: pokemontcg, cloudfunction
+	// (the GetPrice function at PriceServiceUrl) or none
+	PriceProvider         string
+	TcgApiKey             string
+	PriceServiceUrl       string
+	PriceAlertInterval    time.Duration
+	PriceSnapshotInterval time.Duration
+
+	// CatalogueProvider names where card details are filled in from when cards
+	// are created or synced: pokemontcg, fake (the sample development cards
+	// only) or none
+	CatalogueProvider string
 }
 
 func LoadEnvVariables() AppConfig {
-	return loadDatabaseConfig()
+	config := loadDatabaseConfig()
+	config.AdminToken = os.Getenv("ADMIN_TOKEN")
+	config.SeedDatabase = os.Getenv("SEED_DATABASE") == "true"
+	config.PriceServiceUrl = os.Getenv("PRICE_SERVICE_URL")
+	config.TcgApiKey = os.Getenv("POKEMONTCG_API_KEY")
+	config.PriceProvider = loadPriceProvider(config.PriceServiceUrl)
+	config.CatalogueProvider = loadCatalogueProvider()
+
+	config.PriceAlertInterval = loadInterval("PRICE_ALERT_INTERVAL", defaultPriceAlertInterval)
+	config.PriceSnapshotInterval = loadInterval("PRICE_SNAPSHOT_INTERVAL", defaultPriceSnapshotInterval)
+	return config
+}
+
+// loadPriceProvider defaults to pokemontcg.io, unless only the GetPrice function
+// is configured as it was before prices moved into the backend
+func loadPriceProvider(priceServiceUrl string) string {
+	provider, found := os.LookupEnv("PRICE_PROVIDER")
+	if !found {
+		if priceServiceUrl != "" {
+			return PriceProviderCloudFunction
+		}
+		return PriceProviderTcg
+	}
+
+	switch provider {
+	case PriceProviderTcg, PriceProviderNone:
+	case PriceProviderCloudFunction:
+		if priceServiceUrl == "" {
+			log.Fatal("PRICE_SERVICE_URL must be set to use the cloudfunction price provider")
+		}
+	default:
+		log.Fatal("PRICE_PROVIDER must be pokemontcg, cloudfunction or none")
+	}
+	return provider
+}
+
+func loadCatalogueProvider() string {
+	provider, found := os.LookupEnv("CATALOGUE_PROVIDER")
+	if !found {
+		return CatalogueProviderTcg
+	}
+	switch provider {
+	case CatalogueProviderTcg, CatalogueProviderFake, CatalogueProviderNone:
+	default:
+		log.Fatal("CATALOGUE_PROVIDER must be pokemontcg, fake or none")
+	}
+	return provider
+}
+
+func loadInterval(name string, defaultInterval time.Duration) time.Duration {
+	intervalConfig, found := os.LookupEnv(name)
+	if !found {
+		return defaultInterval
+	}
+	interval, err := time.ParseDuration(intervalConfig)
+	if err != nil || interval <= 0 {
+		log.Fatalf("%s must be a positive duration, such as 30m", name)
+	}
+	return interval
 }
 
 func loadDatabaseConfig() AppConfig {
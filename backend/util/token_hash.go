@@ -0,0 +1,33 @@
+package util
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// TokenPrefixLength is the number of leading characters of a token kept in plaintext for lookups.
+const TokenPrefixLength = 8
+
+const tokenSaltByteLength = 16
+
+func NewTokenSalt() (string, error) {
+	saltBytes := make([]byte, tokenSaltByteLength)
+	_, err := rand.Read(saltBytes)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(saltBytes), nil
+}
+
+func HashToken(salt string, token string) string {
+	digest := sha256.Sum256([]byte(salt + token))
+	return hex.EncodeToString(digest[:])
+}
+
+func GetTokenPrefix(token string) string {
+	if len(token) <= TokenPrefixLength {
+		return token
+	}
+	return token[:TokenPrefixLength]
+}
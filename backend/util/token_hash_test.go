@@ -0,0 +1,32 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashToken(t *testing.T) {
+	// Must stay in sync with the SQL used to rehash legacy tokens
+	assert.Equal(t,
+		"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+		HashToken("", "test"),
+	)
+	assert.Equal(t, HashToken("te", "st"), HashToken("", "test"))
+	assert.NotEqual(t, HashToken("salt", "test"), HashToken("", "test"))
+}
+
+func TestNewTokenSalt(t *testing.T) {
+	saltA, err := NewTokenSalt()
+	assert.Nil(t, err)
+	assert.Equal(t, tokenSaltByteLength*2, len(saltA))
+
+	saltB, err := NewTokenSalt()
+	assert.Nil(t, err)
+	assert.NotEqual(t, saltA, saltB)
+}
+
+func TestGetTokenPrefix(t *testing.T) {
+	assert.Equal(t, "AAA", GetTokenPrefix("AAA"))
+	assert.Equal(t, "cs3219to", GetTokenPrefix("cs3219tokena"))
+}
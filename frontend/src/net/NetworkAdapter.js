@@ -30,17 +30,26 @@ class NetworkAdapter {
       return;
     }
 
-    const response = await fetch('/api/card', {
-      headers: {
-        Authorization: `Bearer ${this.apiKey}`,
-      },
-    });
-    const data = await response.json();
-    if (!data) {
-      return;
-    }
-
-    this.changeCallback(data.map((x) => new CardModel(x.id, x.uniqueId, x.pokemon, x.imageUrl)));
+    const cards = [];
+    let cursor = null;
+    do {
+      const query = cursor ? `?limit=200&cursor=${encodeURIComponent(cursor)}` : '?limit=200';
+      // eslint-disable-next-line no-await-in-loop
+      const response = await fetch(`/api/card${query}`, {
+        headers: {
+          Authorization: `Bearer ${this.apiKey}`,
+        },
+      });
+      // eslint-disable-next-line no-await-in-loop
+      const data = await response.json();
+      if (!data || !data.items) {
+        return;
+      }
+      cards.push(...data.items);
+      cursor = data.nextCursor;
+    } while (cursor);
+
+    this.changeCallback(cards.map((x) => new CardModel(x.id, x.uniqueId, x.pokemon, x.imageUrl)));
   }
 
   async netCreateCard(newCardModel) {
@@ -0,0 +1,97 @@
+package functions
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// maxBatchSize covers a whole wishlist page of the backend
+	maxBatchSize = 200
+	// maxConcurrentLookups bounds the upstream requests a batch makes at once
+	maxConcurrentLookups = 8
+	// maxBatchDuration bounds how long a batch takes, well within the function's
+	// 60 second timeout, however slow upstream is
+	maxBatchDuration = 25 * time.Second
+)
+
+// readBatchIds checks the IDs of a batch request and drops duplicates. It
+// returns the error message to report if the batch is invalid.
+func readBatchIds(cardIds []string) ([]string, string) {
+	if len(cardIds) == 0 || len(cardIds) > maxBatchSize {
+		return nil, fmt.Sprintf("Between 1 and %d card IDs must be given", maxBatchSize)
+	}
+
+	seen := make(map[string]bool, len(cardIds))
+	uniqueIds := make([]string, 0, len(cardIds))
+	for _, cardId := range cardIds {
+		if cardId == "" {
+			return nil, "Card IDs cannot be empty"
+		}
+		if !seen[cardId] {
+			seen[cardId] = true
+			uniqueIds = append(uniqueIds, cardId)
+		}
+	}
+	return uniqueIds, ""
+}
+
+// lookupBatch looks up a batch of cards, giving up on those it has not got to
+// once maxBatchDuration has passed
+func lookupBatch(ctx context.Context, apiToken string, cardIds []string, converter *priceConverter, now time.Time) map[string]*PriceResult {
+	ctx, cancel := context.WithTimeout(ctx, maxBatchDuration)
+	defer cancel()
+	return lookupPrices(ctx, apiToken, cardIds, converter, now)
+}
+
+// lookupPrices looks up every card, at most maxConcurrentLookups at a time.
+// Failures are reported per card, so one card cannot fail the whole batch.
+// Cards not yet looked up when ctx is done are reported as timed out, and
+// lookups in flight are cut short.
+func lookupPrices(ctx context.Context, apiToken string, cardIds []string, converter *priceConverter, now time.Time) map[string]*PriceResult {
+	results := make(map[string]*PriceResult, len(cardIds))
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, maxConcurrentLookups)
+
+	for _, cardId := range cardIds {
+		if !acquireSlot(ctx, slots) {
+			mutex.Lock()
+			results[cardId] = &PriceResult{
+				ErrorMessage: "The batch ran out of time before the card was looked up",
+				Status:       504,
+			}
+			mutex.Unlock()
+			continue
+		}
+
+		wg.Add(1)
+		go func(cardId string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			result, _ := lookupPrice(ctx, apiToken, cardId, converter, now)
+			mutex.Lock()
+			results[cardId] = result
+			mutex.Unlock()
+		}(cardId)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// acquireSlot waits for a free lookup slot, unless ctx is done first
+func acquireSlot(ctx context.Context, slots chan struct{}) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	select {
+	case slots <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
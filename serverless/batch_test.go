@@ -0,0 +1,191 @@
+package functions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadBatchIds(t *testing.T) {
+	manyIds := func(count int) []string {
+		cardIds := make([]string, count)
+		for i := range cardIds {
+			cardIds[i] = fmt.Sprintf("xy1-%d", i)
+		}
+		return cardIds
+	}
+
+	tests := []struct {
+		name        string
+		cardIds     []string
+		wantIds     []string
+		wantMessage bool
+	}{
+		{"single ID", []string{"xy1-1"}, []string{"xy1-1"}, false},
+		{"duplicates are dropped in order", []string{"xy1-2", "xy1-1", "xy1-2"}, []string{"xy1-2", "xy1-1"}, false},
+		{"largest batch", manyIds(maxBatchSize), manyIds(maxBatchSize), false},
+		{"too many IDs", manyIds(maxBatchSize + 1), nil, true},
+		{"no IDs", []string{}, nil, true},
+		{"empty ID", []string{"xy1-1", ""}, nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cardIds, message := readBatchIds(test.cardIds)
+			if (message != "") != test.wantMessage {
+				t.Fatalf("message = %q, want one: %v", message, test.wantMessage)
+			}
+			if !reflect.DeepEqual(cardIds, test.wantIds) {
+				t.Errorf("cardIds = %v, want %v", cardIds, test.wantIds)
+			}
+		})
+	}
+}
+
+func TestLookupPricesConcurrency(t *testing.T) {
+	upstream := newFakeUpstream(t)
+	cardIds := make([]string, 3*maxConcurrentLookups)
+	for i := range cardIds {
+		cardIds[i] = fmt.Sprintf("xy1-%d", i)
+		response := pricedCard("2022/10/01", 1.5)
+		response.delay = 20 * time.Millisecond
+		upstream.set(cardIds[i], response)
+	}
+
+	results := lookupPrices(context.Background(), "KEY", cardIds, nil, time.Now())
+
+	if len(results) != len(cardIds) {
+		t.Errorf("got %d results, want %d", len(results), len(cardIds))
+	}
+	for _, cardId := range cardIds {
+		if result := results[cardId]; result == nil || result.Data == nil {
+			t.Errorf("no prices for %s", cardId)
+		}
+	}
+	if upstream.maxActive > maxConcurrentLookups {
+		t.Errorf("%d lookups ran at once, want at most %d", upstream.maxActive, maxConcurrentLookups)
+	}
+}
+
+func TestLookupPricesErrors(t *testing.T) {
+	upstream := newFakeUpstream(t)
+	upstream.set("xy1-1", pricedCard("2022/10/01", 1.5))
+	upstream.set("xy1-2", fakeResponse{status: http.StatusServiceUnavailable})
+	upstream.set("xy1-3", fakeResponse{status: http.StatusOK, body: "<html></html>"})
+
+	results := lookupPrices(context.Background(), "KEY", []string{"xy1-1", "xy1-2", "xy1-3", "xy1-404"}, nil, time.Now())
+
+	tests := []struct {
+		cardId     string
+		wantStatus int
+	}{
+		{"xy1-1", 0},
+		{"xy1-2", 502},
+		{"xy1-3", 500},
+		{"xy1-404", 404},
+	}
+	for _, test := range tests {
+		t.Run(test.cardId, func(t *testing.T) {
+			result := results[test.cardId]
+			if result == nil {
+				t.Fatal("missing result")
+			}
+			if result.Status != test.wantStatus {
+				t.Errorf("status = %d, want %d", result.Status, test.wantStatus)
+			}
+			if (result.Data != nil) != (test.wantStatus == 0) || (result.ErrorMessage != "") != (test.wantStatus != 0) {
+				t.Errorf("unexpected result %+v", result)
+			}
+		})
+	}
+}
+
+func TestLookupPricesDeadline(t *testing.T) {
+	upstream := newFakeUpstream(t)
+	cardIds := make([]string, 4*maxConcurrentLookups)
+	for i := range cardIds {
+		cardIds[i] = fmt.Sprintf("xy1-%d", i)
+		response := pricedCard("2022/10/01", 1.5)
+		response.delay = 100 * time.Millisecond
+		upstream.set(cardIds[i], response)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	results := lookupPrices(ctx, "KEY", cardIds, nil, started)
+
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("batch took %v despite its deadline", elapsed)
+	}
+	if len(results) != len(cardIds) {
+		t.Fatalf("got %d results, want %d", len(results), len(cardIds))
+	}
+	priced, timedOut := 0, 0
+	for _, result := range results {
+		switch {
+		case result.Data != nil:
+			priced++
+		case result.Status == 504:
+			timedOut++
+		default:
+			t.Errorf("unexpected result %+v", result)
+		}
+	}
+	if priced < maxConcurrentLookups || timedOut == 0 {
+		t.Errorf("%d cards priced and %d timed out, want the first %d priced and the rest timed out", priced, timedOut, maxConcurrentLookups)
+	}
+}
+
+func TestGetPriceBatch(t *testing.T) {
+	upstream := newFakeUpstream(t)
+	upstream.set("xy1-1", pricedCard("2022/10/01", 1.5))
+	t.Setenv("API_KEY", "KEY")
+
+	tooMany := make([]string, maxBatchSize+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("xy1-%d", i)
+	}
+	tooManyBody, _ := json.Marshal(QueryRequest{CardUniqueIds: tooMany})
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantIds    []string
+	}{
+		{"batch with duplicates", `{"cardUniqueIds": ["xy1-1", "xy1-404", "xy1-1"]}`, 200, []string{"xy1-1", "xy1-404"}},
+		{"both a card and a batch", `{"cardUniqueId": "xy1-1", "cardUniqueIds": ["xy1-1"]}`, 400, nil},
+		{"empty batch", `{"cardUniqueIds": []}`, 400, nil},
+		{"too many IDs", string(tooManyBody), 400, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			GetPrice(recorder, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(test.body)))
+
+			if recorder.Code != test.wantStatus {
+				t.Fatalf("status = %d, want %d", recorder.Code, test.wantStatus)
+			}
+			var response QueryResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+				t.Fatal(err)
+			}
+			if len(response.Results) != len(test.wantIds) {
+				t.Errorf("got %d results, want %d", len(response.Results), len(test.wantIds))
+			}
+			for _, cardId := range test.wantIds {
+				if response.Results[cardId] == nil {
+					t.Errorf("missing result for %s", cardId)
+				}
+			}
+		})
+	}
+}
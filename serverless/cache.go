@@ -0,0 +1,116 @@
+package functions
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// TCGplayer prices are refreshed about once a day
+	tcgPriceUpdateInterval = 24 * time.Hour
+	tcgUpdatedAtLayout     = "2006/01/02"
+
+	minCacheTtl = 15 * time.Minute
+	maxCacheTtl = 12 * time.Hour
+	// Expired entries are still served for this long when upstream fails
+	maxStaleAge = 7 * 24 * time.Hour
+
+	maxCacheEntries = 5000
+)
+
+type priceCacheEntry struct {
+	data      *PriceData
+	fetchedAt time.Time
+	expiresAt time.Time
+}
+
+// priceCache keeps prices between invocations served by the same instance
+type priceCache struct {
+	mutex   sync.Mutex
+	entries map[string]*priceCacheEntry
+}
+
+var cache = newPriceCache()
+
+func newPriceCache() *priceCache {
+	return &priceCache{
+		entries: make(map[string]*priceCacheEntry),
+	}
+}
+
+// get returns the entry for the card, expired or not, unless it is too old to
+// serve even as a fallback
+func (cache *priceCache) get(cardId string, now time.Time) *priceCacheEntry {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	entry, ok := cache.entries[cardId]
+	if !ok {
+		return nil
+	}
+	if now.Sub(entry.expiresAt) > maxStaleAge {
+		delete(cache.entries, cardId)
+		return nil
+	}
+	return entry
+}
+
+func (cache *priceCache) put(cardId string, data *PriceData, now time.Time) *priceCacheEntry {
+	entry := &priceCacheEntry{
+		data:      data,
+		fetchedAt: now,
+		expiresAt: now.Add(cacheTtl(data, now)),
+	}
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if _, ok := cache.entries[cardId]; !ok && len(cache.entries) >= maxCacheEntries {
+		cache.evict(now)
+	}
+	cache.entries[cardId] = entry
+	return entry
+}
+
+// evict drops entries too old to serve, or if there are none, the entry that
+// expires first
+func (cache *priceCache) evict(now time.Time) {
+	var firstExpiring string
+	for cardId, entry := range cache.entries {
+		if now.Sub(entry.expiresAt) > maxStaleAge {
+			delete(cache.entries, cardId)
+		} else if firstExpiring == "" || entry.expiresAt.Before(cache.entries[firstExpiring].expiresAt) {
+			firstExpiring = cardId
+		}
+	}
+	if len(cache.entries) >= maxCacheEntries {
+		delete(cache.entries, firstExpiring)
+	}
+}
+
+// cacheTtl keeps prices until TCGplayer is next expected to update them. Prices
+// that are already overdue an update are kept briefly, so they are soon checked
+// again without calling upstream on every request.
+func cacheTtl(data *PriceData, now time.Time) time.Duration {
+	updatedAt, err := time.Parse(tcgUpdatedAtLayout, data.UpdatedAt)
+	if err != nil {
+		return minCacheTtl
+	}
+
+	ttl := updatedAt.Add(tcgPriceUpdateInterval).Sub(now)
+	if ttl < minCacheTtl {
+		return minCacheTtl
+	}
+	if ttl > maxCacheTtl {
+		return maxCacheTtl
+	}
+	return ttl
+}
+
+func (entry *priceCacheEntry) isFresh(now time.Time) bool {
+	return now.Before(entry.expiresAt)
+}
+
+func (entry *priceCacheEntry) age(now time.Time) int {
+	return int(now.Sub(entry.fetchedAt) / time.Second)
+}
@@ -0,0 +1,193 @@
+package functions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCacheTtl(t *testing.T) {
+	morning := time.Date(2022, 10, 1, 6, 0, 0, 0, time.UTC)
+	evening := time.Date(2022, 10, 1, 18, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		updatedAt string
+		now       time.Time
+		want      time.Duration
+	}{
+		{"next update is far off", "2022/10/01", morning, maxCacheTtl},
+		{"next update is due soon", "2022/10/01", evening, 6 * time.Hour},
+		{"update is overdue", "2022/09/29", evening, minCacheTtl},
+		{"update expected in a minute", "2022/10/01", time.Date(2022, 10, 1, 23, 59, 0, 0, time.UTC), minCacheTtl},
+		{"missing updatedAt", "", morning, minCacheTtl},
+		{"unreadable updatedAt", "2022-10-01", morning, minCacheTtl},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := cacheTtl(&PriceData{UpdatedAt: test.updatedAt}, test.now)
+			if got != test.want {
+				t.Errorf("cacheTtl() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestLookupPriceCache(t *testing.T) {
+	upstream := newFakeUpstream(t)
+	now := time.Date(2022, 10, 1, 6, 0, 0, 0, time.UTC)
+
+	// Each step runs after the previous one, moving the clock on by advance
+	steps := []struct {
+		name       string
+		advance    time.Duration
+		response   fakeResponse
+		wantStatus int
+		wantAge    int
+		wantStale  bool
+		wantFetch  bool
+	}{
+		{"first lookup fetches", 0, pricedCard("2022/10/01", 1.5), 200, 0, false, true},
+		{"fresh entry is served from the cache", time.Hour, fakeResponse{status: 500}, 200, 3600, false, false},
+		{"expired entry is served stale while upstream fails", 12 * time.Hour, fakeResponse{status: 500}, 200, 13 * 3600, true, true},
+		{"expired entry is refreshed once upstream recovers", time.Hour, pricedCard("2022/10/02", 2), 200, 0, false, true},
+		{"entry too old to serve stale is dropped", maxCacheTtl + maxStaleAge + time.Hour, fakeResponse{status: 500}, 502, 0, false, true},
+		{"lookup after the failure fetches again", time.Minute, pricedCard("2022/10/09", 2.5), 200, 0, false, true},
+		{"card removed upstream is not served stale", 13 * time.Hour, fakeResponse{status: 404}, 404, 0, false, true},
+	}
+
+	for _, step := range steps {
+		now = now.Add(step.advance)
+		upstream.set("xy1-1", step.response)
+		hitsBefore := upstream.hitCount("xy1-1")
+
+		result, status := lookupPrice(context.Background(), "KEY", "xy1-1", nil, now)
+		if status != step.wantStatus {
+			t.Fatalf("%s: status = %d, want %d", step.name, status, step.wantStatus)
+		}
+		if fetched := upstream.hitCount("xy1-1") > hitsBefore; fetched != step.wantFetch {
+			t.Errorf("%s: fetched = %v, want %v", step.name, fetched, step.wantFetch)
+		}
+		if status != 200 {
+			if result.Status != status || result.ErrorMessage == "" || result.Data != nil {
+				t.Errorf("%s: unexpected error result %+v", step.name, result)
+			}
+			continue
+		}
+		if result.CacheAge != step.wantAge || result.Stale != step.wantStale {
+			t.Errorf("%s: cacheAge = %d, stale = %v, want %d, %v", step.name, result.CacheAge, result.Stale, step.wantAge, step.wantStale)
+		}
+		if result.Data == nil || result.Data.Prices["normal"] == nil {
+			t.Errorf("%s: missing prices in %+v", step.name, result.Data)
+		}
+	}
+}
+
+func TestGetPriceCacheAge(t *testing.T) {
+	upstream := newFakeUpstream(t)
+	upstream.set("xy1-1", pricedCard("2022/10/01", 1.5))
+	t.Setenv("API_KEY", "KEY")
+	now := time.Date(2022, 10, 1, 6, 0, 0, 0, time.UTC)
+	fixClock(t, func() time.Time { return now })
+
+	tests := []struct {
+		name    string
+		advance time.Duration
+		wantAge string
+	}{
+		{"fetched from upstream", 0, "0"},
+		{"served from the cache", 90 * time.Second, "90"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			now = now.Add(test.advance)
+			recorder := httptest.NewRecorder()
+			GetPrice(recorder, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"cardUniqueId": "xy1-1"}`)))
+
+			if recorder.Code != 200 {
+				t.Fatalf("status = %d, want 200", recorder.Code)
+			}
+			if age := recorder.Header().Get("Age"); age != test.wantAge {
+				t.Errorf("Age = %q, want %q", age, test.wantAge)
+			}
+			var response QueryResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+				t.Fatal(err)
+			}
+			if fmt.Sprint(response.CacheAge) != test.wantAge || response.Stale {
+				t.Errorf("cacheAge = %d, stale = %v, want %s, false", response.CacheAge, response.Stale, test.wantAge)
+			}
+		})
+	}
+	if hits := upstream.hitCount("xy1-1"); hits != 1 {
+		t.Errorf("upstream was called %d times, want 1", hits)
+	}
+}
+
+func TestPriceCacheEviction(t *testing.T) {
+	now := time.Date(2022, 10, 1, 6, 0, 0, 0, time.UTC)
+	data := &PriceData{}
+
+	t.Run("entry expiring first is evicted when full", func(t *testing.T) {
+		cache := newPriceCache()
+		for i := 0; i < maxCacheEntries; i++ {
+			cache.put(fmt.Sprintf("card-%d", i), data, now.Add(time.Duration(i)*time.Second))
+		}
+		cache.put("card-new", data, now.Add(time.Hour))
+
+		if len(cache.entries) != maxCacheEntries {
+			t.Errorf("cache holds %d entries, want %d", len(cache.entries), maxCacheEntries)
+		}
+		if cache.get("card-0", now) != nil {
+			t.Error("card-0 expires first but was kept")
+		}
+		if cache.get("card-1", now) == nil || cache.get("card-new", now) == nil {
+			t.Error("entries other than card-0 were evicted")
+		}
+	})
+
+	t.Run("replacing an entry evicts nothing", func(t *testing.T) {
+		cache := newPriceCache()
+		for i := 0; i < maxCacheEntries; i++ {
+			cache.put(fmt.Sprintf("card-%d", i), data, now)
+		}
+		cache.put("card-0", data, now.Add(time.Minute))
+
+		if len(cache.entries) != maxCacheEntries {
+			t.Errorf("cache holds %d entries, want %d", len(cache.entries), maxCacheEntries)
+		}
+	})
+
+	t.Run("entries too old to serve are evicted first", func(t *testing.T) {
+		cache := newPriceCache()
+		for i := 0; i < maxCacheEntries; i++ {
+			cache.put(fmt.Sprintf("card-%d", i), data, now)
+		}
+		cache.put("card-new", data, now.Add(minCacheTtl+maxStaleAge+time.Hour))
+
+		if len(cache.entries) != 1 {
+			t.Errorf("cache holds %d entries, want only the new one", len(cache.entries))
+		}
+	})
+
+	t.Run("entry too old to serve is dropped on read", func(t *testing.T) {
+		cache := newPriceCache()
+		cache.put("card-0", data, now)
+
+		if cache.get("card-0", now.Add(minCacheTtl+maxStaleAge)) == nil {
+			t.Error("entry was dropped while it could still be served stale")
+		}
+		if cache.get("card-0", now.Add(minCacheTtl+maxStaleAge+time.Second)) != nil {
+			t.Error("entry too old to serve was returned")
+		}
+		if len(cache.entries) != 0 {
+			t.Error("entry too old to serve was kept")
+		}
+	})
+}
@@ -0,0 +1,99 @@
+package functions
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+)
+
+const (
+	currencyUsd = "USD"
+	currencyEur = "EUR"
+)
+
+// currencyRates maps a currency code to its units per US dollar
+type currencyRates map[string]float64
+
+// loadCurrencyRates reads the EXCHANGE_RATES table, given as JSON such as
+// {"EUR": 0.95, "SGD": 1.36}. US dollars are always supported.
+func loadCurrencyRates() (currencyRates, error) {
+	rates := currencyRates{currencyUsd: 1}
+	value := os.Getenv("EXCHANGE_RATES")
+	if value == "" {
+		return rates, nil
+	}
+
+	var configured map[string]float64
+	err := json.Unmarshal([]byte(value), &configured)
+	if err != nil {
+		return nil, fmt.Errorf("EXCHANGE_RATES is not a JSON object: %w", err)
+	}
+	for currency, rate := range configured {
+		if rate <= 0 {
+			return nil, fmt.Errorf("EXCHANGE_RATES has an invalid rate for %s", currency)
+		}
+		rates[strings.ToUpper(currency)] = rate
+	}
+	return rates, nil
+}
+
+// priceConverter converts prices into the currency requested by the caller
+type priceConverter struct {
+	rates    currencyRates
+	currency string
+}
+
+// newPriceConverter returns nil if no currency was requested, and otherwise the
+// error message to report if the currency cannot be converted to
+func newPriceConverter(rates currencyRates, currency string) (*priceConverter, string) {
+	if currency == "" {
+		return nil, ""
+	}
+
+	currency = strings.ToUpper(currency)
+	if _, ok := rates[currency]; !ok {
+		return nil, fmt.Sprintf("Currency %s is not supported", currency)
+	}
+	return &priceConverter{
+		rates:    rates,
+		currency: currency,
+	}, ""
+}
+
+// convert returns a copy of the data in the requested currency, leaving the
+// cached data untouched. Prices in a currency without a rate keep their own.
+func (converter *priceConverter) convert(data *PriceData) *PriceData {
+	if converter == nil || data == nil {
+		return data
+	}
+
+	converted := *data
+	converted.Cardmarket = converter.convert(data.Cardmarket)
+	fromRate, ok := converter.rates[data.Currency]
+	if !ok || data.Prices == nil {
+		return &converted
+	}
+
+	factor := converter.rates[converter.currency] / fromRate
+	converted.Currency = converter.currency
+	converted.Prices = make(map[string]*PriceRange, len(data.Prices))
+	for variant, prices := range data.Prices {
+		if prices == nil {
+			continue
+		}
+		converted.Prices[variant] = &PriceRange{
+			LowPrice:    convertAmount(prices.LowPrice, factor),
+			MidPrice:    convertAmount(prices.MidPrice, factor),
+			HighPrice:   convertAmount(prices.HighPrice, factor),
+			MarketPrice: convertAmount(prices.MarketPrice, factor),
+		}
+	}
+	return &converted
+}
+
+// convertAmount converts a price, rounding it to cents
+func convertAmount(amount float32, factor float64) float32 {
+	return float32(math.Round(float64(amount)*factor*100) / 100)
+}
@@ -0,0 +1,290 @@
+package functions
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadCurrencyRates(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    currencyRates
+		wantErr bool
+	}{
+		{"not configured", "", currencyRates{"USD": 1}, false},
+		{"configured", `{"EUR": 0.95, "sgd": 1.36}`, currencyRates{"USD": 1, "EUR": 0.95, "SGD": 1.36}, false},
+		{"USD rate is kept", `{"USD": 1}`, currencyRates{"USD": 1}, false},
+		{"not JSON", "EUR=0.95", nil, true},
+		{"zero rate", `{"EUR": 0}`, nil, true},
+		{"negative rate", `{"EUR": -1}`, nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv("EXCHANGE_RATES", test.value)
+			rates, err := loadCurrencyRates()
+			if (err != nil) != test.wantErr {
+				t.Fatalf("err = %v, want one: %v", err, test.wantErr)
+			}
+			if !reflect.DeepEqual(rates, test.want) {
+				t.Errorf("rates = %v, want %v", rates, test.want)
+			}
+		})
+	}
+}
+
+func TestNewPriceConverter(t *testing.T) {
+	rates := currencyRates{"USD": 1, "EUR": 0.95}
+	tests := []struct {
+		name         string
+		currency     string
+		wantCurrency string
+		wantMessage  string
+	}{
+		{"no currency", "", "", ""},
+		{"supported currency", "EUR", "EUR", ""},
+		{"lower case currency", "eur", "EUR", ""},
+		{"unknown currency", "SGD", "", "Currency SGD is not supported"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			converter, message := newPriceConverter(rates, test.currency)
+			if message != test.wantMessage {
+				t.Errorf("message = %q, want %q", message, test.wantMessage)
+			}
+			if (converter != nil) != (test.wantCurrency != "") {
+				t.Fatalf("converter = %+v, want one for %q", converter, test.wantCurrency)
+			}
+			if converter != nil && converter.currency != test.wantCurrency {
+				t.Errorf("currency = %s, want %s", converter.currency, test.wantCurrency)
+			}
+		})
+	}
+}
+
+func TestPriceConverterConvert(t *testing.T) {
+	data := func() *PriceData {
+		return &PriceData{
+			UpdatedAt: "2022/10/01",
+			Currency:  "USD",
+			Prices:    map[string]*PriceRange{"normal": {LowPrice: 1, MidPrice: 2.5, HighPrice: 10, MarketPrice: 3.333}},
+			Cardmarket: &PriceData{
+				UpdatedAt: "2022/10/02",
+				Currency:  "EUR",
+				Prices:    map[string]*PriceRange{"normal": {LowPrice: 0.95, MidPrice: 1.9, MarketPrice: 2.85}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		rates    currencyRates
+		currency string
+		want     *PriceData
+	}{
+		{
+			"no conversion requested",
+			currencyRates{"USD": 1, "EUR": 0.95},
+			"",
+			data(),
+		},
+		{
+			"to euros",
+			currencyRates{"USD": 1, "EUR": 0.95},
+			"EUR",
+			&PriceData{
+				UpdatedAt: "2022/10/01",
+				Currency:  "EUR",
+				Prices:    map[string]*PriceRange{"normal": {LowPrice: 0.95, MidPrice: 2.38, HighPrice: 9.5, MarketPrice: 3.17}},
+				Cardmarket: &PriceData{
+					UpdatedAt: "2022/10/02",
+					Currency:  "EUR",
+					Prices:    map[string]*PriceRange{"normal": {LowPrice: 0.95, MidPrice: 1.9, MarketPrice: 2.85}},
+				},
+			},
+		},
+		{
+			"to a third currency",
+			currencyRates{"USD": 1, "EUR": 0.95, "SGD": 1.4},
+			"SGD",
+			&PriceData{
+				UpdatedAt: "2022/10/01",
+				Currency:  "SGD",
+				Prices:    map[string]*PriceRange{"normal": {LowPrice: 1.4, MidPrice: 3.5, HighPrice: 14, MarketPrice: 4.67}},
+				Cardmarket: &PriceData{
+					UpdatedAt: "2022/10/02",
+					Currency:  "SGD",
+					Prices:    map[string]*PriceRange{"normal": {LowPrice: 1.4, MidPrice: 2.8, MarketPrice: 4.2}},
+				},
+			},
+		},
+		{
+			"missing euro rate leaves Cardmarket prices in euros",
+			currencyRates{"USD": 1, "SGD": 1.4},
+			"SGD",
+			&PriceData{
+				UpdatedAt: "2022/10/01",
+				Currency:  "SGD",
+				Prices:    map[string]*PriceRange{"normal": {LowPrice: 1.4, MidPrice: 3.5, HighPrice: 14, MarketPrice: 4.67}},
+				Cardmarket: &PriceData{
+					UpdatedAt: "2022/10/02",
+					Currency:  "EUR",
+					Prices:    map[string]*PriceRange{"normal": {LowPrice: 0.95, MidPrice: 1.9, MarketPrice: 2.85}},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			converter, message := newPriceConverter(test.rates, test.currency)
+			if message != "" {
+				t.Fatal(message)
+			}
+			original := data()
+			got := converter.convert(original)
+
+			if !reflect.DeepEqual(got, test.want) {
+				gotJson, _ := json.Marshal(got)
+				wantJson, _ := json.Marshal(test.want)
+				t.Errorf("convert() = %s, want %s", gotJson, wantJson)
+			}
+			if !reflect.DeepEqual(original, data()) {
+				t.Error("convert() changed the data it was given")
+			}
+		})
+	}
+}
+
+func TestParseTcgApiResponse(t *testing.T) {
+	tests := []struct {
+		name           string
+		response       string
+		wantCardmarket *PriceData
+		wantErr        bool
+	}{
+		{
+			"not sold on Cardmarket",
+			`{"data": {"tcgplayer": {"updatedAt": "2022/10/01", "prices": {"normal": {"market": 1.5}}}}}`,
+			nil,
+			false,
+		},
+		{
+			"regular prices are for the normal print",
+			`{"data": {
+				"tcgplayer": {"prices": {"normal": {"market": 1.5}, "holofoil": {"market": 4}}},
+				"cardmarket": {"updatedAt": "2022/10/02", "prices": {"averageSellPrice": 1.2, "lowPrice": 0.5, "trendPrice": 1.1}}
+			}}`,
+			&PriceData{
+				UpdatedAt: "2022/10/02",
+				Currency:  "EUR",
+				Prices:    map[string]*PriceRange{"normal": {LowPrice: 0.5, MidPrice: 1.2, MarketPrice: 1.1}},
+			},
+			false,
+		},
+		{
+			"regular prices are for the holofoil print of holofoil only cards",
+			`{"data": {
+				"tcgplayer": {"prices": {"holofoil": {"market": 4}}},
+				"cardmarket": {"updatedAt": "2022/10/02", "prices": {
+					"averageSellPrice": 3.5, "lowPrice": 2, "trendPrice": 3.2,
+					"reverseHoloSell": 6, "reverseHoloLow": 5, "reverseHoloTrend": 5.5
+				}}
+			}}`,
+			&PriceData{
+				UpdatedAt: "2022/10/02",
+				Currency:  "EUR",
+				Prices: map[string]*PriceRange{
+					"holofoil":        {LowPrice: 2, MidPrice: 3.5, MarketPrice: 3.2},
+					"reverseHolofoil": {LowPrice: 5, MidPrice: 6, MarketPrice: 5.5},
+				},
+			},
+			false,
+		},
+		{
+			"missing prices are left out",
+			`{"data": {
+				"tcgplayer": {"prices": {"normal": {"market": 1.5}}},
+				"cardmarket": {"updatedAt": "2022/10/02", "prices": {"reverseHoloTrend": 0.8}}
+			}}`,
+			&PriceData{
+				UpdatedAt: "2022/10/02",
+				Currency:  "EUR",
+				Prices:    map[string]*PriceRange{"reverseHolofoil": {MarketPrice: 0.8}},
+			},
+			false,
+		},
+		{"unreadable response", "<html></html>", nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := parseTcgApiResponse([]byte(test.response))
+			if (err != nil) != test.wantErr {
+				t.Fatalf("err = %v, want one: %v", err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if data.Currency != "USD" || data.Prices == nil {
+				t.Errorf("TCGplayer prices = %s %v, want USD prices", data.Currency, data.Prices)
+			}
+			if !reflect.DeepEqual(data.Cardmarket, test.wantCardmarket) {
+				gotJson, _ := json.Marshal(data.Cardmarket)
+				wantJson, _ := json.Marshal(test.wantCardmarket)
+				t.Errorf("cardmarket = %s, want %s", gotJson, wantJson)
+			}
+		})
+	}
+}
+
+func TestGetPriceCurrency(t *testing.T) {
+	upstream := newFakeUpstream(t)
+	upstream.set("xy1-1", pricedCard("2022/10/01", 2))
+	t.Setenv("API_KEY", "KEY")
+
+	tests := []struct {
+		name         string
+		rates        string
+		body         string
+		wantStatus   int
+		wantCurrency string
+		wantMarket   float32
+	}{
+		{"no currency", `{"EUR": 0.95}`, `{"cardUniqueId": "xy1-1"}`, 200, "USD", 2},
+		{"supported currency", `{"EUR": 0.95}`, `{"cardUniqueId": "xy1-1", "currency": "eur"}`, 200, "EUR", 1.9},
+		{"unknown currency", `{"EUR": 0.95}`, `{"cardUniqueId": "xy1-1", "currency": "SGD"}`, 400, "", 0},
+		{"invalid rates", `{"EUR": "0.95"}`, `{"cardUniqueId": "xy1-1"}`, 500, "", 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv("EXCHANGE_RATES", test.rates)
+			recorder := httptest.NewRecorder()
+			GetPrice(recorder, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(test.body)))
+
+			if recorder.Code != test.wantStatus {
+				t.Fatalf("status = %d, want %d", recorder.Code, test.wantStatus)
+			}
+			var response QueryResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+				t.Fatal(err)
+			}
+			if test.wantStatus != 200 {
+				if response.ErrorMessage == "" {
+					t.Error("missing errorMessage")
+				}
+				return
+			}
+			if response.Data.Currency != test.wantCurrency || response.Data.Prices["normal"].MarketPrice != test.wantMarket {
+				t.Errorf("got %s %v, want %s %v", response.Data.Currency, response.Data.Prices["normal"].MarketPrice, test.wantCurrency, test.wantMarket)
+			}
+		})
+	}
+}
@@ -3,21 +3,49 @@
 package functions
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"net/http"
 	"os"
+	"strconv"
 	"time"
 )
 
+// Overridden by tests, which run against a local upstream and a fixed clock
+var (
+	tcgApiUrl = "https://api.pokemontcg.io/v2"
+	clock     = time.Now
+	sleep     = time.Sleep
+)
+
 type QueryResponse struct {
+	PriceResult
+	// Results maps each ID of a batch request to its prices or error
+	Results map[string]*PriceResult `json:"results,omitempty"`
+}
+
+type PriceResult struct {
 	ErrorMessage string     `json:"errorMessage"`
 	Data         *PriceData `json:"data"`
+	// CacheAge is how many seconds ago Data was fetched from upstream
+	CacheAge int `json:"cacheAge"`
+	// Stale is set when upstream failed and expired data is served instead
+	Stale bool `json:"stale"`
+	// Status is the status of a failed lookup, as it would be for a single card
+	Status int `json:"status,omitempty"`
+	// RetryAfter is how many seconds to wait before retrying a failed lookup
+	RetryAfter int `json:"retryAfter,omitempty"`
 }
 
+// QueryRequest names either a single card, or a batch of up to maxBatchSize.
+// Prices are converted to Currency if it is given.
 type QueryRequest struct {
-	CardUniqueId string `json:"cardUniqueId"`
+	CardUniqueId  string   `json:"cardUniqueId"`
+	CardUniqueIds []string `json:"cardUniqueIds"`
+	Currency      string   `json:"currency"`
 }
 
 type ApiResponse struct {
@@ -26,12 +54,30 @@ type ApiResponse struct {
 			UpdatedAt string                 `json:"updatedAt"`
 			Prices    map[string]*PriceRange `json:"prices"`
 		} `json:"tcgplayer"`
+		Cardmarket *struct {
+			UpdatedAt string           `json:"updatedAt"`
+			Prices    CardmarketPrices `json:"prices"`
+		} `json:"cardmarket"`
 	} `json:"data"`
 }
 
+type CardmarketPrices struct {
+	AverageSellPrice float32 `json:"averageSellPrice"`
+	LowPrice         float32 `json:"lowPrice"`
+	TrendPrice       float32 `json:"trendPrice"`
+	ReverseHoloSell  float32 `json:"reverseHoloSell"`
+	ReverseHoloLow   float32 `json:"reverseHoloLow"`
+	ReverseHoloTrend float32 `json:"reverseHoloTrend"`
+}
+
+// PriceData holds the TCGplayer prices of a card, with its Cardmarket prices
+// nested in the same shape
 type PriceData struct {
 	UpdatedAt string                 `json:"updatedAt"`
+	Currency  string                 `json:"currency"`
 	Prices    map[string]*PriceRange `json:"prices"`
+	// Cardmarket is nil if the card is not sold there
+	Cardmarket *PriceData `json:"cardmarket,omitempty"`
 }
 
 type PriceRange struct {
@@ -72,39 +118,97 @@ func GetPrice(w http.ResponseWriter, r *http.Request) {
 
 	var reqData QueryRequest
 	err = json.Unmarshal(reqBody, &reqData)
-	if err != nil || reqData.CardUniqueId == "" {
+	if err != nil || (reqData.CardUniqueId == "") == (reqData.CardUniqueIds == nil) {
 		writeError(w, 400, "Bad Request Body")
 		return
 	}
 
-	apiResponse, err := getTcgApiResponse(apiToken, reqData.CardUniqueId)
+	rates, err := loadCurrencyRates()
 	if err != nil {
-		writeError(w, 503, "Downstream server could not process the request")
+		writeError(w, 500, "Server is not configured correctly")
+		return
+	}
+	converter, message := newPriceConverter(rates, reqData.Currency)
+	if message != "" {
+		writeError(w, 400, message)
 		return
 	}
 
-	priceData, err := parseTcgApiResponse(apiResponse)
-	if err != nil {
-		writeError(w, 500, "Unable to parse downstream response")
+	now := clock()
+	if reqData.CardUniqueIds != nil {
+		cardIds, message := readBatchIds(reqData.CardUniqueIds)
+		if message != "" {
+			writeError(w, 400, message)
+			return
+		}
+		writeJson(w, 200, QueryResponse{
+			Results: lookupBatch(r.Context(), apiToken, cardIds, converter, now),
+		})
 		return
 	}
 
-	jsonResponse, err := json.Marshal(QueryResponse{
-		ErrorMessage: "",
-		Data:         priceData,
-	})
-	if err != nil {
-		writeError(w, 500, "Internal error encountered")
+	result, status := lookupPrice(r.Context(), apiToken, reqData.CardUniqueId, converter, now)
+	if status != 200 {
+		if result.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(result.RetryAfter))
+		}
+		writeJson(w, status, QueryResponse{
+			PriceResult: *result,
+		})
 		return
 	}
+	w.Header().Set("Age", strconv.Itoa(result.CacheAge))
+	writeJson(w, 200, QueryResponse{
+		PriceResult: *result,
+	})
+}
+
+// lookupPrice serves the card's prices from the cache while they are fresh, and
+// otherwise fetches them, falling back to the expired prices if upstream is
+// temporarily failing. The status is that of the response for a single card.
+func lookupPrice(ctx context.Context, apiToken string, cardId string, converter *priceConverter, now time.Time) (*PriceResult, int) {
+	entry := cache.get(cardId, now)
+	stale := false
+	if entry == nil || !entry.isFresh(now) {
+		priceData, err := fetchPriceData(ctx, apiToken, cardId)
+		switch {
+		case err == nil:
+			entry = cache.put(cardId, priceData, now)
+		case entry != nil && isTransientError(err):
+			// Expired prices are more useful than none while upstream is failing
+			stale = true
+		default:
+			status, message, retryAfter := describeUpstreamError(err)
+			return &PriceResult{
+				ErrorMessage: message,
+				Status:       status,
+				RetryAfter:   int(math.Ceil(retryAfter.Seconds())),
+			}, status
+		}
+	}
 
-	w.Header().Add("Content-Type", "application/json")
-	w.Write(jsonResponse)
+	return &PriceResult{
+		Data:     converter.convert(entry.data),
+		CacheAge: entry.age(now),
+		Stale:    stale,
+	}, 200
+}
+
+// fetchPriceData gets the card's prices from upstream, retrying rate limits and
+// server errors. A card without TCGplayer prices has empty PriceData.
+func fetchPriceData(ctx context.Context, apiToken string, cardId string) (*PriceData, error) {
+	apiResponse, err := withRetries(ctx, func() ([]byte, error) {
+		return getTcgApiResponse(ctx, apiToken, cardId)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return parseTcgApiResponse(apiResponse)
 }
 
-func getTcgApiResponse(apiToken string, cardId string) ([]byte, error) {
-	url := fmt.Sprintf("https://api.pokemontcg.io/v2/cards/%s", cardId)
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+func getTcgApiResponse(ctx context.Context, apiToken string, cardId string) ([]byte, error) {
+	url := fmt.Sprintf("%s/cards/%s", tcgApiUrl, cardId)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -116,36 +220,87 @@ func getTcgApiResponse(apiToken string, cardId string) ([]byte, error) {
 
 	resp, err := httpClient.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, &upstreamConnectionError{Err: err}
 	}
+	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errCardNotFound
+	}
 	if resp.StatusCode != 200 {
-		return nil, nil
+		return nil, &upstreamStatusError{
+			StatusCode: resp.StatusCode,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), clock()),
+		}
 	}
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return nil, &upstreamConnectionError{Err: err}
 	}
 
 	return body, nil
 }
 
 func parseTcgApiResponse(response []byte) (*PriceData, error) {
-	if response == nil {
-		return nil, nil
-	}
-
 	var dataContainer ApiResponse
 	err := json.Unmarshal(response, &dataContainer)
 	if err != nil {
-		return nil, err
+		return nil, &upstreamParseError{Err: err}
 	}
 
-	return &PriceData{
+	priceData := &PriceData{
 		UpdatedAt: dataContainer.Data.TcgPlayer.UpdatedAt,
+		Currency:  currencyUsd,
 		Prices:    dataContainer.Data.TcgPlayer.Prices,
-	}, nil
+	}
+	if cardmarket := dataContainer.Data.Cardmarket; cardmarket != nil {
+		priceData.Cardmarket = &PriceData{
+			UpdatedAt: cardmarket.UpdatedAt,
+			Currency:  currencyEur,
+			Prices:    normaliseCardmarketPrices(cardmarket.Prices, priceData.Prices),
+		}
+	}
+	return priceData, nil
+}
+
+// normaliseCardmarketPrices maps Cardmarket prices onto TCGplayer variants.
+// Cardmarket does not track a high price, and its regular prices are for the
+// holofoil print of cards that TCGplayer only has in holofoil.
+func normaliseCardmarketPrices(prices CardmarketPrices, tcgPrices map[string]*PriceRange) map[string]*PriceRange {
+	variant := "normal"
+	if tcgPrices["normal"] == nil && tcgPrices["holofoil"] != nil {
+		variant = "holofoil"
+	}
+
+	normalised := make(map[string]*PriceRange)
+	if prices.LowPrice != 0 || prices.AverageSellPrice != 0 || prices.TrendPrice != 0 {
+		normalised[variant] = &PriceRange{
+			LowPrice:    prices.LowPrice,
+			MidPrice:    prices.AverageSellPrice,
+			MarketPrice: prices.TrendPrice,
+		}
+	}
+	if prices.ReverseHoloLow != 0 || prices.ReverseHoloSell != 0 || prices.ReverseHoloTrend != 0 {
+		normalised["reverseHolofoil"] = &PriceRange{
+			LowPrice:    prices.ReverseHoloLow,
+			MidPrice:    prices.ReverseHoloSell,
+			MarketPrice: prices.ReverseHoloTrend,
+		}
+	}
+	return normalised
+}
+
+func writeJson(w http.ResponseWriter, code int, response QueryResponse) {
+	content, err := json.Marshal(response)
+	if err != nil {
+		writeError(w, 500, "Internal error encountered")
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(content)
 }
 
 func writeError(w http.ResponseWriter, code int, message string) {
@@ -153,8 +308,9 @@ func writeError(w http.ResponseWriter, code int, message string) {
 	w.WriteHeader(code)
 
 	content, err := json.Marshal(QueryResponse{
-		ErrorMessage: message,
-		Data:         nil,
+		PriceResult: PriceResult{
+			ErrorMessage: message,
+		},
 	})
 
 	if err != nil {
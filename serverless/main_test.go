@@ -0,0 +1,115 @@
+package functions
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeResponse is what the fake upstream answers for a card
+type fakeResponse struct {
+	status     int
+	body       string
+	retryAfter string
+	delay      time.Duration
+}
+
+// fakeUpstream stands in for pokemontcg.io, answering each card with the
+// response set for it and 404 otherwise
+type fakeUpstream struct {
+	mutex     sync.Mutex
+	responses map[string]fakeResponse
+	hits      map[string]int
+	sleeps    []time.Duration
+	// active and maxActive count the requests in flight at once
+	active    int
+	maxActive int
+}
+
+// newFakeUpstream points the function at a local upstream, records retry waits
+// instead of sleeping and starts from an empty cache
+func newFakeUpstream(t *testing.T) *fakeUpstream {
+	upstream := &fakeUpstream{
+		responses: make(map[string]fakeResponse),
+		hits:      make(map[string]int),
+	}
+	server := httptest.NewServer(http.HandlerFunc(upstream.serve))
+
+	originalUrl, originalSleep := tcgApiUrl, sleep
+	tcgApiUrl = server.URL
+	sleep = upstream.recordSleep
+	cache = newPriceCache()
+	t.Cleanup(func() {
+		server.Close()
+		tcgApiUrl, sleep = originalUrl, originalSleep
+		cache = newPriceCache()
+	})
+	return upstream
+}
+
+func (upstream *fakeUpstream) set(cardId string, response fakeResponse) {
+	upstream.mutex.Lock()
+	defer upstream.mutex.Unlock()
+	upstream.responses[cardId] = response
+}
+
+func (upstream *fakeUpstream) hitCount(cardId string) int {
+	upstream.mutex.Lock()
+	defer upstream.mutex.Unlock()
+	return upstream.hits[cardId]
+}
+
+func (upstream *fakeUpstream) recordSleep(wait time.Duration) {
+	upstream.mutex.Lock()
+	defer upstream.mutex.Unlock()
+	upstream.sleeps = append(upstream.sleeps, wait)
+}
+
+func (upstream *fakeUpstream) serve(w http.ResponseWriter, r *http.Request) {
+	cardId := strings.TrimPrefix(r.URL.Path, "/cards/")
+	upstream.mutex.Lock()
+	upstream.hits[cardId]++
+	upstream.active++
+	if upstream.active > upstream.maxActive {
+		upstream.maxActive = upstream.active
+	}
+	response, ok := upstream.responses[cardId]
+	upstream.mutex.Unlock()
+	defer func() {
+		upstream.mutex.Lock()
+		upstream.active--
+		upstream.mutex.Unlock()
+	}()
+
+	time.Sleep(response.delay)
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if response.retryAfter != "" {
+		w.Header().Set("Retry-After", response.retryAfter)
+	}
+	w.WriteHeader(response.status)
+	w.Write([]byte(response.body))
+}
+
+// pricedCard is an upstream response for a card with a normal market price
+func pricedCard(updatedAt string, marketPrice float32) fakeResponse {
+	return fakeResponse{
+		status: http.StatusOK,
+		body:   fmt.Sprintf(`{"data": {"tcgplayer": {"updatedAt": "%s", "prices": {"normal": {"market": %g}}}}}`, updatedAt, marketPrice),
+	}
+}
+
+// fixClock makes the function see the time returned by now
+func fixClock(t *testing.T, now func() time.Time) {
+	originalClock := clock
+	clock = now
+	t.Cleanup(func() {
+		clock = originalClock
+	})
+}
@@ -0,0 +1,146 @@
+package functions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// maxUpstreamAttempts allows 2 retries after the first request
+	maxUpstreamAttempts = 3
+	initialRetryBackoff = 500 * time.Millisecond
+	// maxRetryWait is the longest the function waits before retrying, so that
+	// a long Retry-After is passed on to the caller instead
+	maxRetryWait = 5 * time.Second
+)
+
+var errCardNotFound = errors.New("card not found upstream")
+
+// upstreamStatusError is an unsuccessful response from pokemontcg.io
+type upstreamStatusError struct {
+	StatusCode int
+	// RetryAfter is how long upstream asked us to wait, or 0 if it did not say
+	RetryAfter time.Duration
+}
+
+func (err *upstreamStatusError) Error() string {
+	return fmt.Sprintf("upstream responded with status %d", err.StatusCode)
+}
+
+func (err *upstreamStatusError) isRetryable() bool {
+	return err.StatusCode == http.StatusTooManyRequests || err.StatusCode >= 500
+}
+
+// upstreamConnectionError is a failure to get any response from pokemontcg.io
+type upstreamConnectionError struct {
+	Err error
+}
+
+func (err *upstreamConnectionError) Error() string {
+	return "could not reach upstream: " + err.Err.Error()
+}
+
+func (err *upstreamConnectionError) Unwrap() error {
+	return err.Err
+}
+
+func (err *upstreamConnectionError) isTimeout() bool {
+	var netErr net.Error
+	return errors.As(err.Err, &netErr) && netErr.Timeout()
+}
+
+type upstreamParseError struct {
+	Err error
+}
+
+func (err *upstreamParseError) Error() string {
+	return "unreadable upstream response: " + err.Err.Error()
+}
+
+// withRetries calls fetch until it succeeds, fails for good, or runs out of
+// attempts. Rate limits and server errors are retried with exponential
+// backoff, waiting longer if upstream asks to with Retry-After. No retry is
+// made if the wait would run past the deadline of ctx.
+func withRetries(ctx context.Context, fetch func() ([]byte, error)) ([]byte, error) {
+	backoff := initialRetryBackoff
+	for attempt := 1; ; attempt++ {
+		body, err := fetch()
+		var statusErr *upstreamStatusError
+		var connectionErr *upstreamConnectionError
+		retryable := (errors.As(err, &statusErr) && statusErr.isRetryable()) ||
+			(errors.As(err, &connectionErr) && !connectionErr.isTimeout())
+		if err == nil || !retryable || attempt == maxUpstreamAttempts {
+			return body, err
+		}
+
+		wait := backoff
+		if statusErr != nil && statusErr.RetryAfter > wait {
+			wait = statusErr.RetryAfter
+		}
+		if wait > maxRetryWait {
+			return body, err
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return body, err
+		}
+		sleep(wait)
+		backoff *= 2
+	}
+}
+
+// parseRetryAfter reads a Retry-After header given either in seconds or as an
+// HTTP date, returning 0 if it is missing or invalid
+func parseRetryAfter(header string, now time.Time) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(header); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
+
+// describeUpstreamError picks the status and message to report for a failed
+// lookup, along with how long the caller should wait before retrying
+func describeUpstreamError(err error) (int, string, time.Duration) {
+	var statusErr *upstreamStatusError
+	var connectionErr *upstreamConnectionError
+	var parseErr *upstreamParseError
+	switch {
+	case errors.Is(err, errCardNotFound):
+		return 404, "Card was not found", 0
+	case errors.As(err, &statusErr):
+		switch {
+		case statusErr.StatusCode == http.StatusTooManyRequests:
+			return 429, "Price lookups are being rate limited, try again later", statusErr.RetryAfter
+		case statusErr.StatusCode == http.StatusUnauthorized || statusErr.StatusCode == http.StatusForbidden:
+			return 500, "Server is not configured correctly", 0
+		case statusErr.StatusCode >= 500:
+			return 502, "Downstream server could not process the request", statusErr.RetryAfter
+		}
+		return 502, fmt.Sprintf("Downstream server rejected the request with status %d", statusErr.StatusCode), 0
+	case errors.As(err, &connectionErr):
+		if connectionErr.isTimeout() {
+			return 504, "Downstream server timed out", 0
+		}
+		return 503, "Downstream server could not be reached", 0
+	case errors.As(err, &parseErr):
+		return 500, "Unable to parse downstream response", 0
+	}
+	return 500, "Internal error encountered", 0
+}
+
+// isTransientError reports whether the lookup may succeed later, in which case
+// expired prices are served in its place
+func isTransientError(err error) bool {
+	status, _, _ := describeUpstreamError(err)
+	return status != 404 && status != 500
+}
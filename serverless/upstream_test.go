@@ -0,0 +1,224 @@
+package functions
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// timeoutError is a network error that timed out
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+var _ net.Error = timeoutError{}
+
+func TestWithRetries(t *testing.T) {
+	rateLimited := &upstreamStatusError{StatusCode: http.StatusTooManyRequests}
+	unavailable := &upstreamStatusError{StatusCode: http.StatusServiceUnavailable}
+	refused := &upstreamConnectionError{Err: errors.New("connection refused")}
+
+	tests := []struct {
+		name       string
+		errs       []error
+		wantCalls  int
+		wantSleeps []time.Duration
+	}{
+		{"success is not retried", []error{nil}, 1, nil},
+		{"not found is not retried", []error{errCardNotFound}, 1, nil},
+		{"client errors are not retried", []error{&upstreamStatusError{StatusCode: http.StatusBadRequest}}, 1, nil},
+		{"parse errors are not retried", []error{&upstreamParseError{Err: errors.New("bad json")}}, 1, nil},
+		{"timeouts are not retried", []error{&upstreamConnectionError{Err: timeoutError{}}}, 1, nil},
+		{"rate limit is retried until it succeeds", []error{rateLimited, nil}, 2, []time.Duration{initialRetryBackoff}},
+		{"failed connections are retried", []error{refused, nil}, 2, []time.Duration{initialRetryBackoff}},
+		{
+			"server errors back off exponentially until out of attempts",
+			[]error{unavailable, unavailable, unavailable, nil},
+			maxUpstreamAttempts,
+			[]time.Duration{initialRetryBackoff, 2 * initialRetryBackoff},
+		},
+		{
+			"Retry-After longer than the backoff is waited out",
+			[]error{&upstreamStatusError{StatusCode: http.StatusTooManyRequests, RetryAfter: 3 * time.Second}, nil},
+			2,
+			[]time.Duration{3 * time.Second},
+		},
+		{
+			"Retry-After shorter than the backoff is ignored",
+			[]error{&upstreamStatusError{StatusCode: http.StatusTooManyRequests, RetryAfter: 100 * time.Millisecond}, nil},
+			2,
+			[]time.Duration{initialRetryBackoff},
+		},
+		{
+			"Retry-After past the longest wait is not retried",
+			[]error{&upstreamStatusError{StatusCode: http.StatusTooManyRequests, RetryAfter: maxRetryWait + time.Second}, nil},
+			1,
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			upstream := newFakeUpstream(t)
+			calls := 0
+			_, err := withRetries(context.Background(), func() ([]byte, error) {
+				err := test.errs[calls]
+				calls++
+				return nil, err
+			})
+
+			if calls != test.wantCalls {
+				t.Errorf("fetched %d times, want %d", calls, test.wantCalls)
+			}
+			if !reflect.DeepEqual(upstream.sleeps, test.wantSleeps) {
+				t.Errorf("waited %v, want %v", upstream.sleeps, test.wantSleeps)
+			}
+			if wantErr := test.errs[test.wantCalls-1]; err != wantErr {
+				t.Errorf("err = %v, want %v", err, wantErr)
+			}
+		})
+	}
+}
+
+func TestWithRetriesDeadline(t *testing.T) {
+	upstream := newFakeUpstream(t)
+	ctx, cancel := context.WithTimeout(context.Background(), initialRetryBackoff/2)
+	defer cancel()
+
+	calls := 0
+	withRetries(ctx, func() ([]byte, error) {
+		calls++
+		return nil, &upstreamStatusError{StatusCode: http.StatusServiceUnavailable}
+	})
+
+	if calls != 1 || len(upstream.sleeps) != 0 {
+		t.Errorf("fetched %d times and waited %v, want no retry past the deadline", calls, upstream.sleeps)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2022, 10, 1, 6, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		header string
+		want   time.Duration
+	}{
+		{"missing", "", 0},
+		{"seconds", "3", 3 * time.Second},
+		{"seconds past the longest wait", "120", 120 * time.Second},
+		{"zero seconds", "0", 0},
+		{"negative seconds", "-5", 0},
+		{"HTTP date", "Sat, 01 Oct 2022 06:00:04 GMT", 4 * time.Second},
+		{"HTTP date in the past", "Sat, 01 Oct 2022 05:59:00 GMT", 0},
+		{"invalid", "soon", 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := parseRetryAfter(test.header, now); got != test.want {
+				t.Errorf("parseRetryAfter(%q) = %v, want %v", test.header, got, test.want)
+			}
+		})
+	}
+}
+
+func TestLookupPriceUpstreamErrors(t *testing.T) {
+	now := time.Date(2022, 10, 1, 6, 0, 0, 0, time.UTC)
+	fixClock(t, func() time.Time { return now })
+
+	tests := []struct {
+		name           string
+		response       fakeResponse
+		wantStatus     int
+		wantMessage    string
+		wantRetryAfter int
+		wantHits       int
+	}{
+		{"card not found", fakeResponse{status: http.StatusNotFound}, 404, "Card was not found", 0, 1},
+		{
+			"rate limited",
+			fakeResponse{status: http.StatusTooManyRequests},
+			429, "Price lookups are being rate limited, try again later", 0, maxUpstreamAttempts,
+		},
+		{
+			"rate limited for longer than the longest wait",
+			fakeResponse{status: http.StatusTooManyRequests, retryAfter: "60"},
+			429, "Price lookups are being rate limited, try again later", 60, 1,
+		},
+		{
+			"rate limited until an HTTP date",
+			fakeResponse{status: http.StatusTooManyRequests, retryAfter: "Sat, 01 Oct 2022 06:00:30 GMT"},
+			429, "Price lookups are being rate limited, try again later", 30, 1,
+		},
+		{
+			"server error",
+			fakeResponse{status: http.StatusInternalServerError},
+			502, "Downstream server could not process the request", 0, maxUpstreamAttempts,
+		},
+		{
+			"bad gateway with Retry-After",
+			fakeResponse{status: http.StatusBadGateway, retryAfter: "2"},
+			502, "Downstream server could not process the request", 2, maxUpstreamAttempts,
+		},
+		{"unauthorized", fakeResponse{status: http.StatusForbidden}, 500, "Server is not configured correctly", 0, 1},
+		{
+			"other client error",
+			fakeResponse{status: http.StatusBadRequest},
+			502, "Downstream server rejected the request with status 400", 0, 1,
+		},
+		{
+			"unreadable response",
+			fakeResponse{status: http.StatusOK, body: "<html></html>"},
+			500, "Unable to parse downstream response", 0, 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			upstream := newFakeUpstream(t)
+			upstream.set("xy1-1", test.response)
+
+			result, status := lookupPrice(context.Background(), "KEY", "xy1-1", nil, now)
+
+			if status != test.wantStatus || result.Status != test.wantStatus {
+				t.Errorf("status = %d, result status = %d, want %d", status, result.Status, test.wantStatus)
+			}
+			if result.ErrorMessage != test.wantMessage {
+				t.Errorf("errorMessage = %q, want %q", result.ErrorMessage, test.wantMessage)
+			}
+			if result.RetryAfter != test.wantRetryAfter {
+				t.Errorf("retryAfter = %d, want %d", result.RetryAfter, test.wantRetryAfter)
+			}
+			if hits := upstream.hitCount("xy1-1"); hits != test.wantHits {
+				t.Errorf("upstream was called %d times, want %d", hits, test.wantHits)
+			}
+		})
+	}
+}
+
+func TestDescribeUpstreamErrorConnection(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"timeout", &upstreamConnectionError{Err: timeoutError{}}, 504},
+		{"unreachable", &upstreamConnectionError{Err: errors.New("connection refused")}, 503},
+		{"unknown", errors.New("something else"), 500},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			status, message, _ := describeUpstreamError(test.err)
+			if status != test.wantStatus || message == "" {
+				t.Errorf("describeUpstreamError() = %d, %q, want %d with a message", status, message, test.wantStatus)
+			}
+		})
+	}
+}